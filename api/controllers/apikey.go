@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+	"github.com/mylxsw/go-utils/array"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIKeyController 用户 API Key 管理
+type APIKeyController struct {
+	repo *repo.Repository `autowire:"@"`
+}
+
+// NewAPIKeyController 创建 API Key 控制器
+func NewAPIKeyController(resolver infra.Resolver) web.Controller {
+	ctl := APIKeyController{}
+	resolver.MustAutoWire(&ctl)
+	return &ctl
+}
+
+func (ctl *APIKeyController) Register(router web.Router) {
+	router.Group("/users/api-keys", func(router web.Router) {
+		router.Get("/", ctl.APIKeys)
+		router.Post("/", ctl.CreateAPIKey)
+		router.Put("/{id}", ctl.UpdateAPIKey)
+		router.Delete("/{id}", ctl.DeleteAPIKey)
+	})
+}
+
+// maxAPIKeysPerUser 每个用户最多可以创建的 API Key 数量
+const maxAPIKeysPerUser = 20
+
+// APIKeyRequest 创建、更新 API Key 请求
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+	// ExpiredAt optional expiration time, RFC3339 format, the expiration time is not changed when it is empty during update
+	ExpiredAt string `json:"expired_at,omitempty"`
+
+	expiredAt *time.Time
+}
+
+// parse 解析并校验请求参数，未指定访问范围时默认只允许访问聊天接口
+func (req *APIKeyRequest) parse() error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > 50 {
+		return errors.New("name is required and must be less than 50 characters")
+	}
+
+	if len(req.Scopes) == 0 {
+		req.Scopes = []string{repo.APIKeyScopeChat}
+	}
+
+	req.Scopes = array.Uniq(req.Scopes)
+	for _, scope := range req.Scopes {
+		if !array.In(scope, repo.APIKeyScopes) {
+			return errors.New("invalid scope: " + scope)
+		}
+	}
+
+	if req.ExpiredAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ExpiredAt)
+		if err != nil || t.Before(time.Now()) {
+			return errors.New("invalid expiration time")
+		}
+
+		req.expiredAt = &t
+	}
+
+	return nil
+}
+
+// APIKeys 获取当前用户的 API Key 列表
+func (ctl *APIKeyController) APIKeys(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	keys, err := ctl.repo.APIKey.GetAPIKeys(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query api keys failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"data": keys})
+}
+
+// CreateAPIKey 创建 API Key，Key 只在创建时返回一次
+func (ctl *APIKeyController) CreateAPIKey(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	var req APIKeyRequest
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	if err := req.parse(); err != nil {
+		return webCtx.JSONError(err.Error(), http.StatusBadRequest)
+	}
+
+	keys, err := ctl.repo.APIKey.GetAPIKeys(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query api keys failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	if len(keys) >= maxAPIKeysPerUser {
+		return webCtx.JSONError("the number of api keys has reached the limit", http.StatusBadRequest)
+	}
+
+	key, apiKey, err := ctl.repo.APIKey.CreateAPIKey(ctx, user.ID, req.Name, req.Scopes, req.expiredAt)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("create api key failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{
+		"key":  key,
+		"data": apiKey,
+	})
+}
+
+// UpdateAPIKey 更新 API Key 的名称、访问范围以及过期时间
+func (ctl *APIKeyController) UpdateAPIKey(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.ParseInt(webCtx.PathVar("id"), 10, 64)
+	if err != nil {
+		return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+	}
+
+	var req APIKeyRequest
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	if err := req.parse(); err != nil {
+		return webCtx.JSONError(err.Error(), http.StatusBadRequest)
+	}
+
+	if err := ctl.repo.APIKey.UpdateAPIKey(ctx, user.ID, id, req.Name, req.Scopes, req.expiredAt); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("update api key failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
+
+// DeleteAPIKey 撤销 API Key
+func (ctl *APIKeyController) DeleteAPIKey(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.ParseInt(webCtx.PathVar("id"), 10, 64)
+	if err != nil {
+		return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+	}
+
+	if err := ctl.repo.APIKey.DeleteAPIKey(ctx, user.ID, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "id": id}).Errorf("delete api key failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/mylxsw/aidea-chat-server/internal/consumer/tasks"
 	"github.com/mylxsw/aidea-chat-server/internal/queue"
 	"github.com/mylxsw/aidea-chat-server/pkg/jwt"
+	"github.com/mylxsw/aidea-chat-server/pkg/mail"
 	"github.com/mylxsw/aidea-chat-server/pkg/misc"
 	"github.com/mylxsw/aidea-chat-server/pkg/rate"
 	"github.com/mylxsw/aidea-chat-server/pkg/repo"
@@ -493,7 +494,7 @@ func (ctl *AuthController) ResetPassword(ctx context.Context, webCtx web.Context
 }
 
 // SendEmailCode 发送邮件验证码
-func (ctl *AuthController) SendEmailCode(ctx context.Context, webCtx web.Context, userRepo *repo.UserRepo, rds *redis.Client) web.Response {
+func (ctl *AuthController) SendEmailCode(ctx context.Context, webCtx web.Context, client *auth.ClientInfo, userRepo *repo.UserRepo, rds *redis.Client) web.Response {
 	username := strings.TrimSpace(webCtx.Input("username"))
 	if username == "" {
 		return webCtx.JSONError("用户名不能为空", http.StatusBadRequest)
@@ -553,9 +554,13 @@ func (ctl *AuthController) SendEmailCode(ctx context.Context, webCtx web.Context
 	}).Debugf("send email code: %s", code)
 
 	mailPayload := tasks.MailPayload{
-		To:        []string{username},
-		Subject:   "验证码",
-		Body:      fmt.Sprintf("您的验证码是：%s， 请在 %s 之前使用。", code, time.Now().Add(10*time.Minute).Format("2006-01-02 15:04:05")),
+		To:       []string{username},
+		Template: mail.TemplateVerifyCode,
+		Language: client.Language,
+		Data: map[string]any{
+			"Code":          code,
+			"ExpireMinutes": 10,
+		},
 		CreatedAt: time.Now(),
 	}
 
@@ -733,7 +738,7 @@ func (ctl *AuthController) BindPhoneSendSMSCode(ctx context.Context, webCtx web.
 }
 
 // SignUpSendEmailCode 发送注册邮件验证码
-func (ctl *AuthController) SignUpSendEmailCode(ctx context.Context, webCtx web.Context) web.Response {
+func (ctl *AuthController) SignUpSendEmailCode(ctx context.Context, webCtx web.Context, client *auth.ClientInfo) web.Response {
 	username := strings.TrimSpace(webCtx.Input("username"))
 	if username == "" {
 		return webCtx.JSONError("用户名不能为空", http.StatusBadRequest)
@@ -784,9 +789,13 @@ func (ctl *AuthController) SignUpSendEmailCode(ctx context.Context, webCtx web.C
 	}).Debugf("send email code: %s", code)
 
 	mailPayload := tasks.MailPayload{
-		To:        []string{username},
-		Subject:   "验证您的电子邮件地址",
-		Body:      fmt.Sprintf("您的验证码是：%s， 请在 %s 之前使用。", code, time.Now().Add(10*time.Minute).Format("2006-01-02 15:04:05")),
+		To:       []string{username},
+		Template: mail.TemplateSignupVerifyCode,
+		Language: client.Language,
+		Data: map[string]any{
+			"Code":          code,
+			"ExpireMinutes": 10,
+		},
 		CreatedAt: time.Now(),
 	}
 
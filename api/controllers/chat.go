@@ -6,11 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-redis/redis_rate/v10"
+	"github.com/hibiken/asynq"
 	"github.com/mylxsw/aidea-chat-server/api/auth"
 	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/internal/coins"
+	"github.com/mylxsw/aidea-chat-server/internal/consumer/tasks"
+	"github.com/mylxsw/aidea-chat-server/internal/queue"
 	"github.com/mylxsw/aidea-chat-server/pkg/chat"
 	"github.com/mylxsw/aidea-chat-server/pkg/misc"
 	"github.com/mylxsw/aidea-chat-server/pkg/rate"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/aidea-chat-server/pkg/service"
 	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/glacier/infra"
 	"github.com/mylxsw/glacier/web"
@@ -22,9 +28,15 @@ import (
 
 // ChatController is the controller for chat
 type ChatController struct {
-	conf    *config.Config `autowire:"@"`
-	limiter *rate.Limiter  `autowire:"@"`
-	chatter *chat.Chatter  `autowire:"@"`
+	conf    *config.Config       `autowire:"@"`
+	limiter *rate.Limiter        `autowire:"@"`
+	chatter *chat.Chatter        `autowire:"@"`
+	repo    *repo.Repository     `autowire:"@"`
+	userSrv *service.UserService `autowire:"@"`
+	buffer  *chat.StreamBuffer   `autowire:"@"`
+	queue   *queue.Queue         `autowire:"@"`
+
+	canceller *chat.Canceller `autowire:"@"`
 }
 
 func NewChatController(resolver infra.Resolver) web.Controller {
@@ -36,12 +48,31 @@ func NewChatController(resolver infra.Resolver) web.Controller {
 func (ctl *ChatController) Register(router web.Router) {
 	router.Group("/chat", func(router web.Router) {
 		router.Post("/stream", ctl.ChatStream)
+		// 非流式聊天接口，一次性返回完整的回复
+		router.Post("/message", ctl.Chat)
+		// 客户端断开连接后，从最后收到的分片继续读取回复
+		router.Post("/stream/resume", ctl.ResumeStream)
+		// 停止正在生成的回复
+		router.Post("/cancel", ctl.CancelChat)
+		// 会话分支：重新生成回复、编辑提问后重新发送、切换当前分支
+		router.Post("/regenerate", ctl.Regenerate)
+		router.Post("/edit", ctl.EditQuestion)
+		router.Post("/branch", ctl.SwitchBranch)
+		// OpenAI compatible chat completion API
+		router.Post("/completions", ctl.Completions)
 	})
 }
 
 // ChatRequest chat request
 type ChatRequest struct {
 	chat.Request
+	// ConversationID the conversation to which the question belongs, a new conversation is created when it is empty
+	ConversationID int64 `json:"conversation_id,omitempty"`
+
+	// QuestionID the saved question to answer again when regenerating, the question is not saved again
+	QuestionID int64 `json:"-"`
+	// EditOf the question being edited, the new question is saved as its sibling
+	EditOf int64 `json:"-"`
 }
 
 // Init initialize chat request
@@ -61,20 +92,7 @@ func (ctl *ChatController) ChatStream(
 		user.User = &auth.User{}
 	}
 
-	if user.User == nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = w.Write([]byte(`{"error": "the user is not logged in, please log in first and try again"}`))
-		return
-	}
-
-	// rate control to avoid overuse by a single user
-	if err := ctl.rateLimit(ctx, client, user.User); err != nil {
-		if errors.Is(err, rate.ErrDailyFreeLimitExceeded) {
-			w.WriteHeader(http.StatusUnauthorized)
-		} else {
-			w.WriteHeader(http.StatusTooManyRequests)
-		}
-		_, _ = w.Write([]byte(fmt.Sprintf(`{"error": %s}`, strconv.Quote(err.Error()))))
+	if !ctl.allowStreamRequest(ctx, w, client, user.User) {
 		return
 	}
 
@@ -87,33 +105,132 @@ func (ctl *ChatController) ChatStream(
 	}
 	defer sw.Close()
 
+	if len(req.Messages) == 0 {
+		misc.NoError(sw.WriteErrorStream(errors.New("messages is required"), http.StatusBadRequest))
+		return
+	}
+
+	ctl.streamChat(ctx, sw, user.User, client, req)
+}
+
+// streamChat 处理流式聊天请求：冻结智慧果、保存提问、生成回复并保存，重新生成以及编辑提问也使用该流程
+func (ctl *ChatController) streamChat(
+	ctx context.Context,
+	sw *misc.StreamWriter,
+	user *auth.User,
+	client *auth.ClientInfo,
+	req *ChatRequest,
+) {
+	req.UserID = user.ID
+
 	startTime := time.Now()
 
+	// 检查用户的智慧果余额是否足够，并冻结本次请求预估消耗的智慧果
+	frozenQuota, err := ctl.freezeChatQuota(ctx, req.Request, user)
+	if err != nil {
+		if errors.Is(err, ErrQuotaNotEnough) {
+			misc.NoError(sw.WriteErrorStream(errors.New(QuotaNotEnoughError), http.StatusPaymentRequired))
+			return
+		}
+
+		if errors.Is(err, chat.ErrContextExceedLimit) {
+			misc.NoError(sw.WriteErrorStream(err, http.StatusBadRequest))
+			return
+		}
+
+		if errors.Is(err, repo.ErrNotFound) {
+			misc.NoError(sw.WriteErrorStream(errors.New("robot not found"), http.StatusNotFound))
+			return
+		}
+
+		log.F(log.M{"user_id": user.ID, "req": req}).Errorf("freeze chat quota failed: %s", err)
+		misc.NoError(sw.WriteErrorStream(errors.New(InternalServerError), http.StatusInternalServerError))
+		return
+	}
+
+	// 实际扣费完成后，释放冻结的智慧果
+	defer ctl.unfreezeChatQuota(user, frozenQuota)
+
 	// save chat question
-	questionID := ctl.saveChatQuestion(ctx, req, user.User)
+	conversationID, questionID, err := ctl.saveChatQuestion(ctx, req, user)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			misc.NoError(sw.WriteErrorStream(errors.New("conversation not found"), http.StatusNotFound))
+			return
+		}
+
+		log.F(log.M{"user_id": user.ID, "req": req}).Errorf("save chat question failed: %s", err)
+		misc.NoError(sw.WriteErrorStream(errors.New(InternalServerError), http.StatusInternalServerError))
+		return
+	}
+
+	// 上下文摘要按照会话缓存
+	req.Request.ConversationID = conversationID
+
+	out := &chatStreamWriter{sw: sw}
+
+	// 保存了会话的请求，先创建生成中的回复，客户端断开连接后继续生成并写入缓冲区，客户端可以通过回复 ID 恢复
+	var answerID int64
+	if conversationID > 0 {
+		answerID, err = ctl.repo.Conversation.CreatePendingAnswer(ctx, user.ID, conversationID, questionID, req.RobotID)
+		if err != nil {
+			log.F(log.M{"user_id": user.ID, "conversation_id": conversationID}).Errorf("create pending answer failed: %s", err)
+			misc.NoError(sw.WriteErrorStream(errors.New(InternalServerError), http.StatusInternalServerError))
+			return
+		}
+
+		out.answerID = answerID
+		if err := ctl.buffer.Create(ctx, answerID, user.ID); err != nil {
+			log.F(log.M{"user_id": user.ID, "answer_id": answerID}).Warningf("create stream buffer failed: %s", err)
+		} else {
+			out.buffer = ctl.buffer
+
+			// 客户端断开连接后，请求的 context 会被取消，回复的生成不能依赖它
+			ctx = context.Background()
+		}
+	}
+
+	// 用户可以通过 WebSocket 消息或者取消接口主动停止生成，停止后上游请求被取消
+	ctx, cancelGenerate := context.WithCancelCause(ctx)
+	defer cancelGenerate(nil)
+
+	stop := func() { cancelGenerate(ErrChatCanceled) }
+	stopped := func() bool { return errors.Is(context.Cause(ctx), ErrChatCanceled) }
+
+	sw.OnCancel(stop)
+	if answerID > 0 {
+		defer ctl.canceller.Register(answerID, stop)()
+	}
 
 	var replyText string
+	var toolCalls []chat.ToolCall
 	var usage *chat.Usage
 
 	defer func() {
 		log.F(log.M{
-			"user_id":     user.User.ID,
-			"client":      client,
-			"req":         req,
-			"question_id": questionID,
-			"reply":       replyText,
-			"usage":       usage,
-			"elapse":      time.Since(startTime).Seconds(),
+			"user_id":         user.ID,
+			"client":          client,
+			"req":             req,
+			"conversation_id": conversationID,
+			"question_id":     questionID,
+			"reply":           replyText,
+			"usage":           usage,
+			"elapse":          time.Since(startTime).Seconds(),
 		}).
 			Infof("chat request finished")
 
+		// 用户主动停止生成时，保存已经生成的内容，只扣除已经生成部分的智慧果
+		if stopped() {
+			err = ErrChatCanceled
+		}
+
 		// chat result processing
-		ctl.handleChatResult(ctx, sw, req, user.User, questionID, replyText, usage, err)
+		ctl.handleChatResult(ctx, out, req, user, conversationID, questionID, answerID, replyText, toolCalls, usage, err)
 	}()
 
 	// handle chat request
-	replyText, usage, err = ctl.handleChat(ctx, sw, req, 0)
-	if errors.Is(err, ErrChatResponseHasSent) {
+	replyText, toolCalls, usage, err = ctl.handleChat(ctx, out, req, 0)
+	if errors.Is(err, ErrChatResponseHasSent) || stopped() {
 		return
 	}
 
@@ -123,9 +240,9 @@ func (ctl *ChatController) ChatStream(
 	if errors.Is(err, ErrChatResponseEmpty) || (errors.Is(err, ErrChatResponseGapTimeout) && replyText == "") {
 		// If the user waits for more than 60 seconds, there will be no retry to prevent the user from waiting too long.
 		if startTime.Add(60 * time.Second).After(time.Now()) {
-			log.F(log.M{"req": req, "user_id": user.User.ID}).Warningf("chat response is empty, try requesting again")
+			log.F(log.M{"req": req, "user_id": user.ID}).Warningf("chat response is empty, try requesting again")
 
-			replyText, usage, err = ctl.handleChat(ctx, sw, req, 1)
+			replyText, toolCalls, usage, err = ctl.handleChat(ctx, out, req, 1)
 			if errors.Is(err, ErrChatResponseHasSent) {
 				return
 			}
@@ -134,6 +251,28 @@ func (ctl *ChatController) ChatStream(
 
 }
 
+// allowStreamRequest 检查用户是否登录以及请求频率，不允许时直接写入错误响应（此时还没有创建 StreamWriter）
+func (ctl *ChatController) allowStreamRequest(ctx context.Context, w http.ResponseWriter, client *auth.ClientInfo, user *auth.User) bool {
+	if user == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "the user is not logged in, please log in first and try again"}`))
+		return false
+	}
+
+	// rate control to avoid overuse by a single user
+	if err := ctl.rateLimit(ctx, client, user); err != nil {
+		if errors.Is(err, rate.ErrDailyFreeLimitExceeded) {
+			w.WriteHeader(http.StatusUnauthorized)
+		} else {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"error": %s}`, strconv.Quote(err.Error()))))
+		return false
+	}
+
+	return true
+}
+
 // rateLimit rate limit control
 func (ctl *ChatController) rateLimit(ctx context.Context, client *auth.ClientInfo, user *auth.User) error {
 	if err := ctl.limiter.Allow(ctx, fmt.Sprintf("chat-limit:u:%d:minute", user.ID), redis_rate.PerMinute(10)); err != nil {
@@ -151,15 +290,35 @@ var (
 	ErrChatResponseEmpty      = errors.New("chat response is empty")
 	ErrChatResponseHasSent    = errors.New("chat response has been sent")
 	ErrChatResponseGapTimeout = errors.New("waiting time between two responses is too long, forced interruption")
+	ErrChatResponseFailed     = errors.New("chat response failed")
+	ErrQuotaNotEnough         = service.ErrQuotaNotEnough
+	ErrChatCanceled           = errors.New("chat canceled by the user")
 )
 
+// responseSentError 错误已经写入客户端，errors.Is 匹配 ErrChatResponseHasSent，错误信息保留原始的错误原因
+type responseSentError struct {
+	err error
+}
+
+func (e responseSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e responseSentError) Unwrap() []error {
+	return []error{ErrChatResponseHasSent, e.err}
+}
+
 // ChatResponse chat response
 type ChatResponse struct {
 	chat.StreamResponse
+	// AnswerID the answer the chunk belongs to, the stream can be resumed by it after the client reconnects
+	AnswerID int64 `json:"answer_id,omitempty"`
+	// Index the index of the chunk in the answer, starting from 0
+	Index int64 `json:"index"`
 }
 
 // handleChat handle chat request
-func (ctl *ChatController) handleChat(ctx context.Context, sw *misc.StreamWriter, req *ChatRequest, retryTimes int) (string, *chat.Usage, error) {
+func (ctl *ChatController) handleChat(ctx context.Context, out *chatStreamWriter, req *ChatRequest, retryTimes int) (string, []chat.ToolCall, *chat.Usage, error) {
 	ctx, cancel := context.WithTimeout(ctx, 180*time.Second)
 	defer cancel()
 
@@ -170,30 +329,39 @@ func (ctl *ChatController) handleChat(ctx context.Context, sw *misc.StreamWriter
 	stream, err := ctl.chatter.ChatStream(ctx, req.Request)
 	if err != nil {
 		if errors.Is(err, chat.ErrContentFilter) {
-			ctl.writeViolateContextPolicyError(sw, err.Error())
-			return "", nil, ErrChatResponseHasSent
+			ctl.writeViolateContextPolicyError(out, err.Error())
+			return "", nil, nil, responseSentError{err: err}
 		}
 
 		log.F(log.M{"req": req, "retry_times": retryTimes}).Errorf("chat stream failed: %s", err)
-		misc.NoError(sw.WriteErrorStream(err, http.StatusInternalServerError))
-		return "", nil, ErrChatResponseHasSent
+		misc.NoError(out.sw.WriteErrorStream(err, http.StatusInternalServerError))
+		return "", nil, nil, responseSentError{err: err}
 	}
 
-	replyText, usage, err := ctl.handleChatRequest(ctx, sw, stream)
+	// 提前返回（如用户停止生成）时，继续读取剩余的响应，避免生成响应的协程阻塞
+	defer func() {
+		go func() {
+			for range stream {
+			}
+		}()
+	}()
+
+	replyText, toolCalls, usage, err := ctl.handleChatRequest(ctx, out, stream)
 	if err != nil {
-		return replyText, usage, err
+		return replyText, toolCalls, usage, err
 	}
 
+	// 只返回了工具调用的响应不是空响应，工具由客户端执行
 	replyText = strings.TrimSpace(replyText)
-	if replyText == "" {
-		return replyText, usage, ErrChatResponseEmpty
+	if replyText == "" && len(toolCalls) == 0 {
+		return replyText, toolCalls, usage, ErrChatResponseEmpty
 	}
 
-	return replyText, usage, nil
+	return replyText, toolCalls, usage, nil
 }
 
 // handleChatRequest handle chat request
-func (ctl *ChatController) handleChatRequest(ctx context.Context, sw *misc.StreamWriter, stream <-chan chat.StreamResponse) (replyText string, usage *chat.Usage, err error) {
+func (ctl *ChatController) handleChatRequest(ctx context.Context, out *chatStreamWriter, stream <-chan chat.StreamResponse) (replyText string, toolCalls []chat.ToolCall, usage *chat.Usage, err error) {
 	timer := time.NewTimer(60 * time.Second)
 	defer timer.Stop()
 
@@ -205,44 +373,50 @@ func (ctl *ChatController) handleChatRequest(ctx context.Context, sw *misc.Strea
 
 		select {
 		case <-timer.C:
-			return replyText, usage, ErrChatResponseGapTimeout
+			return replyText, toolCalls, usage, ErrChatResponseGapTimeout
 		case <-ctx.Done():
-			return replyText, usage, nil
+			return replyText, toolCalls, usage, nil
 		case res, ok := <-stream:
 			if !ok {
-				return replyText, usage, nil
+				return replyText, toolCalls, usage, nil
 			}
 
 			id++
 
 			if res.ErrorCode != "" {
-				if res.ErrorCode != "" {
-					res.ErrorMessage = fmt.Sprintf("\n\n---\nSorry, we encountered some errors, here are the error details:\n%s\n", res.ErrorMessage)
-				} else {
-					return replyText, usage, nil
-				}
-			} else {
-				replyText += res.DeltaText()
-				if res.Usage != nil {
-					usage = res.Usage
-				}
+				errorMessage := res.ErrorMessage
+				res.ErrorMessage = fmt.Sprintf("\n\n---\nSorry, we encountered some errors, here are the error details:\n%s\n", res.ErrorMessage)
+				_ = out.Write(res)
+
+				return replyText, toolCalls, usage, fmt.Errorf("%w: [%s] %s", ErrChatResponseFailed, res.ErrorCode, errorMessage)
+			}
+
+			replyText += res.DeltaText()
+			for _, choice := range res.Choices {
+				toolCalls = append(toolCalls, choice.Delta.ToolCalls...)
 			}
 
-			resp := ChatResponse{StreamResponse: res}
+			if res.Usage != nil {
+				usage = res.Usage
+			}
 
-			if err := sw.WriteStream(resp); err != nil {
-				return replyText, usage, nil
+			// 回复可以恢复时，客户端断开连接后继续读取并写入缓冲区
+			if err := out.Write(res); err != nil {
+				return replyText, toolCalls, usage, nil
 			}
 		}
 	}
 }
 
 type UsageSummary struct {
-	QuestionID int64       `json:"question_id,omitempty"`
-	AnswerID   int64       `json:"answer_id,omitempty"`
-	Quota      int64       `json:"quota,omitempty"`
-	Error      string      `json:"error,omitempty"`
-	Usage      *chat.Usage `json:"usage,omitempty"`
+	ConversationID int64       `json:"conversation_id,omitempty"`
+	QuestionID     int64       `json:"question_id,omitempty"`
+	AnswerID       int64       `json:"answer_id,omitempty"`
+	Quota          int64       `json:"quota,omitempty"`
+	Error          string      `json:"error,omitempty"`
+	Usage          *chat.Usage `json:"usage,omitempty"`
+	// Stopped the generation is stopped by the user
+	Stopped bool `json:"stopped,omitempty"`
 }
 
 func (usage UsageSummary) JSON() string {
@@ -250,42 +424,277 @@ func (usage UsageSummary) JSON() string {
 	return string(res)
 }
 
-// saveChatQuestion save chat question and return question id
-func (ctl *ChatController) saveChatQuestion(ctx context.Context, req *ChatRequest, user *auth.User) int64 {
-	// TODO 保存聊天问题
-	return 0
+// freezeChatQuota 预估本次请求的智慧果消耗，余额不足时返回 ErrQuotaNotEnough，否则冻结预估的智慧果并返回冻结数量
+func (ctl *ChatController) freezeChatQuota(ctx context.Context, req chat.Request, user *auth.User) (int64, error) {
+	// anonymous users are not billed
+	if user.IsAnonymous() {
+		return 0, nil
+	}
+
+	estimate, err := ctl.chatter.EstimateQuota(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	if estimate <= 0 {
+		return 0, nil
+	}
+
+	// 余额检查与冻结需要原子完成，否则并发请求会同时通过检查
+	if err := ctl.userSrv.FreezeUserQuota(ctx, user.ID, estimate); err != nil {
+		return 0, err
+	}
+
+	return estimate, nil
 }
 
-func (ctl *ChatController) handleChatResult(ctx context.Context, sw *misc.StreamWriter, req *ChatRequest, user *auth.User, questionID int64, replyText string, usage *chat.Usage, err error) {
-	// TODO 保存聊天结果
-	// 如果 error 不为空，则需要更新 question 的状态为 失败
-	answerID := int64(1)
+// unfreezeChatQuota 释放冻结的智慧果
+func (ctl *ChatController) unfreezeChatQuota(user *auth.User, quota int64) {
+	// the request context may have been canceled by the client
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := ctl.userSrv.UnfreezeUserQuota(ctx, user.ID, quota); err != nil {
+		log.F(log.M{"user_id": user.ID, "quota": quota}).Errorf("unfreeze chat quota failed: %s", err)
+	}
+}
+
+// saveChatQuestion save chat question and return conversation id and question id
+func (ctl *ChatController) saveChatQuestion(ctx context.Context, req *ChatRequest, user *auth.User) (int64, int64, error) {
+	// anonymous users' chat history is not saved
+	if user.IsAnonymous() {
+		return 0, 0, nil
+	}
+
+	// 重新生成回复时，问题已经保存
+	if req.QuestionID > 0 {
+		return req.ConversationID, req.QuestionID, nil
+	}
+
+	// 客户端提交工具执行结果时，提问已经在发起工具调用的那一轮保存
+	if req.ConversationID > 0 && req.Messages[len(req.Messages)-1].Role == "tool" {
+		questionID, err := ctl.repo.Conversation.CurrentQuestion(ctx, user.ID, req.ConversationID)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return req.ConversationID, questionID, nil
+	}
 
-	// 更新智慧果消耗
-	quotaConsumed := int64(0)
+	last, ok := lastUserMessage(req.Messages)
+	if !ok {
+		return 0, 0, nil
+	}
+
+	question := repo.Question{
+		ConversationID: req.ConversationID,
+		RobotID:        req.RobotID,
+		EditOf:         req.EditOf,
+		Message:        last.Content,
+	}
+
+	if len(last.MultipartContents) > 0 {
+		contents, err := json.Marshal(last.MultipartContents)
+		if err != nil {
+			return 0, 0, fmt.Errorf("marshal multipart contents failed: %w", err)
+		}
+
+		question.MultipartContents = string(contents)
+	}
+
+	return ctl.repo.Conversation.SaveQuestion(ctx, user.ID, question)
+}
+
+// lastUserMessage 获取最后一条用户消息，请求中没有用户消息时，不保存聊天记录
+func lastUserMessage(messages []chat.Message) (chat.Message, bool) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == repo.MessageRoleUser {
+			return messages[i], true
+		}
+	}
+
+	return chat.Message{}, false
+}
+
+// handleChatResult save chat result and tell the client the actual consumption
+func (ctl *ChatController) handleChatResult(
+	ctx context.Context,
+	out *chatStreamWriter,
+	req *ChatRequest,
+	user *auth.User,
+	conversationID, questionID, answerID int64,
+	replyText string,
+	toolCalls []chat.ToolCall,
+	usage *chat.Usage,
+	err error,
+) {
+	summary := ctl.saveChatResult(ctx, req, user, conversationID, questionID, answerID, replyText, toolCalls, usage, err)
+	_ = out.Write(chat.NewSystemStreamResponse("final", summary.JSON(), ""))
+	out.Finish()
+}
+
+// saveChatResult 扣除智慧果并保存回答，返回实际消耗情况
+func (ctl *ChatController) saveChatResult(
+	ctx context.Context,
+	req *ChatRequest,
+	user *auth.User,
+	conversationID, questionID, answerID int64,
+	replyText string,
+	toolCalls []chat.ToolCall,
+	usage *chat.Usage,
+	err error,
+) UsageSummary {
+	// the request context may have been canceled by the client, the result still needs to be saved
+	saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 用户主动停止生成不是错误，回复保存已经生成的内容
+	stopped := errors.Is(err, ErrChatCanceled)
+	if stopped {
+		err = nil
+	}
+
+	// 更新智慧果消耗，内容违规以及请求失败时不扣除，停止生成时只扣除已经生成的部分
+	var quotaConsumed int64
+	if err == nil {
+		quotaConsumed = ctl.chargeChatQuota(saveCtx, user, usage)
+	}
+
+	// 只包含工具调用的回复是中间结果，不作为提问的回复保存，工具由客户端执行后继续生成的回复才是最终回复
+	if err == nil && !stopped && replyText == "" && len(toolCalls) > 0 {
+		if conversationID > 0 && answerID > 0 {
+			if discardErr := ctl.repo.Conversation.DiscardAnswer(saveCtx, user.ID, conversationID, questionID, answerID); discardErr != nil {
+				log.F(log.M{"user_id": user.ID, "conversation_id": conversationID, "answer_id": answerID}).
+					Errorf("discard tool calls answer failed: %s", discardErr)
+			}
+		}
+
+		return UsageSummary{
+			ConversationID: conversationID,
+			QuestionID:     questionID,
+			Usage:          usage,
+			Quota:          quotaConsumed,
+		}
+	}
+
+	if conversationID > 0 {
+		answer := repo.Answer{
+			ID:            answerID,
+			QuestionID:    questionID,
+			RobotID:       req.RobotID,
+			Message:       replyText,
+			QuotaConsumed: quotaConsumed,
+			Stopped:       stopped,
+		}
+
+		if usage != nil {
+			answer.Model = usage.Model
+			answer.Channel = usage.Channel
+			answer.PromptTokens = usage.PromptTokens
+			answer.CompletionTokens = usage.CompletionTokens
+			answer.FirstLetterDelay = usage.FirstLetterDelay
+			answer.ConsumeInMilli = usage.ConsumeInMilli
+		}
+
+		if err != nil {
+			answer.Error = err.Error()
+		}
+
+		savedID, saveErr := ctl.repo.Conversation.SaveAnswer(saveCtx, user.ID, conversationID, answer)
+		if saveErr != nil {
+			log.F(log.M{"user_id": user.ID, "conversation_id": conversationID, "question_id": questionID}).
+				Errorf("save chat answer failed: %s", saveErr)
+		} else {
+			answerID = savedID
+		}
+	}
+
+	// 新会话完成第一轮对话后，在后台生成会话标题
+	if err == nil && req.ConversationID == 0 && conversationID > 0 && replyText != "" {
+		ctl.enqueueConversationTitle(saveCtx, req, user, conversationID, replyText)
+	}
 
 	// 告知客户端实际消耗情况
 	summary := UsageSummary{
-		QuestionID: questionID,
-		AnswerID:   answerID,
-		Usage:      usage,
-		Quota:      quotaConsumed,
+		ConversationID: conversationID,
+		QuestionID:     questionID,
+		AnswerID:       answerID,
+		Usage:          usage,
+		Quota:          quotaConsumed,
+		Stopped:        stopped,
 	}
 
 	if err != nil {
 		summary.Error = err.Error()
 	}
 
-	_ = sw.WriteStream(chat.NewSystemStreamResponse("final", summary.JSON(), "").JSON())
+	return summary
+}
+
+// enqueueConversationTitle 提交生成会话标题的任务
+func (ctl *ChatController) enqueueConversationTitle(ctx context.Context, req *ChatRequest, user *auth.User, conversationID int64, replyText string) {
+	if ctl.conf.ConversationTitle.Disabled || len(req.Messages) == 0 {
+		return
+	}
+
+	question := req.Messages[len(req.Messages)-1]
+	if question.Role != "user" || strings.TrimSpace(question.Content) == "" {
+		return
+	}
+
+	// 生成标题只使用提问以及回复的开头部分，任务载荷会保存到 Redis 以及数据库中，不能携带完整的回复
+	payload := tasks.ConversationTitlePayload{
+		UserID:         user.ID,
+		ConversationID: conversationID,
+		Question:       misc.SubStringRaw(question.Content, chat.TitleContextLength),
+		Answer:         misc.SubStringRaw(replyText, chat.TitleContextLength),
+		CreatedAt:      time.Now(),
+	}
+
+	if _, err := ctl.queue.Enqueue(ctx, &payload, asynq.Queue("default")); err != nil {
+		log.F(log.M{"user_id": user.ID, "conversation_id": conversationID}).Errorf("failed to enqueue conversation title task: %s", err)
+	}
+}
+
+// chargeChatQuota 根据 Token 使用量以及模型价格扣除用户的智慧果，返回实际扣除的数量
+func (ctl *ChatController) chargeChatQuota(ctx context.Context, user *auth.User, usage *chat.Usage) int64 {
+	if user.IsAnonymous() || usage == nil {
+		return 0
+	}
+
+	model, ok := ctl.chatter.Model(usage.Model)
+	if !ok {
+		return 0
+	}
+
+	cost := coins.GetTextModelCoins(model, usage.PromptTokens+usage.CompletionTokens)
+
+	if cost > 0 {
+		if err := ctl.repo.Quota.QuotaConsume(ctx, user.ID, cost, repo.NewQuotaUsedMeta("chat", model.ID)); err != nil {
+			log.F(log.M{"user_id": user.ID, "usage": usage, "cost": cost}).Errorf("consume chat quota failed: %s", err)
+			cost = 0
+		}
+	}
+
+	// 内置工具调用单独计费
+	if usage.ToolQuota > 0 {
+		if err := ctl.repo.Quota.QuotaConsume(ctx, user.ID, usage.ToolQuota, repo.NewQuotaUsedMeta("tool", usage.Tools...)); err != nil {
+			log.F(log.M{"user_id": user.ID, "usage": usage}).Errorf("consume tool quota failed: %s", err)
+		} else {
+			cost += usage.ToolQuota
+		}
+	}
+
+	return cost
 }
 
 const violateContentPolicyMessage = "抱歉，您的请求因包含违规内容被系统拦截，如果您对此有任何疑问或想进一步了解详情，欢迎通过以下渠道与我们联系：\n\n服务邮箱：support@aicode.cc\n\n微博：@mylxsw\n\n客服微信：x-prometheus\n\n\n---\n\n> 本次请求不扣除智慧果。"
 
-func (ctl *ChatController) writeViolateContextPolicyError(sw *misc.StreamWriter, detail string) {
+func (ctl *ChatController) writeViolateContextPolicyError(out *chatStreamWriter, detail string) {
 	reason := violateContentPolicyMessage
 	if detail != "" {
 		reason += fmt.Sprintf("\n> \n> 原因：%s", detail)
 	}
 
-	_ = sw.WriteStream(chat.NewStreamResponse("content_filter", reason, "content_filter").JSON())
+	_ = out.Write(chat.NewStreamResponse("content_filter", reason, "content_filter"))
 }
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/pkg/chat"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/web"
+	"github.com/mylxsw/go-utils/ternary"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RegenerateRequest 重新生成回复的请求
+type RegenerateRequest struct {
+	AnswerID int64 `json:"answer_id"`
+	// RobotID the robot used to regenerate the answer, the robot of the original answer is used when it is empty
+	RobotID   string `json:"robot_id,omitempty"`
+	MaxTokens int    `json:"max_tokens,omitempty"`
+}
+
+// Init initialize regenerate request
+func (req RegenerateRequest) Init() RegenerateRequest {
+	return req
+}
+
+// Regenerate 重新生成回复，新的回复和原回复互为兄弟节点，上下文为原回复所在分支中问题之前的对话
+func (ctl *ChatController) Regenerate(
+	ctx context.Context,
+	webCtx web.Context,
+	user *auth.UserOptional,
+	client *auth.ClientInfo,
+	w http.ResponseWriter,
+) {
+	if !ctl.allowStreamRequest(ctx, w, client, user.User) {
+		return
+	}
+
+	sw, req, err := misc.NewStreamWriter[RegenerateRequest](
+		webCtx.Input("ws") == "true", ctl.conf.EnableCORS, webCtx.Request().Raw(), w,
+	)
+	if err != nil {
+		log.F(log.M{"user": user.User.ID, "client": client}).Errorf("create stream writer failed: %s", err)
+		return
+	}
+	defer sw.Close()
+
+	tree, answer, err := ctl.loadBranchMessage(ctx, user.User.ID, req.AnswerID, repo.MessageRoleAssistant)
+	if err != nil {
+		ctl.writeBranchError(sw, user.User.ID, req.AnswerID, err)
+		return
+	}
+
+	questionID := tree.Parent(answer.Id)
+	chatReq := &ChatRequest{
+		Request: chat.Request{
+			RobotID:   ternary.If(req.RobotID != "", req.RobotID, answer.RobotId),
+			Messages:  historyMessages(tree.Path(questionID)),
+			MaxTokens: req.MaxTokens,
+		},
+		ConversationID: tree.ConversationID,
+		QuestionID:     questionID,
+	}
+
+	if questionID == 0 || len(chatReq.Messages) == 0 {
+		misc.NoError(sw.WriteErrorStream(errors.New("question not found"), http.StatusNotFound))
+		return
+	}
+
+	ctl.streamChat(ctx, sw, user.User, client, chatReq)
+}
+
+// EditQuestionRequest 编辑提问后重新发送的请求
+type EditQuestionRequest struct {
+	QuestionID        int64                    `json:"question_id"`
+	Message           string                   `json:"message"`
+	MultipartContents []*chat.MultipartContent `json:"multipart_content,omitempty"`
+	// RobotID the robot used to answer the edited question, the robot of the original question is used when it is empty
+	RobotID   string `json:"robot_id,omitempty"`
+	MaxTokens int    `json:"max_tokens,omitempty"`
+}
+
+// Init initialize edit question request
+func (req EditQuestionRequest) Init() EditQuestionRequest {
+	return req
+}
+
+// EditQuestion 编辑提问后重新发送，新的提问和原提问互为兄弟节点，上下文为原提问之前的对话
+func (ctl *ChatController) EditQuestion(
+	ctx context.Context,
+	webCtx web.Context,
+	user *auth.UserOptional,
+	client *auth.ClientInfo,
+	w http.ResponseWriter,
+) {
+	if !ctl.allowStreamRequest(ctx, w, client, user.User) {
+		return
+	}
+
+	sw, req, err := misc.NewStreamWriter[EditQuestionRequest](
+		webCtx.Input("ws") == "true", ctl.conf.EnableCORS, webCtx.Request().Raw(), w,
+	)
+	if err != nil {
+		log.F(log.M{"user": user.User.ID, "client": client}).Errorf("create stream writer failed: %s", err)
+		return
+	}
+	defer sw.Close()
+
+	if strings.TrimSpace(req.Message) == "" && len(req.MultipartContents) == 0 {
+		misc.NoError(sw.WriteErrorStream(errors.New("message is required"), http.StatusBadRequest))
+		return
+	}
+
+	tree, question, err := ctl.loadBranchMessage(ctx, user.User.ID, req.QuestionID, repo.MessageRoleUser)
+	if err != nil {
+		ctl.writeBranchError(sw, user.User.ID, req.QuestionID, err)
+		return
+	}
+
+	messages := historyMessages(tree.Path(tree.Parent(question.Id)))
+	messages = append(messages, chat.Message{
+		Role:              "user",
+		Content:           req.Message,
+		MultipartContents: req.MultipartContents,
+	})
+
+	ctl.streamChat(ctx, sw, user.User, client, &ChatRequest{
+		Request: chat.Request{
+			RobotID:   ternary.If(req.RobotID != "", req.RobotID, question.RobotId),
+			Messages:  messages,
+			MaxTokens: req.MaxTokens,
+		},
+		ConversationID: tree.ConversationID,
+		EditOf:         question.Id,
+	})
+}
+
+// SwitchBranchRequest 切换分支的请求
+type SwitchBranchRequest struct {
+	// MessageID the message to switch to, usually one of the siblings of a message in the current branch
+	MessageID int64 `json:"message_id"`
+}
+
+// BranchMessage 当前分支中的一条消息
+type BranchMessage struct {
+	ID                int64                    `json:"id"`
+	Role              string                   `json:"role"`
+	Message           string                   `json:"message"`
+	MultipartContents []*chat.MultipartContent `json:"multipart_content,omitempty"`
+	RobotID           string                   `json:"robot_id,omitempty"`
+	Model             string                   `json:"model,omitempty"`
+	Status            string                   `json:"status"`
+	// Siblings all messages at the same position (including itself) in creation order, used to switch between branches
+	Siblings  []int64   `json:"siblings,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SwitchBranch 切换会话的当前分支，返回切换后的分支中的所有消息
+func (ctl *ChatController) SwitchBranch(ctx context.Context, webCtx web.Context, user *auth.UserOptional) web.Response {
+	if user.User == nil || user.User.IsAnonymous() {
+		return webCtx.JSONError("the user is not logged in, please log in first and try again", http.StatusUnauthorized)
+	}
+
+	var req SwitchBranchRequest
+	if err := webCtx.Unmarshal(&req); err != nil || req.MessageID <= 0 {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	tree, err := ctl.repo.Conversation.SwitchBranch(ctx, user.User.ID, req.MessageID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError("message not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.User.ID, "message_id": req.MessageID}).Errorf("switch branch failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{
+		"conversation_id":    tree.ConversationID,
+		"current_message_id": tree.Current,
+		"messages":           branchMessages(tree),
+	})
+}
+
+// loadBranchMessage 加载消息所在会话的消息树，消息不存在或者角色不匹配时返回 repo.ErrNotFound
+func (ctl *ChatController) loadBranchMessage(ctx context.Context, userID int64, messageID int64, role string) (*repo.MessageTree, *model.ChatMessages, error) {
+	if userID <= 0 {
+		return nil, nil, repo.ErrNotFound
+	}
+
+	msg, err := ctl.repo.Conversation.GetMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if msg.Role != role {
+		return nil, nil, repo.ErrNotFound
+	}
+
+	tree, err := ctl.repo.Conversation.MessageTree(ctx, userID, msg.ConversationId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tree, msg, nil
+}
+
+// writeBranchError 写入加载消息失败的错误
+func (ctl *ChatController) writeBranchError(sw *misc.StreamWriter, userID int64, messageID int64, err error) {
+	if errors.Is(err, repo.ErrNotFound) {
+		misc.NoError(sw.WriteErrorStream(errors.New("message not found"), http.StatusNotFound))
+		return
+	}
+
+	log.F(log.M{"user_id": userID, "message_id": messageID}).Errorf("load conversation messages failed: %s", err)
+	misc.NoError(sw.WriteErrorStream(errors.New(InternalServerError), http.StatusInternalServerError))
+}
+
+// historyMessages 将保存的对话转换为聊天上下文，失败以及还在生成的回复不作为上下文
+func historyMessages(path []model.ChatMessages) chat.Messages {
+	messages := make(chat.Messages, 0, len(path))
+	for _, msg := range path {
+		if msg.Role == repo.MessageRoleAssistant {
+			if (msg.Status != repo.MessageStatusSucceed && msg.Status != repo.MessageStatusStopped) || strings.TrimSpace(msg.Message) == "" {
+				continue
+			}
+
+			messages = append(messages, chat.Message{Role: "assistant", Content: msg.Message})
+			continue
+		}
+
+		messages = append(messages, chat.Message{
+			Role:              "user",
+			Content:           msg.Message,
+			MultipartContents: parseMultipartContents(msg.MultipartContents),
+		})
+	}
+
+	return messages
+}
+
+// branchMessages 当前分支中的所有消息以及每条消息的兄弟节点
+func branchMessages(tree *repo.MessageTree) []BranchMessage {
+	path := tree.ActivePath()
+	messages := make([]BranchMessage, 0, len(path))
+	for _, msg := range path {
+		item := BranchMessage{
+			ID:                msg.Id,
+			Role:              msg.Role,
+			Message:           msg.Message,
+			MultipartContents: parseMultipartContents(msg.MultipartContents),
+			RobotID:           msg.RobotId,
+			Model:             msg.Model,
+			Status:            msg.Status,
+			CreatedAt:         msg.CreatedAt,
+		}
+
+		if siblings := tree.Siblings(msg.Id); len(siblings) > 1 {
+			item.Siblings = siblings
+		}
+
+		messages = append(messages, item)
+	}
+
+	return messages
+}
+
+// parseMultipartContents 解析保存的多模态消息内容，内容无效时忽略
+func parseMultipartContents(data string) []*chat.MultipartContent {
+	if data == "" {
+		return nil
+	}
+
+	var contents []*chat.MultipartContent
+	if err := json.Unmarshal([]byte(data), &contents); err != nil {
+		return nil
+	}
+
+	return contents
+}
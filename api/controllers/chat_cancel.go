@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/web"
+	"net/http"
+)
+
+// CancelChatRequest 停止生成的请求
+type CancelChatRequest struct {
+	AnswerID int64 `json:"answer_id"`
+}
+
+// CancelChat 停止正在生成的回复，已经生成的内容会被保存，只扣除已经生成部分的智慧果
+//
+// 回复可能在其它实例上生成，取消请求会广播给所有实例
+func (ctl *ChatController) CancelChat(ctx context.Context, webCtx web.Context, user *auth.UserOptional) web.Response {
+	if user.User == nil {
+		return webCtx.JSONError("the user is not logged in, please log in first and try again", http.StatusUnauthorized)
+	}
+
+	var req CancelChatRequest
+	if err := webCtx.Unmarshal(&req); err != nil || req.AnswerID <= 0 {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	msg, err := ctl.repo.Conversation.GetMessage(ctx, user.User.ID, req.AnswerID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError("answer not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.User.ID, "answer_id": req.AnswerID}).Errorf("query answer failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	if msg.Role != repo.MessageRoleAssistant {
+		return webCtx.JSONError("answer not found", http.StatusNotFound)
+	}
+
+	// 回复已经生成完成，无需停止
+	if msg.Status != repo.MessageStatusPending {
+		return webCtx.JSON(web.M{})
+	}
+
+	if err := ctl.canceller.Cancel(ctx, req.AnswerID); err != nil {
+		log.F(log.M{"user_id": user.User.ID, "answer_id": req.AnswerID}).Errorf("cancel chat failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
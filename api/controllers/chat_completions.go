@@ -0,0 +1,393 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/pkg/chat"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/rate"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/web"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CompletionMessage OpenAI compatible chat message, content can be a string or an array of content parts
+type CompletionMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+	// ToolCalls the tool calls generated by the model, only for assistant messages
+	ToolCalls []chat.ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID the tool call that this message is responding to, only for tool messages
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ToMessage convert to chat.Message
+func (msg CompletionMessage) ToMessage() (chat.Message, error) {
+	ret := chat.Message{Role: msg.Role, ToolCalls: msg.ToolCalls, ToolCallID: msg.ToolCallID}
+	if len(msg.Content) == 0 || string(msg.Content) == "null" {
+		return ret, nil
+	}
+
+	if err := json.Unmarshal(msg.Content, &ret.Content); err == nil {
+		return ret, nil
+	}
+
+	if err := json.Unmarshal(msg.Content, &ret.MultipartContents); err != nil {
+		return ret, fmt.Errorf("invalid message content: %w", err)
+	}
+
+	texts := make([]string, 0)
+	for _, part := range ret.MultipartContents {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	ret.Content = strings.Join(texts, "\n")
+
+	return ret, nil
+}
+
+// CompletionRequest OpenAI compatible chat completion request
+type CompletionRequest struct {
+	// Model model id or robot id
+	Model     string              `json:"model"`
+	Messages  []CompletionMessage `json:"messages"`
+	Stream    bool                `json:"stream,omitempty"`
+	MaxTokens int                 `json:"max_tokens,omitempty"`
+	// StreamOptions options for streaming response, only when stream is true
+	StreamOptions *CompletionStreamOptions `json:"stream_options,omitempty"`
+	// Tools a list of tools the model may call
+	Tools []chat.Tool `json:"tools,omitempty"`
+	// ToolChoice controls which (if any) tool is called by the model, string or object
+	ToolChoice any `json:"tool_choice,omitempty"`
+}
+
+// CompletionStreamOptions OpenAI compatible stream options
+type CompletionStreamOptions struct {
+	// IncludeUsage an additional chunk with the token usage of the entire request will be streamed before the [DONE] message
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+// includeUsage whether to stream the token usage before the [DONE] message
+func (req CompletionRequest) includeUsage() bool {
+	return req.Stream && req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+}
+
+// ToChatRequest convert to chat.Request
+func (req CompletionRequest) ToChatRequest(userID int64) (chat.Request, error) {
+	messages := make(chat.Messages, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		m, err := msg.ToMessage()
+		if err != nil {
+			return chat.Request{}, err
+		}
+
+		messages = append(messages, m)
+	}
+
+	return chat.Request{
+		UserID:     userID,
+		RobotID:    req.Model,
+		Messages:   messages,
+		MaxTokens:  req.MaxTokens,
+		Tools:      req.Tools,
+		ToolChoice: req.ToolChoice,
+	}, nil
+}
+
+// CompletionUsage OpenAI compatible token usage
+type CompletionUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// newCompletionUsage convert chat.Usage to CompletionUsage
+func newCompletionUsage(usage *chat.Usage) *CompletionUsage {
+	if usage == nil {
+		return &CompletionUsage{}
+	}
+
+	return &CompletionUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.PromptTokens + usage.CompletionTokens,
+	}
+}
+
+// CompletionMessageResponse the message generated by the model
+type CompletionMessageResponse struct {
+	Role      string          `json:"role,omitempty"`
+	Content   string          `json:"content"`
+	ToolCalls []chat.ToolCall `json:"tool_calls,omitempty"`
+}
+
+// CompletionChoice OpenAI compatible chat completion choice
+type CompletionChoice struct {
+	Index        int                        `json:"index"`
+	Message      *CompletionMessageResponse `json:"message,omitempty"`
+	Delta        *CompletionMessageResponse `json:"delta,omitempty"`
+	FinishReason *string                    `json:"finish_reason"`
+}
+
+// CompletionResponse OpenAI compatible chat completion response, used for both streaming chunks and full responses
+type CompletionResponse struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []CompletionChoice `json:"choices"`
+	Usage   *CompletionUsage   `json:"usage,omitempty"`
+}
+
+// CompletionError OpenAI compatible error response
+type CompletionError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+}
+
+// writeCompletionError 返回 OpenAI 格式的错误响应，code 与 type 相同
+func writeCompletionError(w http.ResponseWriter, statusCode int, errType string, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	misc.NoError2(w.Write([]byte(misc.NoError2(json.Marshal(web.M{
+		"error": CompletionError{Message: message, Type: errType, Code: errType},
+	})))))
+}
+
+// Completions OpenAI compatible chat completion API, supports both streaming and non-streaming mode
+func (ctl *ChatController) Completions(
+	ctx context.Context,
+	webCtx web.Context,
+	user *auth.User,
+	client *auth.ClientInfo,
+	w http.ResponseWriter,
+) {
+	// rate control to avoid overuse by a single user
+	if err := ctl.rateLimit(ctx, client, user); err != nil {
+		writeCompletionError(w, http.StatusTooManyRequests, "rate_limit_exceeded", rate.ErrRateLimitExceeded.Error())
+		return
+	}
+
+	var completionReq CompletionRequest
+	if err := json.NewDecoder(webCtx.Request().Raw().Body).Decode(&completionReq); err != nil {
+		writeCompletionError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+
+	req, err := completionReq.ToChatRequest(user.ID)
+	if err != nil {
+		writeCompletionError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	if req.RobotID == "" || len(req.Messages) == 0 {
+		writeCompletionError(w, http.StatusBadRequest, "invalid_request_error", "model and messages are required")
+		return
+	}
+
+	// 检查用户的智慧果余额是否足够，并冻结本次请求预估消耗的智慧果
+	frozenQuota, err := ctl.freezeChatQuota(ctx, req, user)
+	if err != nil {
+		if errors.Is(err, ErrQuotaNotEnough) {
+			writeCompletionError(w, http.StatusPaymentRequired, "insufficient_quota", QuotaNotEnoughError)
+			return
+		}
+
+		if errors.Is(err, chat.ErrContextExceedLimit) {
+			writeCompletionError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+
+		if errors.Is(err, repo.ErrNotFound) {
+			writeCompletionError(w, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("the model `%s` does not exist", req.RobotID))
+			return
+		}
+
+		log.F(log.M{"user_id": user.ID, "req": req}).Errorf("freeze chat quota failed: %s", err)
+		writeCompletionError(w, http.StatusInternalServerError, "server_error", InternalServerError)
+		return
+	}
+	defer ctl.unfreezeChatQuota(user, frozenQuota)
+
+	chatCtx, cancel := context.WithTimeout(ctx, 180*time.Second)
+	defer cancel()
+
+	stream, err := ctl.chatter.ChatStream(chatCtx, req)
+	if err != nil {
+		if errors.Is(err, chat.ErrContentFilter) {
+			writeCompletionError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+
+		log.F(log.M{"user_id": user.ID, "req": req}).Errorf("chat completion failed: %s", err)
+		writeCompletionError(w, http.StatusInternalServerError, "server_error", InternalServerError)
+		return
+	}
+
+	sw := misc.NewSSEStreamWriter(ctl.conf.EnableCORS, webCtx.Request().Raw(), w)
+	// 已经开始返回流式响应，出错时只能通过流返回错误
+	streamStarted := false
+	defer func() {
+		if streamStarted {
+			sw.Close()
+		}
+	}()
+
+	id := "chatcmpl-" + misc.UUID()
+	created := time.Now().Unix()
+
+	var replyText, finishReason string
+	var toolCalls []chat.ToolCall
+	var usage *chat.Usage
+	var streamErr error
+
+	for res := range stream {
+		if res.ErrorCode != "" {
+			streamErr = fmt.Errorf("%w: [%s] %s", ErrChatResponseFailed, res.ErrorCode, res.ErrorMessage)
+			break
+		}
+
+		if res.Usage != nil {
+			usage = res.Usage
+		}
+
+		// 内置工具的执行过程以及知识库引用不属于 OpenAI 兼容的响应格式
+		if res.ToolStep != nil || len(res.References) > 0 {
+			continue
+		}
+
+		delta := res.DeltaText()
+		replyText += delta
+
+		var deltaToolCalls []chat.ToolCall
+		if len(res.Choices) > 0 {
+			deltaToolCalls = res.Choices[0].Delta.ToolCalls
+			toolCalls = append(toolCalls, deltaToolCalls...)
+			if res.Choices[0].FinishReason != "" {
+				finishReason = res.Choices[0].FinishReason
+			}
+		}
+
+		if completionReq.Stream {
+			chunk := CompletionResponse{
+				ID:      id,
+				Object:  "chat.completion.chunk",
+				Created: created,
+				Model:   req.RobotID,
+				Choices: []CompletionChoice{{Delta: &CompletionMessageResponse{Content: delta, ToolCalls: deltaToolCalls}}},
+			}
+
+			// 只有第一个分片包含角色
+			if !streamStarted {
+				chunk.Choices[0].Delta.Role = "assistant"
+			}
+
+			if finishReason != "" {
+				chunk.Choices[0].FinishReason = &finishReason
+			}
+
+			streamStarted = true
+			if err := sw.WriteStream(chunk); err != nil {
+				break
+			}
+		}
+	}
+
+	// 客户端断开连接或者出错提前结束时，取消上游请求并读取剩余的响应，避免生成响应的协程阻塞
+	cancel()
+	for range stream {
+	}
+
+	// 更新智慧果消耗，请求失败时不扣除
+	billCtx, billCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer billCancel()
+
+	if streamErr == nil {
+		ctl.chargeChatQuota(billCtx, user, usage)
+	}
+
+	log.F(log.M{
+		"user_id": user.ID,
+		"client":  client,
+		"model":   req.RobotID,
+		"usage":   usage,
+		"error":   streamErr,
+	}).Infof("chat completion finished")
+
+	// 上游没有返回任何内容时，流式请求同样返回结束分片
+	if completionReq.Stream && streamErr == nil && !streamStarted {
+		if finishReason == "" {
+			finishReason = "stop"
+		}
+
+		streamStarted = true
+		misc.NoError(sw.WriteStream(CompletionResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   req.RobotID,
+			Choices: []CompletionChoice{{Delta: &CompletionMessageResponse{Role: "assistant"}, FinishReason: &finishReason}},
+		}))
+	}
+
+	if streamStarted {
+		if streamErr != nil {
+			misc.NoError(sw.WriteStream(web.M{"error": CompletionError{Message: streamErr.Error(), Type: "server_error", Code: "server_error"}}))
+			return
+		}
+
+		// 开启 include_usage 时，在 [DONE] 之前返回只包含使用量的分片
+		if completionReq.includeUsage() {
+			misc.NoError(sw.WriteStream(CompletionResponse{
+				ID:      id,
+				Object:  "chat.completion.chunk",
+				Created: created,
+				Model:   req.RobotID,
+				Choices: []CompletionChoice{},
+				Usage:   newCompletionUsage(usage),
+			}))
+		}
+
+		return
+	}
+
+	if streamErr != nil {
+		writeCompletionError(w, http.StatusBadGateway, "server_error", streamErr.Error())
+		return
+	}
+
+	if finishReason == "" {
+		finishReason = "stop"
+		if len(toolCalls) > 0 {
+			finishReason = "tool_calls"
+		}
+	}
+
+	resp := CompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: created,
+		Model:   req.RobotID,
+		Choices: []CompletionChoice{
+			{
+				Message:      &CompletionMessageResponse{Role: "assistant", Content: replyText, ToolCalls: toolCalls},
+				FinishReason: &finishReason,
+			},
+		},
+	}
+
+	if usage != nil {
+		resp.Usage = newCompletionUsage(usage)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	misc.NoError2(w.Write(misc.NoError2(json.Marshal(resp))))
+}
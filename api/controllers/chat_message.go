@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/pkg/chat"
+	"github.com/mylxsw/aidea-chat-server/pkg/knowledge"
+	"github.com/mylxsw/aidea-chat-server/pkg/rate"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/web"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ChatMessageResponse 非流式聊天响应
+type ChatMessageResponse struct {
+	UsageSummary
+	// Message the reply message
+	Message chat.Message `json:"message"`
+	// FinishReason the reason the model stopped generating tokens
+	FinishReason string `json:"finish_reason,omitempty"`
+	// References the knowledge base content referenced by the answer
+	References []knowledge.Reference `json:"references,omitempty"`
+	// ToolSteps the results of the built-in tools executed during the chat
+	ToolSteps []chat.ToolStep `json:"tool_steps,omitempty"`
+}
+
+// Chat 非流式聊天，等待回复完成后一次性返回完整的消息、Token 使用量以及问题、回答 ID，计费以及重试方式和流式接口一致
+func (ctl *ChatController) Chat(
+	ctx context.Context,
+	webCtx web.Context,
+	user *auth.UserOptional,
+	client *auth.ClientInfo,
+) web.Response {
+	if user.User == nil && ctl.conf.EnableAnonymousChat {
+		user.User = &auth.User{}
+	}
+
+	if user.User == nil {
+		return webCtx.JSONError("the user is not logged in, please log in first and try again", http.StatusUnauthorized)
+	}
+
+	// rate control to avoid overuse by a single user
+	if err := ctl.rateLimit(ctx, client, user.User); err != nil {
+		if errors.Is(err, rate.ErrDailyFreeLimitExceeded) {
+			return webCtx.JSONError(err.Error(), http.StatusUnauthorized)
+		}
+
+		return webCtx.JSONError(err.Error(), http.StatusTooManyRequests)
+	}
+
+	var req ChatRequest
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	if len(req.Messages) == 0 {
+		return webCtx.JSONError("messages is required", http.StatusBadRequest)
+	}
+
+	req.UserID = user.User.ID
+
+	startTime := time.Now()
+
+	// 检查用户的智慧果余额是否足够，并冻结本次请求预估消耗的智慧果
+	frozenQuota, err := ctl.freezeChatQuota(ctx, req.Request, user.User)
+	if err != nil {
+		if errors.Is(err, ErrQuotaNotEnough) {
+			return webCtx.JSONError(QuotaNotEnoughError, http.StatusPaymentRequired)
+		}
+
+		if errors.Is(err, chat.ErrContextExceedLimit) {
+			return webCtx.JSONError(err.Error(), http.StatusBadRequest)
+		}
+
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError("robot not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.User.ID, "req": req}).Errorf("freeze chat quota failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	// 实际扣费完成后，释放冻结的智慧果
+	defer ctl.unfreezeChatQuota(user.User, frozenQuota)
+
+	// save chat question
+	conversationID, questionID, err := ctl.saveChatQuestion(ctx, &req, user.User)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError("conversation not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.User.ID, "req": req}).Errorf("save chat question failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	// 上下文摘要按照会话缓存
+	req.Request.ConversationID = conversationID
+
+	comp, err := ctl.handleChatMessage(ctx, &req, 0)
+
+	// 回复为空或者等待时间过长并且回复为空时，和流式接口一样重试一次
+	if errors.Is(err, ErrChatResponseEmpty) || (errors.Is(err, ErrChatResponseGapTimeout) && (comp == nil || comp.Message.Content == "")) {
+		if startTime.Add(60 * time.Second).After(time.Now()) {
+			log.F(log.M{"req": req, "user_id": user.User.ID}).Warningf("chat response is empty, try requesting again")
+			comp, err = ctl.handleChatMessage(ctx, &req, 1)
+		}
+	}
+
+	if comp == nil {
+		comp = &chat.Completion{Message: chat.Message{Role: "assistant"}}
+	}
+
+	log.F(log.M{
+		"user_id":         user.User.ID,
+		"client":          client,
+		"req":             req,
+		"conversation_id": conversationID,
+		"question_id":     questionID,
+		"reply":           comp.Message.Content,
+		"usage":           comp.Usage,
+		"elapse":          time.Since(startTime).Seconds(),
+	}).
+		Infof("chat request finished")
+
+	summary := ctl.saveChatResult(ctx, &req, user.User, conversationID, questionID, 0, comp.Message.Content, comp.Message.ToolCalls, comp.Usage, err)
+
+	if errors.Is(err, chat.ErrContentFilter) {
+		return webCtx.JSONWithCode(web.M{"error": violateContentPolicyMessage, "answer_id": summary.AnswerID}, http.StatusBadRequest)
+	}
+
+	resp := ChatMessageResponse{
+		UsageSummary: summary,
+		Message:      comp.Message,
+		FinishReason: comp.FinishReason,
+		References:   comp.References,
+		ToolSteps:    comp.ToolSteps,
+	}
+
+	if err != nil {
+		return webCtx.JSONWithCode(resp, http.StatusBadGateway)
+	}
+
+	return webCtx.JSON(resp)
+}
+
+// handleChatMessage 发起非流式聊天请求，错误的处理方式和 handleChat 一致
+func (ctl *ChatController) handleChatMessage(ctx context.Context, req *ChatRequest, retryTimes int) (*chat.Completion, error) {
+	ctx, cancel := context.WithTimeout(ctx, 180*time.Second)
+	defer cancel()
+
+	if retryTimes > 0 {
+		ctx = chat.NewContext(ctx, &chat.Control{PreferBackup: true})
+	}
+
+	comp, err := ctl.chatter.Chat(ctx, req.Request)
+	if err != nil {
+		if comp == nil {
+			if !errors.Is(err, chat.ErrContentFilter) {
+				log.F(log.M{"req": req, "retry_times": retryTimes}).Errorf("chat failed: %s", err)
+			}
+
+			return nil, err
+		}
+
+		var streamErr *chat.StreamError
+		switch {
+		case errors.As(err, &streamErr):
+			return comp, fmt.Errorf("%w: %s", ErrChatResponseFailed, streamErr.Error())
+		case errors.Is(err, chat.ErrResponseGapTimeout):
+			return comp, ErrChatResponseGapTimeout
+		case ctx.Err() == nil:
+			return comp, err
+		}
+
+		// 请求超时或者客户端断开连接时，和流式接口一样使用已经生成的内容
+	}
+
+	// 只返回了工具调用的响应不是空响应，工具由客户端执行
+	comp.Message.Content = strings.TrimSpace(comp.Message.Content)
+	if comp.Message.Content == "" && len(comp.Message.ToolCalls) == 0 {
+		return comp, ErrChatResponseEmpty
+	}
+
+	return comp, nil
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/pkg/chat"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/web"
+	"net/http"
+	"time"
+)
+
+// chatStreamWriter 将回复写入客户端，回复可以恢复时同时写入缓冲区
+//
+// 客户端断开连接后，写入客户端失败不会中断回复的生成，后续的分片只写入缓冲区
+type chatStreamWriter struct {
+	sw *misc.StreamWriter
+	// buffer the stream buffer, nil means the stream is not resumable
+	buffer   *chat.StreamBuffer
+	answerID int64
+
+	index      int64
+	clientGone bool
+}
+
+// Write 写入一个分片，只有回复不可恢复并且客户端写入失败时才返回错误
+func (out *chatStreamWriter) Write(res chat.StreamResponse) error {
+	data, err := json.Marshal(ChatResponse{StreamResponse: res, AnswerID: out.answerID, Index: out.index})
+	if err != nil {
+		return err
+	}
+
+	out.index++
+
+	if out.buffer != nil {
+		// the request context may have been canceled by the client
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		if err := out.buffer.Append(ctx, out.answerID, string(data)); err != nil {
+			log.F(log.M{"answer_id": out.answerID, "index": out.index - 1}).Warningf("append stream buffer failed: %s", err)
+		}
+	}
+
+	if out.clientGone {
+		return nil
+	}
+
+	if err := out.sw.WriteStream(string(data)); err != nil {
+		if out.buffer == nil {
+			return err
+		}
+
+		out.clientGone = true
+		log.F(log.M{"answer_id": out.answerID}).Debugf("client disconnected, continue generating into the stream buffer")
+	}
+
+	return nil
+}
+
+// Finish 标记回复已经生成完成
+func (out *chatStreamWriter) Finish() {
+	if out.buffer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := out.buffer.Finish(ctx, out.answerID); err != nil {
+		log.F(log.M{"answer_id": out.answerID}).Warningf("finish stream buffer failed: %s", err)
+	}
+}
+
+// ResumeChatRequest 恢复回复的请求
+type ResumeChatRequest struct {
+	AnswerID int64 `json:"answer_id"`
+	// Index the index of the first chunk to read, usually the index of the last received chunk + 1
+	Index int64 `json:"index"`
+}
+
+// Init initialize resume chat request
+func (req ResumeChatRequest) Init() ResumeChatRequest {
+	return req
+}
+
+// resumePollInterval 回复还在生成时，读取缓冲区的间隔
+const resumePollInterval = 300 * time.Millisecond
+
+// ResumeStream 从缓冲区中读取回复，支持 SSE 和 WebSocket，回复还在生成时持续等待新的分片，直到回复完成
+func (ctl *ChatController) ResumeStream(
+	ctx context.Context,
+	webCtx web.Context,
+	user *auth.UserOptional,
+	w http.ResponseWriter,
+) {
+	if user.User == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "the user is not logged in, please log in first and try again"}`))
+		return
+	}
+
+	sw, req, err := misc.NewStreamWriter[ResumeChatRequest](
+		webCtx.Input("ws") == "true", ctl.conf.EnableCORS, webCtx.Request().Raw(), w,
+	)
+	if err != nil {
+		log.F(log.M{"user": user.User.ID}).Errorf("create stream writer failed: %s", err)
+		return
+	}
+	defer sw.Close()
+
+	owner, err := ctl.buffer.Owner(ctx, req.AnswerID)
+	if err != nil {
+		if errors.Is(err, chat.ErrStreamBufferNotFound) {
+			misc.NoError(sw.WriteErrorStream(err, http.StatusNotFound))
+			return
+		}
+
+		log.F(log.M{"user_id": user.User.ID, "answer_id": req.AnswerID}).Errorf("query stream buffer failed: %s", err)
+		misc.NoError(sw.WriteErrorStream(errors.New(InternalServerError), http.StatusInternalServerError))
+		return
+	}
+
+	if owner != user.User.ID {
+		misc.NoError(sw.WriteErrorStream(chat.ErrStreamBufferNotFound, http.StatusNotFound))
+		return
+	}
+
+	// 恢复后的连接上同样可以发送取消消息停止生成
+	sw.OnCancel(func() {
+		if err := ctl.canceller.Cancel(context.Background(), req.AnswerID); err != nil {
+			log.F(log.M{"user_id": user.User.ID, "answer_id": req.AnswerID}).Errorf("cancel chat failed: %s", err)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, chat.StreamBufferTTL)
+	defer cancel()
+
+	index := req.Index
+	if index < 0 {
+		index = 0
+	}
+
+	for {
+		chunks, done, err := ctl.buffer.Read(ctx, req.AnswerID, index)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.F(log.M{"user_id": user.User.ID, "answer_id": req.AnswerID}).Errorf("read stream buffer failed: %s", err)
+				misc.NoError(sw.WriteErrorStream(errors.New(InternalServerError), http.StatusInternalServerError))
+			}
+
+			return
+		}
+
+		for _, chunk := range chunks {
+			if err := sw.WriteStream(chunk); err != nil {
+				return
+			}
+		}
+
+		index += int64(len(chunks))
+		if done {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resumePollInterval):
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConversationController 会话历史管理
+type ConversationController struct {
+	repo *repo.Repository `autowire:"@"`
+}
+
+// NewConversationController 创建会话历史控制器
+func NewConversationController(resolver infra.Resolver) web.Controller {
+	ctl := ConversationController{}
+	resolver.MustAutoWire(&ctl)
+	return &ctl
+}
+
+func (ctl *ConversationController) Register(router web.Router) {
+	router.Group("/conversations", func(router web.Router) {
+		router.Get("/", ctl.Conversations)
+		router.Get("/search", ctl.Search)
+		router.Get("/{id}/messages", ctl.Messages)
+		router.Get("/{id}/export", ctl.Export)
+		router.Put("/{id}", ctl.Rename)
+		router.Delete("/{id}", ctl.Delete)
+	})
+}
+
+const (
+	// defaultConversationsPerPage 每页返回的会话数量
+	defaultConversationsPerPage = 20
+	// defaultMessagesPerPage 每页返回的消息数量
+	defaultMessagesPerPage = 50
+	// maxItemsPerPage 每页最多返回的数量
+	maxItemsPerPage = 100
+)
+
+// pagination 从请求中读取分页参数
+func pagination(webCtx web.Context, defaultPerPage int64) (page int64, perPage int64) {
+	page = webCtx.Int64Input("page", 1)
+	if page <= 0 {
+		page = 1
+	}
+
+	perPage = webCtx.Int64Input("per_page", defaultPerPage)
+	if perPage <= 0 || perPage > maxItemsPerPage {
+		perPage = defaultPerPage
+	}
+
+	return page, perPage
+}
+
+// Conversations 分页获取当前用户的会话列表，按照最后一条消息的时间倒序排列
+func (ctl *ConversationController) Conversations(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	page, perPage := pagination(webCtx, defaultConversationsPerPage)
+
+	convs, meta, err := ctl.repo.Conversation.Conversations(ctx, user.ID, page, perPage)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query conversations failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"data": convs, "meta": meta})
+}
+
+// Messages 分页获取会话当前分支中的消息
+//
+// 第一页为最近的消息，每一页中的消息按照时间顺序排列
+func (ctl *ConversationController) Messages(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.ParseInt(webCtx.PathVar("id"), 10, 64)
+	if err != nil {
+		return webCtx.JSONError("invalid conversation id", http.StatusBadRequest)
+	}
+
+	tree, err := ctl.repo.Conversation.MessageTree(ctx, user.ID, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError("conversation not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "conversation_id": id}).Errorf("query conversation messages failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	page, perPage := pagination(webCtx, defaultMessagesPerPage)
+	messages := branchMessages(tree)
+
+	meta := query.PaginateMeta{
+		Page:     page,
+		PerPage:  perPage,
+		Total:    int64(len(messages)),
+		LastPage: (int64(len(messages)) + perPage - 1) / perPage,
+	}
+
+	end := int64(len(messages)) - (page-1)*perPage
+	start := end - perPage
+	if start < 0 {
+		start = 0
+	}
+
+	if end <= 0 {
+		messages = []BranchMessage{}
+	} else {
+		messages = messages[start:end]
+	}
+
+	return webCtx.JSON(web.M{
+		"data":               messages,
+		"meta":               meta,
+		"current_message_id": tree.Current,
+	})
+}
+
+// Rename 修改会话标题
+func (ctl *ConversationController) Rename(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.ParseInt(webCtx.PathVar("id"), 10, 64)
+	if err != nil {
+		return webCtx.JSONError("invalid conversation id", http.StatusBadRequest)
+	}
+
+	var req struct {
+		Title string `json:"title"`
+	}
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" || len([]rune(req.Title)) > 70 {
+		return webCtx.JSONError("title is required and must be less than 70 characters", http.StatusBadRequest)
+	}
+
+	if err := ctl.repo.Conversation.RenameConversation(ctx, user.ID, id, req.Title); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError("conversation not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "conversation_id": id}).Errorf("rename conversation failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
+
+// Delete 删除会话以及会话中的所有消息
+func (ctl *ConversationController) Delete(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.ParseInt(webCtx.PathVar("id"), 10, 64)
+	if err != nil {
+		return webCtx.JSONError("invalid conversation id", http.StatusBadRequest)
+	}
+
+	if err := ctl.repo.Conversation.DeleteConversation(ctx, user.ID, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError("conversation not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "conversation_id": id}).Errorf("delete conversation failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
+
+// Search 全文检索当前用户的聊天记录
+func (ctl *ConversationController) Search(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	keyword := strings.TrimSpace(webCtx.Input("keyword"))
+	if keyword == "" || len([]rune(keyword)) > 100 {
+		return webCtx.JSONError("keyword is required and must be less than 100 characters", http.StatusBadRequest)
+	}
+
+	page, perPage := pagination(webCtx, defaultConversationsPerPage)
+
+	results, meta, err := ctl.repo.Conversation.SearchMessages(ctx, user.ID, keyword, page, perPage)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "keyword": keyword}).Errorf("search messages failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	if results == nil {
+		results = []repo.MessageSearchResult{}
+	}
+
+	return webCtx.JSON(web.M{"data": results, "meta": meta})
+}
+
+const (
+	// ExportFormatMarkdown 导出为 Markdown 文档
+	ExportFormatMarkdown = "markdown"
+	// ExportFormatJSON 导出为 JSON 文档
+	ExportFormatJSON = "json"
+)
+
+// ConversationExport 导出的会话
+type ConversationExport struct {
+	Conversation repo.Conversation `json:"conversation"`
+	Messages     []BranchMessage   `json:"messages"`
+	ExportedAt   time.Time         `json:"exported_at"`
+}
+
+// Export 导出会话当前分支中的所有消息，format 为 markdown（默认）或者 json
+func (ctl *ConversationController) Export(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	id, err := strconv.ParseInt(webCtx.PathVar("id"), 10, 64)
+	if err != nil {
+		return webCtx.JSONError("invalid conversation id", http.StatusBadRequest)
+	}
+
+	format := webCtx.InputWithDefault("format", ExportFormatMarkdown)
+	if format != ExportFormatMarkdown && format != ExportFormatJSON {
+		return webCtx.JSONError("format must be markdown or json", http.StatusBadRequest)
+	}
+
+	conv, err := ctl.repo.Conversation.Conversation(ctx, user.ID, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError("conversation not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "conversation_id": id}).Errorf("query conversation failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	tree, err := ctl.repo.Conversation.MessageTree(ctx, user.ID, id)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "conversation_id": id}).Errorf("query conversation messages failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	export := ConversationExport{
+		Conversation: *conv,
+		Messages:     branchMessages(tree),
+		ExportedAt:   time.Now(),
+	}
+
+	return webCtx.Raw(func(w http.ResponseWriter) {
+		var data []byte
+		if format == ExportFormatJSON {
+			data, _ = json.MarshalIndent(export, "", "  ")
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="conversation-%d.json"`, id))
+		} else {
+			data = []byte(export.Markdown())
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="conversation-%d.md"`, id))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	})
+}
+
+// Markdown 将会话转换为 Markdown 文档，失败以及还在生成的回复不导出
+func (export ConversationExport) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", export.Conversation.Title))
+	sb.WriteString(fmt.Sprintf("> %s\n\n", export.Conversation.LastMessageAt.Format(time.DateTime)))
+
+	for _, msg := range export.Messages {
+		if msg.Role == repo.MessageRoleAssistant {
+			if msg.Status != repo.MessageStatusSucceed && msg.Status != repo.MessageStatusStopped {
+				continue
+			}
+
+			sb.WriteString("## Assistant\n\n")
+		} else {
+			sb.WriteString("## User\n\n")
+		}
+
+		content := strings.TrimSpace(msg.Message)
+		for _, part := range msg.MultipartContents {
+			switch {
+			case part.Type == "text" && content == "":
+				content = strings.TrimSpace(part.Text)
+			case part.ImageURL != nil && strings.HasPrefix(part.ImageURL.URL, "data:"):
+				content += "\n\n[image]"
+			case part.ImageURL != nil:
+				content += fmt.Sprintf("\n\n![image](%s)", part.ImageURL.URL)
+			}
+		}
+
+		sb.WriteString(strings.TrimSpace(content))
+		sb.WriteString("\n\n")
+	}
+
+	return sb.String()
+}
@@ -2,3 +2,4 @@ package controllers
 
 const InternalServerError = "internal server error"
 const NotFoundError = "not found"
+const QuotaNotEnoughError = "quota not enough, please recharge and try again"
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/pkg/knowledge"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// KnowledgeController 知识库管理
+type KnowledgeController struct {
+	repo      *repo.Repository     `autowire:"@"`
+	knowledge *knowledge.Knowledge `autowire:"@"`
+}
+
+// NewKnowledgeController 创建知识库控制器
+func NewKnowledgeController(resolver infra.Resolver) web.Controller {
+	ctl := KnowledgeController{}
+	resolver.MustAutoWire(&ctl)
+	return &ctl
+}
+
+func (ctl *KnowledgeController) Register(router web.Router) {
+	router.Group("/knowledge-bases", func(router web.Router) {
+		router.Get("/", ctl.KnowledgeBases)
+		router.Post("/", ctl.CreateKnowledgeBase)
+		router.Delete("/{kb_id}", ctl.DeleteKnowledgeBase)
+		router.Get("/{kb_id}/documents", ctl.Documents)
+		router.Post("/{kb_id}/documents", ctl.CreateDocument)
+		router.Delete("/{kb_id}/documents/{id}", ctl.DeleteDocument)
+	})
+}
+
+const (
+	// maxKnowledgeBasesPerUser 每个用户最多可以创建的知识库数量
+	maxKnowledgeBasesPerUser = 20
+	// maxKnowledgeDocumentSize 单个文档的最大字节数
+	maxKnowledgeDocumentSize = 512 * 1024
+)
+
+// KnowledgeBases 获取当前用户的知识库列表
+func (ctl *KnowledgeController) KnowledgeBases(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	kbs, err := ctl.repo.Knowledge.GetKnowledgeBases(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query knowledge bases failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"data": kbs})
+}
+
+// CreateKnowledgeBase 创建知识库
+func (ctl *KnowledgeController) CreateKnowledgeBase(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	if !ctl.knowledge.Enabled() {
+		return webCtx.JSONError(knowledge.ErrDisabled.Error(), http.StatusNotImplemented)
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > 50 {
+		return webCtx.JSONError("name is required and must be less than 50 characters", http.StatusBadRequest)
+	}
+
+	if len([]rune(req.Description)) > 255 {
+		return webCtx.JSONError("description must be less than 255 characters", http.StatusBadRequest)
+	}
+
+	kbs, err := ctl.repo.Knowledge.GetKnowledgeBases(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query knowledge bases failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	if len(kbs) >= maxKnowledgeBasesPerUser {
+		return webCtx.JSONError("the number of knowledge bases has reached the limit", http.StatusBadRequest)
+	}
+
+	kb, err := ctl.repo.Knowledge.CreateKnowledgeBase(ctx, user.ID, req.Name, req.Description)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("create knowledge base failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(kb)
+}
+
+// DeleteKnowledgeBase 删除知识库以及其中的所有文档
+func (ctl *KnowledgeController) DeleteKnowledgeBase(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	kbID := webCtx.PathVar("kb_id")
+	if err := ctl.knowledge.DeleteKnowledgeBase(ctx, user.ID, kbID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "kb_id": kbID}).Errorf("delete knowledge base failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
+
+// Documents 获取知识库中的文档列表
+func (ctl *KnowledgeController) Documents(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	kbID := webCtx.PathVar("kb_id")
+	if _, err := ctl.repo.Knowledge.GetKnowledgeBase(ctx, user.ID, kbID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "kb_id": kbID}).Errorf("query knowledge base failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	docs, err := ctl.repo.Knowledge.GetDocuments(ctx, user.ID, kbID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "kb_id": kbID}).Errorf("query knowledge documents failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"data": docs})
+}
+
+// readDocument 读取上传的文档，支持 multipart 上传 .txt/.md 文件（字段名 file），或者 JSON 格式的 title/content
+func readDocument(webCtx web.Context) (title string, contentType string, content string, err error) {
+	if strings.HasPrefix(webCtx.ContentType(), "multipart/form-data") {
+		file, err := webCtx.File("file")
+		if err != nil {
+			return "", "", "", errors.New("file is required")
+		}
+		defer file.Delete()
+
+		switch strings.ToLower(filepath.Ext(file.Name())) {
+		case ".txt":
+			contentType = "text"
+		case ".md", ".markdown":
+			contentType = "markdown"
+		default:
+			return "", "", "", errors.New("only .txt and .md files are supported")
+		}
+
+		if file.Size() > maxKnowledgeDocumentSize {
+			return "", "", "", errors.New("the document is too large")
+		}
+
+		data, err := os.ReadFile(file.GetTempFilename())
+		if err != nil {
+			return "", "", "", err
+		}
+
+		title = strings.TrimSpace(webCtx.Input("title"))
+		if title == "" {
+			title = strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		}
+
+		content = string(data)
+	} else {
+		var req struct {
+			Title       string `json:"title"`
+			ContentType string `json:"content_type,omitempty"`
+			Content     string `json:"content"`
+		}
+		if err := webCtx.Unmarshal(&req); err != nil {
+			return "", "", "", errors.New("invalid request")
+		}
+
+		if len(req.Content) > maxKnowledgeDocumentSize {
+			return "", "", "", errors.New("the document is too large")
+		}
+
+		if req.ContentType == "" {
+			req.ContentType = "text"
+		}
+
+		if req.ContentType != "text" && req.ContentType != "markdown" {
+			return "", "", "", errors.New("invalid content type")
+		}
+
+		title, contentType, content = strings.TrimSpace(req.Title), req.ContentType, req.Content
+	}
+
+	if title == "" || len([]rune(title)) > 255 {
+		return "", "", "", errors.New("title is required and must be less than 255 characters")
+	}
+
+	if !utf8.ValidString(content) {
+		return "", "", "", errors.New("the document must be utf-8 encoded")
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return "", "", "", knowledge.ErrEmptyDocument
+	}
+
+	return title, contentType, content, nil
+}
+
+// CreateDocument 上传文档到知识库
+func (ctl *KnowledgeController) CreateDocument(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	if !ctl.knowledge.Enabled() {
+		return webCtx.JSONError(knowledge.ErrDisabled.Error(), http.StatusNotImplemented)
+	}
+
+	kbID := webCtx.PathVar("kb_id")
+	if _, err := ctl.repo.Knowledge.GetKnowledgeBase(ctx, user.ID, kbID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "kb_id": kbID}).Errorf("query knowledge base failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	title, contentType, content, err := readDocument(webCtx)
+	if err != nil {
+		return webCtx.JSONError(err.Error(), http.StatusBadRequest)
+	}
+
+	doc, err := ctl.knowledge.AddDocument(ctx, user.ID, kbID, title, contentType, content)
+	if err != nil {
+		if errors.Is(err, knowledge.ErrChunkLimitExceeded) || errors.Is(err, knowledge.ErrEmptyDocument) {
+			return webCtx.JSONError(err.Error(), http.StatusBadRequest)
+		}
+
+		log.F(log.M{"user_id": user.ID, "kb_id": kbID, "title": title}).Errorf("add knowledge document failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(doc)
+}
+
+// DeleteDocument 删除知识库中的文档
+func (ctl *KnowledgeController) DeleteDocument(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	kbID := webCtx.PathVar("kb_id")
+	id, err := strconv.ParseInt(webCtx.PathVar("id"), 10, 64)
+	if err != nil {
+		return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+	}
+
+	if err := ctl.knowledge.DeleteDocument(ctx, user.ID, kbID, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "kb_id": kbID, "id": id}).Errorf("delete knowledge document failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/internal/consumer/tasks"
+	"github.com/mylxsw/aidea-chat-server/internal/queue"
+	"github.com/mylxsw/aidea-chat-server/pkg/payment"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+	"net/http"
+	"strings"
+)
+
+// PaymentController 智慧果购买
+type PaymentController struct {
+	conf  *config.Config   `autowire:"@"`
+	repo  *repo.Repository `autowire:"@"`
+	queue *queue.Queue     `autowire:"@"`
+	apple *payment.Apple   `autowire:"@"`
+}
+
+// NewPaymentController 创建支付控制器
+func NewPaymentController(resolver infra.Resolver) web.Controller {
+	ctl := PaymentController{}
+	resolver.MustAutoWire(&ctl)
+	return &ctl
+}
+
+func (ctl *PaymentController) Register(router web.Router) {
+	router.Group("/payment", func(router web.Router) {
+		router.Get("/products", ctl.Products)
+		router.Get("/orders", ctl.Orders)
+		router.Post("/apple", ctl.CreateAppleOrder)
+		router.Post("/apple/verify", ctl.VerifyAppleTransaction)
+	})
+}
+
+// Products 可购买的产品列表
+func (ctl *PaymentController) Products(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	products := ctl.conf.Payment.Products
+	if products == nil {
+		products = []config.Product{}
+	}
+
+	return webCtx.JSON(web.M{"data": products})
+}
+
+// Orders 分页查询已支付的订单
+func (ctl *PaymentController) Orders(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	page, perPage := pagination(webCtx, defaultConversationsPerPage)
+
+	orders, meta, err := ctl.repo.Payment.Orders(ctx, user.ID, page, perPage)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query payment orders failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"data": orders, "meta": meta})
+}
+
+// CreateAppleOrder 创建 Apple 应用内购买订单
+//
+// 客户端发起购买时，使用返回的订单号作为 appAccountToken，购买完成后调用 VerifyAppleTransaction 校验交易
+func (ctl *PaymentController) CreateAppleOrder(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	if !ctl.apple.Enabled() {
+		return webCtx.JSONError("apple in-app purchase is not supported", http.StatusNotImplemented)
+	}
+
+	var req struct {
+		ProductID string `json:"product_id"`
+	}
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	product, ok := ctl.conf.Payment.Product(req.ProductID)
+	if !ok {
+		return webCtx.JSONError("product not found", http.StatusNotFound)
+	}
+
+	order, err := ctl.repo.Payment.CreateOrder(ctx, user.ID, product, repo.PaymentSourceApple)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "product_id": product.ID}).Errorf("create payment order failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{"order": order, "product": product})
+}
+
+// VerifyAppleTransactionRequest 校验 Apple 应用内购买交易的请求
+type VerifyAppleTransactionRequest struct {
+	TransactionID string `json:"transaction_id"`
+}
+
+// VerifyAppleTransaction 通过 App Store Server API 校验交易，校验通过后为用户增加智慧果
+//
+// 同一个交易只处理一次：当前用户重复提交时返回已经处理的订单，客户端可以正常结束交易；其它用户提交时拒绝
+func (ctl *PaymentController) VerifyAppleTransaction(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	var req VerifyAppleTransactionRequest
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return webCtx.JSONError("invalid request", http.StatusBadRequest)
+	}
+
+	req.TransactionID = strings.TrimSpace(req.TransactionID)
+	if req.TransactionID == "" {
+		return webCtx.JSONError("transaction_id is required", http.StatusBadRequest)
+	}
+
+	if resp, ok := ctl.processedTransaction(ctx, webCtx, user, req.TransactionID); ok {
+		return resp
+	}
+
+	trans, err := ctl.apple.Transaction(ctx, req.TransactionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, payment.ErrAppleNotConfigured):
+			return webCtx.JSONError("apple in-app purchase is not supported", http.StatusNotImplemented)
+		case errors.Is(err, payment.ErrTransactionNotFound):
+			return webCtx.JSONError("transaction not found", http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "transaction_id": req.TransactionID}).Errorf("query apple transaction failed: %s", err)
+		return webCtx.JSONError("verify transaction failed, please try again later", http.StatusBadGateway)
+	}
+
+	if trans.Revoked() {
+		return webCtx.JSONError("the transaction has been refunded or revoked", http.StatusBadRequest)
+	}
+
+	if !trans.Consumable() {
+		log.F(log.M{"user_id": user.ID, "transaction": trans}).Warningf("unexpected apple transaction type")
+		return webCtx.JSONError("unsupported transaction type", http.StatusBadRequest)
+	}
+
+	product, ok := ctl.conf.Payment.Product(trans.ProductID)
+	if !ok {
+		log.F(log.M{"user_id": user.ID, "transaction": trans}).Errorf("the product of apple transaction is not configured")
+		return webCtx.JSONError("product not found", http.StatusNotFound)
+	}
+
+	// 客户端购买时设置的 appAccountToken 为订单号，交易只能用于当前用户的订单
+	payload, _ := json.Marshal(trans)
+	order, eventID, err := ctl.repo.Payment.CompletePayment(ctx, user.ID, product, repo.PaymentTransaction{
+		Source:                repo.PaymentSourceApple,
+		TransactionID:         trans.TransactionID,
+		OriginalTransactionID: trans.OriginalTransactionID,
+		ProductID:             trans.ProductID,
+		Quantity:              trans.Quantity,
+		Environment:           trans.Environment,
+		PurchasedAt:           trans.PurchasedAt(),
+		AccountToken:          strings.ToLower(trans.AppAccountToken),
+		Payload:               string(payload),
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrPaymentOrderNotMatched) {
+			log.F(log.M{"user_id": user.ID, "transaction_id": trans.TransactionID, "app_account_token": trans.AppAccountToken}).Warningf("the apple transaction does not belong to the user")
+			return webCtx.JSONError("the transaction does not belong to the current user", http.StatusForbidden)
+		}
+
+		if errors.Is(err, repo.ErrPaymentDuplicated) {
+			if resp, ok := ctl.processedTransaction(ctx, webCtx, user, trans.TransactionID); ok {
+				return resp
+			}
+		}
+
+		log.F(log.M{"user_id": user.ID, "transaction_id": trans.TransactionID}).Errorf("complete payment failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	// 加入队列失败时，由定时任务重新加入队列
+	if err := tasks.EnqueuePaymentCompleted(ctx, ctl.queue, eventID); err != nil {
+		log.F(log.M{"user_id": user.ID, "order_no": order.OrderNo, "event_id": eventID}).Errorf("failed to enqueue payment completed task: %s", err)
+	}
+
+	return webCtx.JSON(web.M{"order": order, "duplicated": false})
+}
+
+// processedTransaction 交易已经处理过时，返回已经处理的订单或者拒绝请求
+func (ctl *PaymentController) processedTransaction(ctx context.Context, webCtx web.Context, user *auth.User, transactionID string) (web.Response, bool) {
+	rec, err := ctl.repo.Payment.GetPaymentByTransaction(ctx, repo.PaymentSourceApple, transactionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, false
+		}
+
+		log.F(log.M{"user_id": user.ID, "transaction_id": transactionID}).Errorf("query payment record failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError), true
+	}
+
+	if rec.UserID != user.ID {
+		log.F(log.M{"user_id": user.ID, "owner": rec.UserID, "transaction_id": transactionID}).Warningf("the transaction has been used by another user")
+		return webCtx.JSONError("the transaction has already been processed", http.StatusConflict), true
+	}
+
+	order, err := ctl.repo.Payment.GetOrder(ctx, user.ID, rec.OrderNo)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "order_no": rec.OrderNo}).Errorf("query payment order failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError), true
+	}
+
+	ctl.requeuePaymentCompleted(ctx, *order)
+
+	return webCtx.JSON(web.M{"order": order, "duplicated": true}), true
+}
+
+// requeuePaymentCompleted 订单的 payment_completed 事件还没有处理时（例如之前加入队列失败），重新加入队列
+func (ctl *PaymentController) requeuePaymentCompleted(ctx context.Context, order repo.PaymentOrder) {
+	event, err := ctl.repo.Payment.GetPaymentCompletedEvent(ctx, order)
+	if err != nil {
+		if !errors.Is(err, repo.ErrNotFound) {
+			log.F(log.M{"user_id": order.UserID, "order_no": order.OrderNo}).Errorf("query payment completed event failed: %s", err)
+		}
+
+		return
+	}
+
+	if event.Status != repo.EventStatusWaiting {
+		return
+	}
+
+	if err := tasks.EnqueuePaymentCompleted(ctx, ctl.queue, event.Id); err != nil {
+		log.F(log.M{"user_id": order.UserID, "order_no": order.OrderNo, "event_id": event.Id}).Errorf("failed to enqueue payment completed task: %s", err)
+	}
+}
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/api/auth"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/aidea-chat-server/pkg/tools"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+	"github.com/mylxsw/glacier/web"
+	"github.com/mylxsw/go-utils/array"
+	"net/http"
+	"strings"
+)
+
+// RobotController 机器人（助手）管理
+type RobotController struct {
+	conf  *config.Config   `autowire:"@"`
+	repo  *repo.Repository `autowire:"@"`
+	tools *tools.Registry  `autowire:"@"`
+}
+
+// NewRobotController 创建机器人控制器
+func NewRobotController(resolver infra.Resolver) web.Controller {
+	ctl := RobotController{}
+	resolver.MustAutoWire(&ctl)
+	return &ctl
+}
+
+func (ctl *RobotController) Register(router web.Router) {
+	router.Group("/robots", func(router web.Router) {
+		router.Get("/", ctl.Robots)
+		router.Post("/", ctl.CreateRobot)
+		router.Get("/{robot_id}", ctl.Robot)
+		router.Put("/{robot_id}", ctl.UpdateRobot)
+		router.Delete("/{robot_id}", ctl.DeleteRobot)
+	})
+}
+
+// RobotResponse 返回给客户端的机器人信息
+type RobotResponse struct {
+	repo.Robot
+	// Owned whether the robot is created by the current user
+	Owned bool `json:"owned"`
+}
+
+// buildRobotResponse 构建返回给客户端的机器人信息，隐藏不应该暴露给用户的字段
+func buildRobotResponse(robot repo.Robot, userID int64) RobotResponse {
+	// server token is never returned to the client
+	robot.ServerToken = ""
+
+	owned := !robot.IsBuiltin() && robot.UserID == userID
+	if !owned {
+		robot.ServerURL = ""
+		if !robot.RobotMeta.ShowPrompt {
+			robot.Prompt = ""
+		}
+	}
+
+	return RobotResponse{Robot: robot, Owned: owned}
+}
+
+// Robots 获取当前用户可用的机器人列表
+func (ctl *RobotController) Robots(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	robots, err := ctl.repo.Robot.GetUserRobots(ctx, user.ID)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID}).Errorf("query user robots failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{
+		"data": array.Map(robots, func(item repo.Robot, _ int) RobotResponse {
+			return buildRobotResponse(item, user.ID)
+		}),
+	})
+}
+
+// Robot 获取机器人详情
+func (ctl *RobotController) Robot(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	robot, err := ctl.repo.Robot.GetUserRobot(ctx, user.ID, webCtx.PathVar("robot_id"))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "robot_id": webCtx.PathVar("robot_id")}).Errorf("query robot failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(buildRobotResponse(*robot, user.ID))
+}
+
+// RobotRequest 创建、更新机器人请求
+type RobotRequest struct {
+	Name           string              `json:"name"`
+	Type           repo.RobotType      `json:"type"`
+	Description    string              `json:"description,omitempty"`
+	Privilege      repo.RobotPrivilege `json:"privilege,omitempty"`
+	Model          string              `json:"model,omitempty"`
+	Prompt         string              `json:"prompt,omitempty"`
+	WelcomeMessage string              `json:"welcome_message,omitempty"`
+	ServerURL      string              `json:"server_url,omitempty"`
+	ServerToken    string              `json:"server_token,omitempty"`
+	RobotMeta      repo.RobotMeta      `json:"robot_meta,omitempty"`
+}
+
+// parseRobotRequest 解析并校验机器人请求参数
+func (ctl *RobotController) parseRobotRequest(ctx context.Context, webCtx web.Context) (*repo.Robot, error) {
+	var req RobotRequest
+	if err := webCtx.Unmarshal(&req); err != nil {
+		return nil, errors.New("invalid request")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > 50 {
+		return nil, errors.New("name is required and must be less than 50 characters")
+	}
+
+	if req.Type == 0 {
+		req.Type = repo.RobotTypeModelDriven
+	}
+
+	if req.Privilege == 0 {
+		req.Privilege = repo.RobotPrivilegePrivate
+	}
+
+	if req.Privilege != repo.RobotPrivilegePrivate && req.Privilege != repo.RobotPrivilegePublic {
+		return nil, errors.New("invalid privilege")
+	}
+
+	// 自定义服务器的机器人同样需要指定模型，用于 Token 计算以及计费
+	if !array.In(req.Model, array.Map(ctl.conf.Models, func(item config.Model, _ int) string { return item.ID })) {
+		return nil, errors.New("invalid model")
+	}
+
+	req.RobotMeta.Tools = array.Uniq(req.RobotMeta.Tools)
+	for _, name := range req.RobotMeta.Tools {
+		if _, ok := ctl.tools.Get(name); !ok {
+			return nil, errors.New("invalid tool: " + name)
+		}
+	}
+
+	req.RobotMeta.KnowledgeBases = array.Uniq(req.RobotMeta.KnowledgeBases)
+
+	if req.RobotMeta.Context != nil {
+		if req.RobotMeta.Context.Strategy != "" && !array.In(req.RobotMeta.Context.Strategy, config.ContextStrategies) {
+			return nil, errors.New("invalid context strategy")
+		}
+
+		if req.RobotMeta.Context.MaxTurns < 0 {
+			return nil, errors.New("invalid context max turns")
+		}
+	}
+
+	if req.Type != repo.RobotTypeModelDriven && req.Type != repo.RobotTypeCustomServer {
+		return nil, errors.New("invalid robot type")
+	}
+
+	if req.Type == repo.RobotTypeCustomServer {
+		// 自定义服务器的机器人会由服务端发起请求，默认只允许管理员在数据库中创建
+		if !ctl.conf.EnableUserCustomServerRobot {
+			return nil, errors.New("custom server robot is not allowed")
+		}
+
+		// 只允许访问公网地址，避免通过机器人访问内网服务
+		if err := misc.CheckPublicURL(ctx, req.ServerURL); err != nil {
+			log.F(log.M{"server_url": req.ServerURL}).Warningf("invalid custom server url: %s", err)
+			return nil, errors.New("invalid server url")
+		}
+	}
+
+	return &repo.Robot{
+		Name:           req.Name,
+		Type:           req.Type,
+		Description:    req.Description,
+		Privilege:      req.Privilege,
+		Model:          req.Model,
+		Prompt:         req.Prompt,
+		WelcomeMessage: req.WelcomeMessage,
+		ServerURL:      req.ServerURL,
+		ServerToken:    req.ServerToken,
+		RobotMeta:      req.RobotMeta,
+	}, nil
+}
+
+// checkKnowledgeBases 机器人只能关联自己创建的知识库
+func (ctl *RobotController) checkKnowledgeBases(ctx context.Context, webCtx web.Context, userID int64, kbIDs []string) web.Response {
+	owned, err := ctl.repo.Knowledge.KnowledgeBasesOwnedBy(ctx, userID, kbIDs)
+	if err != nil {
+		log.F(log.M{"user_id": userID, "knowledge_bases": kbIDs}).Errorf("query knowledge bases failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	if !owned {
+		return webCtx.JSONError("invalid knowledge bases", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// CreateRobot 创建机器人
+func (ctl *RobotController) CreateRobot(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	robot, err := ctl.parseRobotRequest(ctx, webCtx)
+	if err != nil {
+		return webCtx.JSONError(err.Error(), http.StatusBadRequest)
+	}
+
+	if resp := ctl.checkKnowledgeBases(ctx, webCtx, user.ID, robot.RobotMeta.KnowledgeBases); resp != nil {
+		return resp
+	}
+
+	created, err := ctl.repo.Robot.CreateRobot(ctx, user.ID, *robot)
+	if err != nil {
+		log.F(log.M{"user_id": user.ID, "robot": robot.Name}).Errorf("create robot failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(buildRobotResponse(*created, user.ID))
+}
+
+// UpdateRobot 更新机器人
+func (ctl *RobotController) UpdateRobot(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	robotID := webCtx.PathVar("robot_id")
+	existing, err := ctl.repo.Robot.GetRobotByID(ctx, robotID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "robot_id": robotID}).Errorf("query robot failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	// 内置机器人以及其他用户的机器人不允许修改
+	if existing.IsBuiltin() || existing.UserID != user.ID {
+		return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+	}
+
+	robot, err := ctl.parseRobotRequest(ctx, webCtx)
+	if err != nil {
+		return webCtx.JSONError(err.Error(), http.StatusBadRequest)
+	}
+
+	if resp := ctl.checkKnowledgeBases(ctx, webCtx, user.ID, robot.RobotMeta.KnowledgeBases); resp != nil {
+		return resp
+	}
+
+	// server token 不会返回给客户端，未提交时保持不变
+	if robot.ServerToken == "" {
+		robot.ServerToken = existing.ServerToken
+	}
+
+	if err := ctl.repo.Robot.UpdateRobot(ctx, user.ID, robotID, *robot); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "robot_id": robotID}).Errorf("update robot failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	robot.RobotID = robotID
+	robot.UserID = user.ID
+
+	return webCtx.JSON(buildRobotResponse(*robot, user.ID))
+}
+
+// DeleteRobot 删除机器人
+func (ctl *RobotController) DeleteRobot(ctx context.Context, webCtx web.Context, user *auth.User) web.Response {
+	robotID := webCtx.PathVar("robot_id")
+	if err := ctl.repo.Robot.DeleteRobot(ctx, user.ID, robotID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return webCtx.JSONError(NotFoundError, http.StatusNotFound)
+		}
+
+		log.F(log.M{"user_id": user.ID, "robot_id": robotID}).Errorf("delete robot failed: %s", err)
+		return webCtx.JSONError(InternalServerError, http.StatusInternalServerError)
+	}
+
+	return webCtx.JSON(web.M{})
+}
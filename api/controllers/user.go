@@ -159,6 +159,12 @@ func (ctl *UserController) Destroy(ctx context.Context, webCtx web.Context, user
 		return webCtx.JSONError("验证码错误", http.StatusBadRequest)
 	}
 
+	// 先删除用户的聊天记录，删除失败时账号不销毁，验证码保留，用户可以重试
+	if err := ctl.repo.Conversation.DeleteUserConversations(ctx, user.ID); err != nil {
+		log.With(user).Errorf("failed to delete user conversations: %s", err)
+		return webCtx.JSONError("内部错误，请稍后再试", http.StatusInternalServerError)
+	}
+
 	_ = ctl.rds.Del(ctx, fmt.Sprintf("auth:verify-code:%s:%s", verifyCodeId, user.Phone)).Err()
 
 	if err := ctl.repo.User.UpdateStatus(ctx, user.ID, repo.UserStatusDeleted); err != nil {
@@ -74,7 +74,7 @@ func buildRouter(resolver infra.Resolver, router web.Router, mw web.RequestMiddl
 	)
 
 	// 添加 web 中间件
-	resolver.MustResolve(func(tk *jwt.Token, userSrv *service.UserService, limiter *redis_rate.Limiter) {
+	resolver.MustResolve(func(tk *jwt.Token, userSrv *service.UserService, rp *repo.Repository, limiter *redis_rate.Limiter) {
 		mws = append(mws, func(handler web.WebHandler) web.WebHandler {
 			return func(ctx web.Context) web.Response {
 				ctx.Response().Header("aidea-global-alert-id", "20231204")
@@ -142,17 +142,30 @@ func buildRouter(resolver infra.Resolver, router web.Router, mw web.RequestMiddl
 					urlPath := webCtx.Request().Raw().URL.Path
 					needAuth := str.HasPrefixes(urlPath, needAuthPrefix)
 
-					claims, err := tk.ParseToken(credential)
-					if needAuth && err != nil {
-						return errors.New("invalid auth credential")
-					}
-
 					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 					defer cancel()
 
+					var userID int64
+					if repo.IsAPIKey(credential) {
+						// 用户 API Key 鉴权
+						id, err := authWithAPIKey(ctx, rp, urlPath, credential)
+						if needAuth && err != nil {
+							return err
+						}
+
+						userID = id
+					} else {
+						claims, err := tk.ParseToken(credential)
+						if needAuth && err != nil {
+							return errors.New("invalid auth credential")
+						}
+
+						userID = claims.Int64Value("id")
+					}
+
 					// 查询用户信息
 					var user *auth.User
-					if u, err := userSrv.GetUserByID(ctx, claims.Int64Value("id"), false); err != nil {
+					if u, err := userSrv.GetUserByID(ctx, userID, false); err != nil {
 						if needAuth {
 							if errors.Is(err, repo.ErrNotFound) {
 								return errors.New("invalid auth credential, user not found")
@@ -257,6 +270,56 @@ func readFromWebContext(webCtx web.Context, key string) string {
 	return webCtx.Header("X-" + strings.ToUpper(key))
 }
 
+// apiKeyScopes API Key 可以访问的 URL 前缀以及需要的访问范围，按顺序匹配
+var apiKeyScopes = []struct {
+	Prefix string
+	Scope  string
+}{
+	// API Key 管理、账号销毁以及修改密码只允许使用登录凭证访问
+	{Prefix: "/v1/users/api-keys", Scope: ""},
+	{Prefix: "/v1/users/destroy", Scope: ""},
+	{Prefix: "/v1/users/reset-password", Scope: ""},
+	{Prefix: "/v1/users", Scope: repo.APIKeyScopeUsers},
+	{Prefix: "/v1/chat", Scope: repo.APIKeyScopeChat},
+	{Prefix: "/v1/conversations", Scope: repo.APIKeyScopeChat},
+	{Prefix: "/v1/robots", Scope: repo.APIKeyScopeRobots},
+	{Prefix: "/v1/knowledge-bases", Scope: repo.APIKeyScopeRobots},
+}
+
+// authWithAPIKey 使用用户 API Key 鉴权，返回 API Key 所属的用户 ID
+func authWithAPIKey(ctx context.Context, rp *repo.Repository, urlPath string, credential string) (int64, error) {
+	key, err := rp.APIKey.GetAPIKeyByKey(ctx, credential)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return 0, errors.New("invalid api key")
+		}
+
+		return 0, err
+	}
+
+	if key.Expired() {
+		return 0, errors.New("api key has expired")
+	}
+
+	var scope string
+	for _, item := range apiKeyScopes {
+		if strings.HasPrefix(urlPath, item.Prefix) {
+			scope = item.Scope
+			break
+		}
+	}
+
+	if scope == "" || !key.HasScope(scope) {
+		return 0, errors.New("api key does not have permission to access this resource")
+	}
+
+	if err := rp.APIKey.TouchAPIKey(ctx, key.ID); err != nil {
+		log.F(log.M{"key_id": key.ID}).Errorf("update api key last used time failed: %s", err)
+	}
+
+	return key.UserID, nil
+}
+
 func authHandler(cb func(ctx web.Context, credential string) error, skip func(ctx web.Context) bool) web.HandlerDecorator {
 	return func(handler web.WebHandler) web.WebHandler {
 		return func(ctx web.Context) (resp web.Response) {
@@ -11,8 +11,13 @@ import (
 
 // 需要鉴权的 URLs
 var needAuthPrefix = []string{
-	"/v1/users", // 用户管理
-	"/v1/tasks", // 任务管理
+	"/v1/users",            // 用户管理
+	"/v1/tasks",            // 任务管理
+	"/v1/robots",           // 机器人管理
+	"/v1/chat/completions", // OpenAI 兼容的聊天接口
+	"/v1/knowledge-bases",  // 知识库管理
+	"/v1/conversations",    // 会话历史
+	"/v1/payment",          // 智慧果购买
 
 	"/v1/auth/bind-phone",  // 绑定手机号码
 	"/v1/auth/bind-wechat", // 绑定微信
@@ -27,6 +32,11 @@ func routes(resolver infra.Resolver, r *web.MiddlewareRouter) {
 		controllers.NewTaskController(resolver),
 		controllers.NewUserController(resolver),
 		controllers.NewChatController(resolver),
+		controllers.NewRobotController(resolver),
+		controllers.NewAPIKeyController(resolver),
+		controllers.NewKnowledgeController(resolver),
+		controllers.NewConversationController(resolver),
+		controllers.NewPaymentController(resolver),
 	)
 }
 
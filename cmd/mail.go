@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/mail"
+	"github.com/urfave/cli/v2"
+	"os"
+	"strings"
+)
+
+// mailPreviewCommand 使用示例数据渲染邮件模板，用于运营人员修改模板后预览效果
+func mailPreviewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "mail-preview",
+		Usage: "render a mail template with sample data",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "template", Usage: fmt.Sprintf("the template to render: %s", strings.Join(mail.Templates, ", "))},
+			&cli.StringFlag{Name: "lang", Value: mail.DefaultLanguage, Usage: "the language of the template, e.g. zh, en"},
+			&cli.StringFlag{Name: "format", Value: "html", Usage: "output format: html or text"},
+			&cli.StringFlag{Name: "output", Usage: "write the result to the file instead of standard output"},
+		},
+		Action: func(c *cli.Context) error {
+			conf, err := config.Load(c.String("conf"))
+			if err != nil {
+				return err
+			}
+
+			name := c.String("template")
+			if name == "" {
+				return fmt.Errorf("--template is required, available templates: %s", strings.Join(mail.Templates, ", "))
+			}
+
+			msg, err := mail.NewRenderer(conf.Mail).Render(c.String("lang"), name, mail.SampleData(name))
+			if err != nil {
+				return err
+			}
+
+			var content string
+			switch c.String("format") {
+			case "html":
+				content = msg.HTML
+			case "text":
+				content = fmt.Sprintf("Subject: %s\n\n%s\n", msg.Subject, msg.Text)
+			default:
+				return fmt.Errorf("unsupported format: %s", c.String("format"))
+			}
+
+			if output := c.String("output"); output != "" {
+				return os.WriteFile(output, []byte(content), 0644)
+			}
+
+			_, err = fmt.Fprint(c.App.Writer, content)
+			return err
+		},
+	}
+}
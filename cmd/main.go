@@ -11,12 +11,16 @@ import (
 	"github.com/mylxsw/aidea-chat-server/migrate"
 	"github.com/mylxsw/aidea-chat-server/pkg/chat"
 	"github.com/mylxsw/aidea-chat-server/pkg/jwt"
+	"github.com/mylxsw/aidea-chat-server/pkg/knowledge"
 	"github.com/mylxsw/aidea-chat-server/pkg/mail"
+	"github.com/mylxsw/aidea-chat-server/pkg/payment"
 	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
 	"github.com/mylxsw/aidea-chat-server/pkg/rate"
 	"github.com/mylxsw/aidea-chat-server/pkg/redis"
 	"github.com/mylxsw/aidea-chat-server/pkg/repo"
 	"github.com/mylxsw/aidea-chat-server/pkg/service"
+	"github.com/mylxsw/aidea-chat-server/pkg/sms"
+	"github.com/mylxsw/aidea-chat-server/pkg/tools"
 	"github.com/mylxsw/aidea-chat-server/pkg/wechat"
 	"github.com/mylxsw/asteria/formatter"
 	"github.com/mylxsw/asteria/level"
@@ -24,6 +28,7 @@ import (
 	"github.com/mylxsw/asteria/writer"
 	"github.com/mylxsw/glacier/infra"
 	"github.com/mylxsw/glacier/starter/app"
+	"github.com/urfave/cli/v2"
 	"path/filepath"
 	"time"
 )
@@ -42,6 +47,10 @@ func main() {
 	// load configurations
 	config.Register(ins)
 
+	ins.WithCLIOptions(func(cliApp *cli.App) {
+		cliApp.Commands = append(cliApp.Commands, mailPreviewCommand())
+	})
+
 	// log configuration
 	ins.Init(func(f infra.FlagContext) error {
 		if !f.Bool("log-color") {
@@ -82,10 +91,14 @@ func main() {
 		jwt.Provider{},
 		wechat.Provider{},
 		mail.Provider{},
+		payment.Provider{},
+		sms.Provider{},
 		queue.Provider{},
 		consumer.Provider{},
 		proxy.Provider{},
 		chat.Provider{},
+		tools.Provider{},
+		knowledge.Provider{},
 	)
 
 	app.MustRun(ins)
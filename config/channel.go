@@ -0,0 +1,59 @@
+package config
+
+import "github.com/mylxsw/go-utils/array"
+
+const (
+	// ChannelTypeOpenAI OpenAI compatible API, including Azure OpenAI
+	ChannelTypeOpenAI = "openai"
+	// ChannelTypeAnthropic Anthropic Messages API
+	ChannelTypeAnthropic = "anthropic"
+	// ChannelTypeGemini Google Gemini API
+	ChannelTypeGemini = "gemini"
+)
+
+// DefaultChannel the name of the channel created from the legacy `openai` configuration
+const DefaultChannel = "openai"
+
+// Channel 模型服务渠道，每个渠道对应一个上游服务提供商
+type Channel struct {
+	// Name channel name, referenced by Model.Channel
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Type channel type: openai/anthropic/gemini, default is openai
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	ServerURL    string `json:"server_url,omitempty" yaml:"server_url,omitempty"`
+	APIKey       string `json:"-" yaml:"api_key,omitempty"`
+	Organization string `json:"organization,omitempty" yaml:"organization,omitempty"`
+
+	// UseAzure whether the channel is Azure OpenAI, only valid for openai type
+	UseAzure        bool   `json:"use_azure,omitempty" yaml:"use_azure,omitempty"`
+	AzureAPIVersion string `json:"azure_api_version,omitempty" yaml:"azure_api_version,omitempty"`
+	// AzureModelMapping azure model mapping
+	// Key: OpenAI model name, Value: Azure model name
+	AzureModelMapping map[string]string `json:"azure_model_mapping,omitempty" yaml:"azure_model_mapping,omitempty"`
+	// DisableStreamUsage do not request the token usage in the streaming response, only valid for openai type
+	DisableStreamUsage bool `json:"disable_stream_usage,omitempty" yaml:"disable_stream_usage,omitempty"`
+
+	// Models the list of model IDs served by this channel
+	// models that do not specify a channel will use the first channel that serves it
+	Models []string `json:"models,omitempty" yaml:"models,omitempty"`
+}
+
+// OpenAIConfig convert the channel to OpenAI compatible configuration
+func (ch Channel) OpenAIConfig() OpenAIConfig {
+	return OpenAIConfig{
+		ServerURL:         ch.ServerURL,
+		APIKey:            ch.APIKey,
+		Organization:      ch.Organization,
+		UseAzure:          ch.UseAzure,
+		AzureAPIVersion:   ch.AzureAPIVersion,
+		AzureModelMapping: ch.AzureModelMapping,
+
+		DisableStreamUsage: ch.DisableStreamUsage,
+	}
+}
+
+// Serve whether the channel serves the specified model
+func (ch Channel) Serve(modelID string) bool {
+	return array.In(modelID, ch.Models)
+}
@@ -39,20 +39,38 @@ type Config struct {
 
 	// Mail Email configuration
 	Mail Mail `json:"mail,omitempty" yaml:"mail,omitempty"`
+	// SMS configuration
+	SMS SMS `json:"sms,omitempty" yaml:"sms,omitempty"`
 
 	// WeChat configuration
 	WeChat WeChat `json:"wechat,omitempty" yaml:"wechat,omitempty"`
 	// Apple signIn configuration
 	Apple AppleSignIn `json:"apple,omitempty" yaml:"apple,omitempty"`
+	// Payment products and payment channels configuration
+	Payment Payment `json:"payment,omitempty" yaml:"payment,omitempty"`
 
 	// EnableAnonymousChat whether to enable anonymous chat
 	EnableAnonymousChat bool `json:"enable_anonymous_chat,omitempty" yaml:"enable_anonymous_chat,omitempty"`
+	// EnableUserCustomServerRobot whether users are allowed to create robots that proxy chat to their own server
+	EnableUserCustomServerRobot bool `json:"enable_user_custom_server_robot,omitempty" yaml:"enable_user_custom_server_robot,omitempty"`
+	// ToolPrices the price of each call to the built-in tools (tool name => coins), tools not listed are free
+	ToolPrices map[string]int64 `json:"tool_prices,omitempty" yaml:"tool_prices,omitempty"`
 
 	// OpenAI compatible configuration
 	OpenAI OpenAIConfig `json:"openai,omitempty" yaml:"openai,omitempty"`
 
+	// Channels upstream model service channels, the legacy `openai` configuration is used as the default channel
+	Channels []Channel `json:"channels,omitempty" yaml:"channels,omitempty"`
+
 	// Models supported model list
 	Models []Model `json:"models,omitempty" yaml:"models,omitempty"`
+
+	// Knowledge knowledge base configuration
+	Knowledge Knowledge `json:"knowledge,omitempty" yaml:"knowledge,omitempty"`
+	// ContextSummary the configuration of the summarize context reduction strategy
+	ContextSummary ContextSummary `json:"context_summary,omitempty" yaml:"context_summary,omitempty"`
+	// ConversationTitle the configuration of the conversation title generation
+	ConversationTitle ConversationTitle `json:"conversation_title,omitempty" yaml:"conversation_title,omitempty"`
 }
 
 // WeChat configuration
@@ -83,6 +101,17 @@ func (conf *Config) Init() {
 	conf.QueueWorkers = misc.IntDefault(conf.QueueWorkers, 10)
 	conf.EnableScheduler = misc.BoolDefault(conf.EnableScheduler, true)
 
+	if conf.ToolPrices == nil {
+		conf.ToolPrices = map[string]int64{"web_fetch": 1}
+	}
+
+	conf.Mail.init()
+	conf.SMS.init()
+	conf.Payment.init()
+	conf.Knowledge.init()
+	conf.ContextSummary.init()
+	conf.ConversationTitle.init()
+
 	conf.OpenAI.AzureAPIVersion = misc.StringDefault(conf.OpenAI.AzureAPIVersion, "2023-05-15")
 	conf.OpenAI.ServerURL = strings.TrimSuffix(misc.StringDefault(conf.OpenAI.ServerURL, "https://api.openai.com/v1"), "/")
 
@@ -119,20 +148,93 @@ func (conf *Config) Init() {
 		}
 	}
 
+	// 兼容旧版本的 openai 配置，作为默认渠道
+	if _, ok := conf.Channel(DefaultChannel); !ok {
+		conf.Channels = append(conf.Channels, Channel{
+			Name:               DefaultChannel,
+			Type:               ChannelTypeOpenAI,
+			ServerURL:          conf.OpenAI.ServerURL,
+			APIKey:             conf.OpenAI.APIKey,
+			Organization:       conf.OpenAI.Organization,
+			UseAzure:           conf.OpenAI.UseAzure,
+			AzureAPIVersion:    conf.OpenAI.AzureAPIVersion,
+			AzureModelMapping:  conf.OpenAI.AzureModelMapping,
+			DisableStreamUsage: conf.OpenAI.DisableStreamUsage,
+		})
+	}
+
+	for i := 0; i < len(conf.Channels); i++ {
+		ch := &conf.Channels[i]
+		ch.Type = misc.StringDefault(ch.Type, ChannelTypeOpenAI)
+
+		switch ch.Type {
+		case ChannelTypeAnthropic:
+			ch.ServerURL = misc.StringDefault(ch.ServerURL, "https://api.anthropic.com")
+		case ChannelTypeGemini:
+			ch.ServerURL = misc.StringDefault(ch.ServerURL, "https://generativelanguage.googleapis.com")
+		default:
+			ch.ServerURL = misc.StringDefault(ch.ServerURL, "https://api.openai.com/v1")
+			ch.AzureAPIVersion = misc.StringDefault(ch.AzureAPIVersion, "2023-05-15")
+		}
+
+		ch.ServerURL = strings.TrimSuffix(ch.ServerURL, "/")
+	}
+
 	for i := 0; i < len(conf.Models); i++ {
 		if conf.Models[i].MaxContext == 0 {
 			conf.Models[i].MaxContext = 4000
 		}
+
+		// 智谱的 GLM 4V 模型，每张图片固定消耗 1047 个 token
+		if conf.Models[i].ImageTokens == 0 && conf.Models[i].ID == "glm-4v" {
+			conf.Models[i].ImageTokens = 1047
+		}
+
+		// 未指定渠道的模型，使用第一个提供该模型的渠道，都没有时使用默认渠道
+		if conf.Models[i].Channel == "" {
+			conf.Models[i].Channel = DefaultChannel
+			for _, ch := range conf.Channels {
+				if ch.Serve(conf.Models[i].ID) {
+					conf.Models[i].Channel = ch.Name
+					break
+				}
+			}
+		}
+	}
+}
+
+// Channel returns the channel with the specified name
+func (conf *Config) Channel(name string) (Channel, bool) {
+	for _, ch := range conf.Channels {
+		if ch.Name == name {
+			return ch, true
+		}
 	}
+
+	return Channel{}, false
 }
 
 type Mail struct {
-	From     string `json:"from,omitempty" yaml:"from,omitempty"`
-	Host     string `json:"host,omitempty" yaml:"host,omitempty"`
-	Port     int    `json:"port,omitempty" yaml:"port,omitempty"`
-	Username string `json:"username,omitempty" yaml:"username,omitempty"`
-	Password string `json:"-" yaml:"password,omitempty"`
-	UseSSL   bool   `json:"use_ssl,omitempty" yaml:"use_ssl,omitempty"`
+	// From the display name of the sender, default is the brand name
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	// FromAddress the address of the sender, default is the username
+	FromAddress string `json:"from_address,omitempty" yaml:"from_address,omitempty"`
+	Host        string `json:"host,omitempty" yaml:"host,omitempty"`
+	Port        int    `json:"port,omitempty" yaml:"port,omitempty"`
+	Username    string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password    string `json:"-" yaml:"password,omitempty"`
+	UseSSL      bool   `json:"use_ssl,omitempty" yaml:"use_ssl,omitempty"`
+
+	// Brand the brand name used in mail templates, default is AIdea
+	Brand string `json:"brand,omitempty" yaml:"brand,omitempty"`
+	// TemplateDir the directory of custom mail templates, templates not found in it fall back to the built-in ones
+	TemplateDir string `json:"template_dir,omitempty" yaml:"template_dir,omitempty"`
+}
+
+func (m *Mail) init() {
+	m.Brand = misc.StringDefault(m.Brand, "AIdea")
+	m.From = misc.StringDefault(m.From, m.Brand)
+	m.FromAddress = misc.StringDefault(m.FromAddress, m.Username)
 }
 
 func Register(ins *app.App) {
@@ -143,23 +245,32 @@ func Register(ins *app.App) {
 	ins.AddBoolFlag("disable-migrate", "whether to disable database migration")
 
 	ins.Singleton(func(flg infra.FlagContext) *Config {
-		confFilePath := flg.String("conf")
-		if confFilePath == "" {
-			confFilePath = "config.yaml"
-		}
-
-		data, err := os.ReadFile(confFilePath)
+		conf, err := Load(flg.String("conf"))
 		if err != nil {
-			panic(fmt.Errorf("read config file failed: %s", err))
+			panic(err)
 		}
 
-		var conf Config
-		if err := yaml.Unmarshal(data, &conf); err != nil {
-			panic(fmt.Errorf("parse config file failed: %s", err))
-		}
+		return conf
+	})
+}
 
-		conf.Init()
+// Load 加载配置文件，配置文件路径为空时使用 config.yaml
+func Load(confFilePath string) (*Config, error) {
+	if confFilePath == "" {
+		confFilePath = "config.yaml"
+	}
 
-		return &conf
-	})
+	data, err := os.ReadFile(confFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("read config file failed: %s", err)
+	}
+
+	var conf Config
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return nil, fmt.Errorf("parse config file failed: %s", err)
+	}
+
+	conf.Init()
+
+	return &conf, nil
 }
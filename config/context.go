@@ -0,0 +1,66 @@
+package config
+
+const (
+	// ContextStrategyTruncate drop the oldest turns until the context fits, system messages are pinned
+	ContextStrategyTruncate = "truncate"
+	// ContextStrategyDropMiddle keep the first turn and the latest turns, drop the turns in the middle
+	ContextStrategyDropMiddle = "drop_middle"
+	// ContextStrategySummarize summarize the older turns with the summary model, the summary is cached per conversation
+	ContextStrategySummarize = "summarize"
+	// ContextStrategyCount keep at most MaxTurns latest turns, then truncate by tokens
+	ContextStrategyCount = "count"
+)
+
+// ContextStrategies all supported context reduction strategies
+var ContextStrategies = []string{
+	ContextStrategyTruncate,
+	ContextStrategyDropMiddle,
+	ContextStrategySummarize,
+	ContextStrategyCount,
+}
+
+// ContextReduction 上下文超出模型限制时的裁剪方式，机器人的配置优先于模型的配置
+type ContextReduction struct {
+	// Strategy truncate/drop_middle/summarize/count, default is truncate
+	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	// MaxTurns the maximum number of turns (question + answer) kept in the context, only valid for count strategy
+	MaxTurns int `json:"max_turns,omitempty" yaml:"max_turns,omitempty"`
+}
+
+// ContextSummary 上下文摘要配置，用于 summarize 策略
+type ContextSummary struct {
+	// Model the model used to summarize older turns, a cheap model is recommended, default is gpt-3.5-turbo
+	Model string `json:"model,omitempty" yaml:"model,omitempty"`
+	// MaxTokens the maximum number of tokens of the summary
+	MaxTokens int `json:"max_tokens,omitempty" yaml:"max_tokens,omitempty"`
+}
+
+func (cs *ContextSummary) init() {
+	if cs.Model == "" {
+		cs.Model = "gpt-3.5-turbo"
+	}
+
+	if cs.MaxTokens <= 0 {
+		cs.MaxTokens = 500
+	}
+}
+
+// ConversationTitle 会话标题生成配置，第一轮对话完成后在后台生成会话标题
+type ConversationTitle struct {
+	// Disabled disable the title generation, the first question is used as the title
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	// Model the model used to generate titles, a cheap model is recommended, default is gpt-3.5-turbo
+	Model string `json:"model,omitempty" yaml:"model,omitempty"`
+	// MaxTokens the maximum number of tokens of the title
+	MaxTokens int `json:"max_tokens,omitempty" yaml:"max_tokens,omitempty"`
+}
+
+func (ct *ConversationTitle) init() {
+	if ct.Model == "" {
+		ct.Model = "gpt-3.5-turbo"
+	}
+
+	if ct.MaxTokens <= 0 {
+		ct.MaxTokens = 30
+	}
+}
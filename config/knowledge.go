@@ -0,0 +1,54 @@
+package config
+
+const (
+	// KnowledgeStoreMySQL vectors are stored in MySQL, similarity is calculated in process
+	KnowledgeStoreMySQL = "mysql"
+	// KnowledgeStoreMemory vectors are stored in process memory and lost after restart, for development only
+	KnowledgeStoreMemory = "memory"
+)
+
+// Knowledge 知识库配置
+type Knowledge struct {
+	// Channel the channel used to create embeddings, must be an openai type channel, default is openai
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	// EmbeddingModel the model used to create embeddings
+	EmbeddingModel string `json:"embedding_model,omitempty" yaml:"embedding_model,omitempty"`
+	// ChunkSize the number of characters per document chunk
+	ChunkSize int `json:"chunk_size,omitempty" yaml:"chunk_size,omitempty"`
+	// TopK the number of chunks injected into the prompt
+	TopK int `json:"top_k,omitempty" yaml:"top_k,omitempty"`
+	// MinScore chunks with a similarity lower than this value are ignored
+	MinScore float64 `json:"min_score,omitempty" yaml:"min_score,omitempty"`
+	// Store vector store: mysql/memory, default is mysql
+	Store string `json:"store,omitempty" yaml:"store,omitempty"`
+	// MaxChunks the maximum number of chunks in a knowledge base, default is 5000
+	MaxChunks int64 `json:"max_chunks,omitempty" yaml:"max_chunks,omitempty"`
+	// EmbeddingPrice the price of creating embeddings when uploading documents, calculated based on 1K Token, 0 means free
+	EmbeddingPrice int64 `json:"embedding_price,omitempty" yaml:"embedding_price,omitempty"`
+}
+
+func (kb *Knowledge) init() {
+	if kb.Channel == "" {
+		kb.Channel = DefaultChannel
+	}
+
+	if kb.EmbeddingModel == "" {
+		kb.EmbeddingModel = "text-embedding-3-small"
+	}
+
+	if kb.ChunkSize <= 0 {
+		kb.ChunkSize = 500
+	}
+
+	if kb.TopK <= 0 {
+		kb.TopK = 3
+	}
+
+	if kb.MaxChunks <= 0 {
+		kb.MaxChunks = 5000
+	}
+
+	if kb.Store == "" {
+		kb.Store = KnowledgeStoreMySQL
+	}
+}
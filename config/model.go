@@ -13,6 +13,17 @@ type Model struct {
 	Price int64 `json:"price,omitempty" yaml:"price,omitempty"`
 	// MaxContext model max context
 	MaxContext int `json:"max_context,omitempty" yaml:"max_context,omitempty"`
+	// Channel the name of the channel that serves this model
+	Channel string `json:"-" yaml:"channel,omitempty"`
+	// BackupChannels the channels used when the primary channel fails or the client retries
+	BackupChannels []string `json:"-" yaml:"backup_channels,omitempty"`
+	// Tokenizer the tokenizer used to count tokens: cl100k_base/o200k_base/p50k_base/r50k_base/estimate
+	// leave blank to use the tiktoken encoding of the OpenAI model, other models use cl100k_base
+	Tokenizer string `json:"-" yaml:"tokenizer,omitempty"`
+	// ImageTokens fixed number of tokens per image, 0 means calculated by image size (OpenAI tile formula)
+	ImageTokens int `json:"-" yaml:"image_tokens,omitempty"`
+	// Context how to reduce the context when it exceeds MaxContext, can be overridden by the robot
+	Context ContextReduction `json:"-" yaml:"context,omitempty"`
 	// Capabilities model capabilities
 	Capabilities []string `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
 }
@@ -10,4 +10,7 @@ type OpenAIConfig struct {
 	// AzureModelMapping azure model mapping
 	// Key: OpenAI model name, Value: Azure model name
 	AzureModelMapping map[string]string `json:"azure_model_mapping,omitempty" yaml:"azure_model_mapping,omitempty"`
+
+	// DisableStreamUsage do not request stream_options.include_usage, for upstreams that reject unknown parameters
+	DisableStreamUsage bool `json:"disable_stream_usage,omitempty" yaml:"disable_stream_usage,omitempty"`
 }
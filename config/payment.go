@@ -0,0 +1,61 @@
+package config
+
+// Payment 支付配置
+type Payment struct {
+	// Products the products available for purchase, payments are disabled when it is empty
+	Products []Product `json:"products,omitempty" yaml:"products,omitempty"`
+	// Apple App Store in-app purchase configuration
+	Apple AppleIAP `json:"apple,omitempty" yaml:"apple,omitempty"`
+}
+
+// Product 可购买的智慧果产品
+type Product struct {
+	// ID the product id, must be the same as the product id in App Store Connect for apple in-app purchases
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Quota the number of coins granted for each purchase
+	Quota int64 `json:"quota" yaml:"quota"`
+	// Price the retail price in cents, only used for display and statistics
+	Price int64 `json:"price" yaml:"price"`
+	// ValidDays the number of days the coins are valid, default is 365
+	ValidDays int `json:"valid_days,omitempty" yaml:"valid_days,omitempty"`
+}
+
+// AppleIAP App Store Server API 配置，用于校验 Apple 应用内购买的交易
+type AppleIAP struct {
+	// BundleID the bundle id of the app, transactions of other apps are rejected
+	BundleID string `json:"bundle_id,omitempty" yaml:"bundle_id,omitempty"`
+	// IssuerID the issuer id of the in-app purchase key in App Store Connect
+	IssuerID string `json:"issuer_id,omitempty" yaml:"issuer_id,omitempty"`
+	// KeyID the id of the in-app purchase key
+	KeyID string `json:"key_id,omitempty" yaml:"key_id,omitempty"`
+	// PrivateKey the content of the in-app purchase key (.p8 file)
+	PrivateKey string `json:"-" yaml:"private_key,omitempty"`
+	// Sandbox only use the sandbox environment, otherwise the sandbox environment is used when the transaction is not found in production
+	Sandbox bool `json:"sandbox,omitempty" yaml:"sandbox,omitempty"`
+}
+
+// Enabled 是否配置了 Apple 应用内购买
+func (iap AppleIAP) Enabled() bool {
+	return iap.BundleID != "" && iap.IssuerID != "" && iap.KeyID != "" && iap.PrivateKey != ""
+}
+
+// Product 根据 ID 查询产品
+func (pay Payment) Product(id string) (Product, bool) {
+	for _, p := range pay.Products {
+		if p.ID == id {
+			return p, true
+		}
+	}
+
+	return Product{}, false
+}
+
+func (pay *Payment) init() {
+	for i := range pay.Products {
+		if pay.Products[i].ValidDays <= 0 {
+			pay.Products[i].ValidDays = 365
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+const (
+	// SMSDriverAliyun 阿里云短信服务
+	SMSDriverAliyun = "aliyun"
+	// SMSDriverTencent 腾讯云短信服务
+	SMSDriverTencent = "tencent"
+	// SMSDriverHTTP sends the message to a custom HTTP webhook, which is responsible for delivering it
+	SMSDriverHTTP = "http"
+	// SMSDriverFake only logs and records the message in process memory, for development and testing only
+	SMSDriverFake = "fake"
+)
+
+// SMSTemplateVerifyCode 验证码短信模板，模板参数为 code
+const SMSTemplateVerifyCode = "verify_code"
+
+// SMS 短信发送配置
+type SMS struct {
+	// Driver sms driver: aliyun/tencent/http/fake, default is fake
+	Driver string `json:"driver,omitempty" yaml:"driver,omitempty"`
+	// SignName the signature of the message, used by aliyun and tencent
+	SignName string `json:"sign_name,omitempty" yaml:"sign_name,omitempty"`
+	// Templates template name => template id of the sms provider, e.g. verify_code: SMS_123456
+	Templates map[string]string `json:"templates,omitempty" yaml:"templates,omitempty"`
+
+	Aliyun  SMSAliyun  `json:"aliyun,omitempty" yaml:"aliyun,omitempty"`
+	Tencent SMSTencent `json:"tencent,omitempty" yaml:"tencent,omitempty"`
+	HTTP    SMSHTTP    `json:"http,omitempty" yaml:"http,omitempty"`
+}
+
+// SMSAliyun 阿里云短信服务配置
+type SMSAliyun struct {
+	AccessKeyID     string `json:"access_key_id,omitempty" yaml:"access_key_id,omitempty"`
+	AccessKeySecret string `json:"-" yaml:"access_key_secret,omitempty"`
+	// Endpoint the api endpoint, default is https://dysmsapi.aliyuncs.com
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+}
+
+// SMSTencent 腾讯云短信服务配置
+type SMSTencent struct {
+	SecretID  string `json:"secret_id,omitempty" yaml:"secret_id,omitempty"`
+	SecretKey string `json:"-" yaml:"secret_key,omitempty"`
+	// SDKAppID the SmsSdkAppId of the sms application
+	SDKAppID string `json:"sdk_app_id,omitempty" yaml:"sdk_app_id,omitempty"`
+	// Region the region of the api, default is ap-guangzhou
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// Endpoint the api endpoint, default is https://sms.tencentcloudapi.com
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+}
+
+// SMSHTTP 自定义 HTTP Webhook 短信配置
+type SMSHTTP struct {
+	// URL the webhook address, the message is sent as a JSON POST request
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// Token sent in the Authorization header as a Bearer token when it is not empty
+	Token string `json:"-" yaml:"token,omitempty"`
+}
+
+func (sms *SMS) init() {
+	if sms.Driver == "" {
+		sms.Driver = SMSDriverFake
+	}
+
+	if sms.Templates == nil {
+		sms.Templates = map[string]string{}
+	}
+
+	if sms.Aliyun.Endpoint == "" {
+		sms.Aliyun.Endpoint = "https://dysmsapi.aliyuncs.com"
+	}
+
+	if sms.Tencent.Region == "" {
+		sms.Tencent.Region = "ap-guangzhou"
+	}
+
+	if sms.Tencent.Endpoint == "" {
+		sms.Tencent.Endpoint = "https://sms.tencentcloudapi.com"
+	}
+}
@@ -17,12 +17,15 @@ require (
 	github.com/mylxsw/eloquent v0.0.2-0.20231129035241-c08e054b0632
 	github.com/mylxsw/glacier v1.1.4-0.20231112080120-114e547468b0
 	github.com/mylxsw/go-utils v1.0.3
-	github.com/pkoukk/tiktoken-go v0.1.6
+	github.com/pkoukk/tiktoken-go v0.1.7
+	github.com/pkoukk/tiktoken-go-loader v0.0.2
 	github.com/prometheus/client_golang v1.18.0
 	github.com/redis/go-redis/v9 v9.5.1
 	github.com/robfig/cron/v3 v3.0.1
+	github.com/sashabaranov/go-openai v1.24.0
 	github.com/speps/go-hashids/v2 v2.0.1
 	github.com/tideland/gorest v2.15.5+incompatible
+	github.com/urfave/cli/v2 v2.23.7
 	github.com/wagslane/go-password-validator v0.3.0
 	golang.org/x/crypto v0.19.0
 	gopkg.in/gomail.v2 v2.0.0-20160411212932-81ebce5c23df
@@ -56,10 +59,8 @@ require (
 	github.com/prometheus/common v0.45.0 // indirect
 	github.com/prometheus/procfs v0.12.0 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
-	github.com/sashabaranov/go-openai v1.20.1 // indirect
 	github.com/spf13/cast v1.3.1 // indirect
 	github.com/tideland/golib v4.24.2+incompatible // indirect
-	github.com/urfave/cli/v2 v2.23.7 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
 	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sys v0.17.0 // indirect
@@ -0,0 +1,15 @@
+package coins
+
+import (
+	"github.com/mylxsw/aidea-chat-server/config"
+	"math"
+)
+
+// GetTextModelCoins 计算文本模型消耗的智慧果数量，模型价格按照 1K Token 计算
+func GetTextModelCoins(model config.Model, tokenCount int64) int64 {
+	if model.Price <= 0 || tokenCount <= 0 {
+		return 0
+	}
+
+	return int64(math.Ceil(float64(model.Price) * float64(tokenCount) / 1000.0))
+}
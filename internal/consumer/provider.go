@@ -64,6 +64,8 @@ func (p Provider) Boot(resolver infra.Resolver) {
 	resolver.MustResolve(tasks.RegisterBindPhoneTask)
 	resolver.MustResolve(tasks.RegisterSignupTask)
 	resolver.MustResolve(tasks.RegisterSMSTask)
+	resolver.MustResolve(tasks.RegisterConversationTitleTask)
+	resolver.MustResolve(tasks.RegisterPaymentCompletedTask)
 }
 
 func (Provider) ShouldLoad(conf *config.Config) bool {
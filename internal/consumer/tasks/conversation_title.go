@@ -0,0 +1,99 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hibiken/asynq"
+	"github.com/mylxsw/aidea-chat-server/internal/queue"
+	"github.com/mylxsw/aidea-chat-server/pkg/chat"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"time"
+)
+
+const TypeConversationTitle = "conversation:title"
+
+type ConversationTitlePayload struct {
+	ID             string    `json:"id,omitempty"`
+	UserID         int64     `json:"user_id"`
+	ConversationID int64     `json:"conversation_id"`
+	Question       string    `json:"question"`
+	Answer         string    `json:"answer"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+}
+
+func (payload *ConversationTitlePayload) GetType() string {
+	return TypeConversationTitle
+}
+
+func (payload *ConversationTitlePayload) GetTitle() string {
+	return "生成会话标题"
+}
+
+func (payload *ConversationTitlePayload) SetID(id string) { payload.ID = id }
+
+func (payload *ConversationTitlePayload) GetID() string {
+	return payload.ID
+}
+
+func RegisterConversationTitleTask(mux *asynq.ServeMux, chatter *chat.Chatter, rp *repo.Repository) {
+	mux.HandleFunc(TypeConversationTitle, func(ctx context.Context, task *asynq.Task) (err error) {
+		var payload ConversationTitlePayload
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return err
+		}
+
+		// 如果任务是 10 分钟前创建的，不再处理，此时用户大概率已经不在当前会话中
+		if payload.CreatedAt.Add(10 * time.Minute).Before(time.Now()) {
+			return nil
+		}
+
+		defer func() {
+			if err2 := recover(); err2 != nil {
+				log.With(task).Errorf("panic: %v", err2)
+				err = err2.(error)
+			}
+
+			if err != nil {
+				if err := rp.Queue.Update(
+					context.TODO(),
+					payload.GetID(),
+					repo.QueueTaskStatusFailed,
+					queue.ErrorResult{
+						Errors: []string{err.Error()},
+					},
+				); err != nil {
+					log.With(task).Errorf("update queue status failed: %s", err)
+				}
+			}
+		}()
+
+		titleCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		title, err := chatter.GenerateTitle(titleCtx, payload.Question, payload.Answer)
+		if err != nil {
+			log.F(log.M{"user_id": payload.UserID, "conversation_id": payload.ConversationID}).Errorf("generate conversation title failed: %v", err)
+			return err
+		}
+
+		// 只替换默认标题，用户已经重命名的会话保持不变
+		if _, err := rp.Conversation.ReplaceConversationTitle(
+			ctx,
+			payload.UserID,
+			payload.ConversationID,
+			repo.DefaultConversationTitle(payload.Question),
+			repo.DefaultConversationTitle(title),
+		); err != nil {
+			log.F(log.M{"user_id": payload.UserID, "conversation_id": payload.ConversationID}).Errorf("update conversation title failed: %v", err)
+			return err
+		}
+
+		return rp.Queue.Update(
+			context.TODO(),
+			payload.GetID(),
+			repo.QueueTaskStatusSuccess,
+			queue.EmptyResult{},
+		)
+	})
+}
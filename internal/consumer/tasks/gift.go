@@ -24,3 +24,23 @@ func inviteGiftHandler(ctx context.Context, rp *repo.Repository, userId, invited
 		}
 	}
 }
+
+// invitePaymentGiftHandler 被引荐人充值后，引荐人获得充值智慧果的 InvitePaymentGiftRate 比例作为奖励，同一笔支付只奖励一次
+func invitePaymentGiftHandler(ctx context.Context, rp *repo.Repository, invitedByUserId int64, quota int64, paymentID string) error {
+	gift := int64(float64(quota) * coins.InvitePaymentGiftRate)
+	if gift <= 0 {
+		return nil
+	}
+
+	exists, err := rp.Quota.PaymentQuotaExists(ctx, invitedByUserId, paymentID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err = rp.Quota.AddUserQuota(ctx, invitedByUserId, gift, time.Now().AddDate(0, 1, 0), "引荐人充值奖励", paymentID)
+	return err
+}
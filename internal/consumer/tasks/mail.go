@@ -3,7 +3,6 @@ package tasks
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/hibiken/asynq"
 	"github.com/mylxsw/aidea-chat-server/internal/queue"
 	"github.com/mylxsw/aidea-chat-server/pkg/mail"
@@ -15,10 +14,16 @@ import (
 const TypeMailSend = "mail:send"
 
 type MailPayload struct {
-	ID        string    `json:"id,omitempty"`
-	To        []string  `json:"to"`
-	Subject   string    `json:"subject"`
-	Body      string    `json:"body"`
+	ID string   `json:"id,omitempty"`
+	To []string `json:"to"`
+	// Template the mail template, see mail.Templates
+	Template string `json:"template,omitempty"`
+	// Language the language of the client, used to choose the template
+	Language string         `json:"language,omitempty"`
+	Data     map[string]any `json:"data,omitempty"`
+	// Subject and Body plain text mail, only used when Template is empty
+	Subject   string    `json:"subject,omitempty"`
+	Body      string    `json:"body,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 }
 
@@ -27,6 +32,10 @@ func (payload *MailPayload) GetType() string {
 }
 
 func (payload *MailPayload) GetTitle() string {
+	if payload.Template != "" {
+		return payload.Template
+	}
+
 	return payload.Subject
 }
 
@@ -68,7 +77,13 @@ func RegisterMailSendTask(mux *asynq.ServeMux, mailer *mail.Sender, rp *repo.Rep
 			}
 		}()
 
-		if err := mailer.Send(payload.To, fmt.Sprintf("【AIdea】%s", payload.Subject), payload.Body); err != nil {
+		if payload.Template != "" {
+			err = mailer.SendTemplate(payload.To, payload.Language, payload.Template, payload.Data)
+		} else {
+			err = mailer.SendText(payload.To, payload.Subject, payload.Body)
+		}
+
+		if err != nil {
 			log.With(payload).Errorf("send mail failed: %v", err)
 			return err
 		}
@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/internal/queue"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"time"
+)
+
+const TypePaymentCompleted = "payment:completed"
+
+type PaymentCompletedPayload struct {
+	ID        string    `json:"id,omitempty"`
+	EventID   int64     `json:"event_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (payload *PaymentCompletedPayload) GetType() string {
+	return TypePaymentCompleted
+}
+
+func (payload *PaymentCompletedPayload) GetTitle() string {
+	return "支付完成"
+}
+
+func (payload *PaymentCompletedPayload) SetID(id string) {
+	payload.ID = id
+}
+
+func (payload *PaymentCompletedPayload) GetID() string {
+	return payload.ID
+}
+
+// EnqueuePaymentCompleted 将 payment_completed 事件加入队列
+//
+// 使用事件 ID 作为任务 ID，事件的任务还在队列中（等待执行或者等待重试）时不会重复加入
+func EnqueuePaymentCompleted(ctx context.Context, qu *queue.Queue, eventID int64) error {
+	payload := PaymentCompletedPayload{
+		EventID:   eventID,
+		CreatedAt: time.Now(),
+	}
+
+	_, err := qu.Enqueue(ctx, &payload, asynq.Queue("user"), asynq.TaskID(fmt.Sprintf("%s:%d", TypePaymentCompleted, eventID)))
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+
+	return err
+}
+
+func RegisterPaymentCompletedTask(mux *asynq.ServeMux, conf *config.Config, rp *repo.Repository) {
+	mux.HandleFunc(TypePaymentCompleted, func(ctx context.Context, task *asynq.Task) (err error) {
+		var payload PaymentCompletedPayload
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return err
+		}
+
+		// 用户已经完成支付，任务不会因为创建时间过长而跳过，由事件状态保证只处理一次
+
+		defer func() {
+			if err2 := recover(); err2 != nil {
+				log.With(task).Errorf("panic: %v", err2)
+				err = err2.(error)
+			}
+
+			if err != nil {
+				// 任务还会重试时，事件保持 waiting 状态，只有最后一次重试失败时才标记为失败
+				retried, _ := asynq.GetRetryCount(ctx)
+				maxRetry, _ := asynq.GetMaxRetry(ctx)
+				if retried < maxRetry {
+					log.WithFields(log.Fields{"event_id": payload.EventID, "retried": retried}).Warningf("payment completed task failed, will retry: %s", err)
+					return
+				}
+
+				if err := rp.Queue.Update(
+					context.TODO(),
+					payload.GetID(),
+					repo.QueueTaskStatusFailed,
+					queue.ErrorResult{
+						Errors: []string{err.Error()},
+					},
+				); err != nil {
+					log.With(task).Errorf("update queue status failed: %s", err)
+				}
+
+				if err := rp.Event.UpdateEvent(ctx, payload.EventID, repo.EventStatusFailed); err != nil {
+					log.WithFields(log.Fields{"event_id": payload.EventID}).Errorf("update event status failed: %s", err)
+				}
+			}
+		}()
+
+		// 查询事件记录
+		event, err := rp.Event.GetEvent(ctx, payload.EventID)
+		if err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				log.WithFields(log.Fields{"event_id": payload.EventID}).Errorf("event not found")
+				return nil
+			}
+
+			log.With(payload).Errorf("get event failed: %s", err)
+			return err
+		}
+
+		if event.Status != repo.EventStatusWaiting {
+			log.WithFields(log.Fields{"event_id": payload.EventID}).Warningf("event status is not waiting")
+			return nil
+		}
+
+		if event.EventType != repo.EventTypePaymentCompleted {
+			log.With(payload).Errorf("event type is not payment_completed")
+			return nil
+		}
+
+		var eventPayload repo.PaymentCompletedEvent
+		if err := json.Unmarshal([]byte(event.Payload), &eventPayload); err != nil {
+			log.With(payload).Errorf("unmarshal event payload failed: %s", err)
+			return err
+		}
+
+		order, err := rp.Payment.GetOrder(ctx, eventPayload.UserID, eventPayload.PaymentID)
+		if err != nil {
+			log.With(eventPayload).Errorf("get payment order failed: %s", err)
+			return err
+		}
+
+		if order.Status != repo.PaymentOrderStatusSucceed {
+			log.With(eventPayload).Errorf("payment order is not paid")
+			return nil
+		}
+
+		// 为用户增加购买的智慧果，已经增加过时（例如事件状态更新失败后重新处理）不再重复增加
+		exists, err := rp.Quota.PaymentQuotaExists(ctx, order.UserID, order.OrderNo)
+		if err != nil {
+			log.With(eventPayload).Errorf("query payment quota failed: %s", err)
+			return err
+		}
+
+		if !exists {
+			validDays := 365
+			if product, ok := conf.Payment.Product(order.ProductID); ok {
+				validDays = product.ValidDays
+			}
+
+			if _, err := rp.Quota.AddUserQuota(ctx, order.UserID, order.Quota, time.Now().AddDate(0, 0, validDays), "购买智慧果", order.OrderNo); err != nil {
+				log.With(eventPayload).Errorf("create user quota failed: %s", err)
+				return err
+			}
+		}
+
+		// 引荐人奖励，已经发放过时不再重复发放，失败时重试
+		user, err := rp.User.GetUserByID(ctx, order.UserID)
+		if err != nil && !errors.Is(err, repo.ErrNotFound) {
+			log.WithFields(log.Fields{"user_id": order.UserID}).Errorf("get user failed: %s", err)
+			return err
+		}
+
+		if user != nil && user.InvitedBy > 0 {
+			if err := invitePaymentGiftHandler(ctx, rp, user.InvitedBy, order.Quota, order.OrderNo); err != nil {
+				log.WithFields(log.Fields{"user_id": order.UserID, "invited_by": user.InvitedBy}).Errorf("create invite payment gift failed: %s", err)
+				return err
+			}
+		}
+
+		// 更新事件状态
+		if err := rp.Event.UpdateEvent(ctx, payload.EventID, repo.EventStatusSucceed); err != nil {
+			log.WithFields(log.Fields{"event_id": payload.EventID}).Errorf("update event status failed: %s", err)
+		}
+
+		return rp.Queue.Update(
+			context.TODO(),
+			payload.GetID(),
+			repo.QueueTaskStatusSuccess,
+			queue.EmptyResult{},
+		)
+	})
+}
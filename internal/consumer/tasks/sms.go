@@ -6,6 +6,7 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/mylxsw/aidea-chat-server/internal/queue"
 	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/aidea-chat-server/pkg/sms"
 	"github.com/mylxsw/asteria/log"
 	"time"
 )
@@ -35,7 +36,7 @@ func (payload *SMSVerifyCodePayload) GetID() string {
 	return payload.ID
 }
 
-func RegisterSMSTask(mux *asynq.ServeMux, rp *repo.Repository) {
+func RegisterSMSTask(mux *asynq.ServeMux, sender *sms.Sender, rp *repo.Repository) {
 	mux.HandleFunc(TypeSMSVerifyCode, func(ctx context.Context, task *asynq.Task) (err error) {
 		var payload SMSVerifyCodePayload
 		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
@@ -43,7 +44,7 @@ func RegisterSMSTask(mux *asynq.ServeMux, rp *repo.Repository) {
 		}
 
 		// 如果任务是 5 分钟前创建的，不再处理
-		if payload.CreatedAt.Add(5 * time.Minute).Before(time.Now()) {
+		if smsVerifyCodeExpired(payload, time.Now()) {
 			return nil
 		}
 
@@ -67,8 +68,10 @@ func RegisterSMSTask(mux *asynq.ServeMux, rp *repo.Repository) {
 			}
 		}()
 
-		// TODO 发送短信验证码
-		log.F(log.M{"code": payload.Code}).Infof("send sms code to %s", payload.Receiver)
+		if err := sender.SendVerifyCode(ctx, payload.Receiver, payload.Code); err != nil {
+			log.F(log.M{"receiver": payload.Receiver}).Errorf("send sms code failed: %v", err)
+			return err
+		}
 
 		return rp.Queue.Update(
 			context.TODO(),
@@ -78,3 +81,8 @@ func RegisterSMSTask(mux *asynq.ServeMux, rp *repo.Repository) {
 		)
 	})
 }
+
+// smsVerifyCodeExpired 验证码的有效期为 5 分钟，超过后不再发送
+func smsVerifyCodeExpired(payload SMSVerifyCodePayload, now time.Time) bool {
+	return payload.CreatedAt.Add(5 * time.Minute).Before(now)
+}
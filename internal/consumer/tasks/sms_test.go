@@ -0,0 +1,25 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSMSVerifyCodeExpired(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	payload := SMSVerifyCodePayload{Receiver: "13800000000", Code: "1234", CreatedAt: createdAt}
+
+	testCases := map[time.Duration]bool{
+		0:                               false,
+		4 * time.Minute:                 false,
+		5 * time.Minute:                 false,
+		5*time.Minute + time.Nanosecond: true,
+		6 * time.Minute:                 true,
+	}
+
+	for elapsed, want := range testCases {
+		if got := smsVerifyCodeExpired(payload, createdAt.Add(elapsed)); got != want {
+			t.Errorf("%s elapsed: expect expired %v, got %v", elapsed, want, got)
+		}
+	}
+}
@@ -19,4 +19,11 @@ func jobs(creator scheduler.JobCreator) {
 		"0 0 0 * * *",
 		scheduler.WithoutOverlap(ClearExpiredCacheJob),
 	))
+
+	// 重新加入队列长时间没有处理的支付事件
+	misc.NoError(creator.Add(
+		"requeue-payment-events",
+		"0 */5 * * * *",
+		scheduler.WithoutOverlap(RequeuePaymentEventsJob),
+	))
 }
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"github.com/mylxsw/aidea-chat-server/internal/consumer/tasks"
+	"github.com/mylxsw/aidea-chat-server/internal/queue"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"time"
+)
+
+// RequeuePaymentEventsJob 将长时间没有处理的 payment_completed 事件重新加入队列
+//
+// 支付完成后加入队列失败（例如 Redis 不可用）时，用户已经付款但是没有获得智慧果，由该任务补偿
+func RequeuePaymentEventsJob(ctx context.Context, rp *repo.Repository, qu *queue.Queue) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	events, err := rp.Event.GetWaitingEvents(ctx, repo.EventTypePaymentCompleted, time.Now().Add(-5*time.Minute), 100)
+	if err != nil {
+		log.Errorf("查询等待处理的支付事件失败: %v", err)
+		return nil
+	}
+
+	for _, event := range events {
+		if err := tasks.EnqueuePaymentCompleted(ctx, qu, event.Id); err != nil {
+			log.WithFields(log.Fields{"event_id": event.Id}).Errorf("支付事件重新加入队列失败: %v", err)
+		}
+	}
+
+	return nil
+}
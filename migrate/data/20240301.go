@@ -0,0 +1,40 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240301(m *migrate.Manager) {
+
+	m.Schema("20240301").Create("conversations", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.Integer("user_id", false, true).Nullable(false).Comment("User ID")
+		builder.String("robot_id", 64).Nullable(true).Comment("Robot ID")
+		builder.String("title", 255).Nullable(true).Comment("Title")
+
+		builder.Index("idx_user_updated_at", "user_id", "updated_at")
+	})
+
+	m.Schema("20240301").Create("chat_messages", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.Integer("user_id", false, true).Nullable(false).Comment("User ID")
+		builder.Integer("conversation_id", false, true).Nullable(false).Comment("Conversation ID")
+		builder.String("robot_id", 64).Nullable(true).Comment("Robot ID")
+		builder.String("role", 20).Nullable(false).Comment("Role: user, assistant")
+		builder.MediumText("message").Nullable(true).Comment("Message")
+		builder.Json("multipart_contents").Nullable(true).Comment("Multipart Contents")
+		builder.Integer("pid", false, true).Nullable(true).Comment("问题 ID，仅 assistant 消息有效")
+		builder.String("model", 64).Nullable(true).Comment("Model")
+		builder.Integer("prompt_tokens", false, true).Nullable(true).Default(migrate.RawExpr("0")).Comment("Prompt Tokens")
+		builder.Integer("completion_tokens", false, true).Nullable(true).Default(migrate.RawExpr("0")).Comment("Completion Tokens")
+		builder.Integer("first_letter_delay", false, true).Nullable(true).Default(migrate.RawExpr("0")).Comment("首字延迟，单位毫秒")
+		builder.Integer("consume_in_milli", false, true).Nullable(true).Default(migrate.RawExpr("0")).Comment("总耗时，单位毫秒")
+		builder.String("status", 20).Nullable(false).Comment("Status: succeed, failed")
+		builder.Text("error").Nullable(true).Comment("Error")
+
+		builder.Index("idx_conversation_id", "conversation_id")
+		builder.Index("idx_user_id", "user_id")
+	})
+}
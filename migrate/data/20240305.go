@@ -0,0 +1,10 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240305(m *migrate.Manager) {
+
+	m.Schema("20240305").Table("chat_messages", func(builder *migrate.Builder) {
+		builder.Integer("quota_consumed", false, true).Nullable(true).Default(migrate.RawExpr("0")).After("consume_in_milli").Comment("消耗的智慧果数量")
+	})
+}
@@ -0,0 +1,27 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240310(m *migrate.Manager) {
+
+	m.Schema("20240310").Create("robots", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.String("robot_id", 64).Nullable(false).Comment("Robot ID")
+		builder.Integer("user_id", false, true).Nullable(false).Default(migrate.RawExpr("0")).Comment("创建者 ID，0 表示系统内置")
+		builder.String("name", 100).Nullable(false).Comment("Name")
+		builder.TinyInteger("type", false, true).Nullable(false).Default(migrate.RawExpr("1")).Comment("Type: 1-model driven, 2-custom server")
+		builder.String("description", 255).Nullable(true).Comment("Description")
+		builder.TinyInteger("privilege", false, true).Nullable(false).Default(migrate.RawExpr("1")).Comment("Privilege: 1-private, 2-public")
+		builder.String("model", 64).Nullable(true).Comment("Model")
+		builder.Text("prompt").Nullable(true).Comment("System Prompt")
+		builder.Text("welcome_message").Nullable(true).Comment("Welcome Message")
+		builder.String("server_url", 255).Nullable(true).Comment("Custom Server URL")
+		builder.String("server_token", 255).Nullable(true).Comment("Custom Server Token")
+		builder.Json("robot_meta").Nullable(true).Comment("Robot Meta")
+
+		builder.Unique("uk_robot_id", "robot_id")
+		builder.Index("idx_user_id", "user_id")
+	})
+}
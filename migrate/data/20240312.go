@@ -0,0 +1,10 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240312(m *migrate.Manager) {
+
+	m.Schema("20240312").Table("chat_messages", func(builder *migrate.Builder) {
+		builder.String("channel", 64).Nullable(true).After("model").Comment("实际提供服务的渠道")
+	})
+}
@@ -0,0 +1,22 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240315(m *migrate.Manager) {
+
+	m.Schema("20240315").Create("user_api_keys", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.Integer("user_id", false, true).Nullable(false).Comment("User ID")
+		builder.String("name", 100).Nullable(false).Comment("Name")
+		builder.String("key_hash", 64).Nullable(false).Comment("SHA256 hash of the key")
+		builder.String("key_preview", 32).Nullable(true).Comment("脱敏后的 Key，用于展示")
+		builder.String("scopes", 255).Nullable(true).Comment("允许访问的范围，多个以逗号分隔")
+		builder.Timestamp("last_used_at", 0).Nullable(true).Comment("最后使用时间")
+		builder.Timestamp("expired_at", 0).Nullable(true).Comment("过期时间，为空表示永不过期")
+
+		builder.Unique("uk_key_hash", "key_hash")
+		builder.Index("idx_user_id", "user_id")
+	})
+}
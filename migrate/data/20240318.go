@@ -0,0 +1,47 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240318(m *migrate.Manager) {
+
+	m.Schema("20240318").Create("knowledge_bases", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.String("kb_id", 64).Nullable(false).Comment("知识库 ID")
+		builder.Integer("user_id", false, true).Nullable(false).Comment("User ID")
+		builder.String("name", 100).Nullable(false).Comment("Name")
+		builder.String("description", 255).Nullable(true).Comment("Description")
+
+		builder.Unique("uk_kb_id", "kb_id")
+		builder.Index("idx_user_id", "user_id")
+	})
+
+	m.Schema("20240318").Create("knowledge_documents", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.String("kb_id", 64).Nullable(false).Comment("知识库 ID")
+		builder.Integer("user_id", false, true).Nullable(false).Comment("User ID")
+		builder.String("title", 255).Nullable(false).Comment("文档标题")
+		builder.String("content_type", 50).Nullable(true).Comment("文档类型：text/markdown")
+		builder.Integer("content_length", false, true).Nullable(true).Comment("文档字符数")
+		builder.Integer("chunk_count", false, true).Nullable(true).Comment("分块数量")
+
+		builder.Index("idx_kb_id", "kb_id")
+	})
+
+	m.Schema("20240318").Create("knowledge_chunks", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.String("kb_id", 64).Nullable(false).Comment("知识库 ID")
+		builder.Integer("document_id", false, true).Nullable(false).Comment("文档 ID")
+		builder.Integer("seq", false, true).Nullable(false).Comment("分块序号")
+		builder.Text("content").Nullable(true).Comment("分块内容")
+		builder.MediumText("embedding").Nullable(true).Comment("向量，JSON 数组")
+
+		builder.Index("idx_kb_id", "kb_id")
+		builder.Index("idx_document_id", "document_id")
+	})
+}
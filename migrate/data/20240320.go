@@ -0,0 +1,14 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240320(m *migrate.Manager) {
+
+	m.Schema("20240320").Table("chat_messages", func(builder *migrate.Builder) {
+		builder.Integer("parent_id", false, true).Nullable(true).After("pid").Comment("当前分支中的上一条消息 ID，0 表示会话的第一条消息，为空时按照 ID 顺序（旧数据）")
+	})
+
+	m.Schema("20240320").Table("conversations", func(builder *migrate.Builder) {
+		builder.Integer("current_message_id", false, true).Nullable(true).After("title").Comment("当前分支的最后一条消息 ID")
+	})
+}
@@ -0,0 +1,22 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240322(m *migrate.Manager) {
+
+	// 旧数据的最后活跃时间即为最后一条消息的时间
+	m.Schema("20240322").Raw("conversations", func() []string {
+		return []string{
+			"ALTER TABLE `conversations` ADD COLUMN `last_message_at` TIMESTAMP NULL COMMENT '最后一条消息的时间' AFTER `current_message_id`",
+			"ALTER TABLE `conversations` ADD INDEX `idx_user_last_message_at` (`user_id`, `last_message_at`)",
+			"UPDATE `conversations` SET `last_message_at` = `updated_at` WHERE `last_message_at` IS NULL",
+		}
+	})
+
+	// 聊天记录全文检索，使用 ngram 分词以支持中文
+	m.Schema("20240322").Raw("chat_messages", func() []string {
+		return []string{
+			"ALTER TABLE `chat_messages` ADD FULLTEXT INDEX `ft_message` (`message`) WITH PARSER ngram",
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240324(m *migrate.Manager) {
+
+	m.Schema("20240324").Create("payment_orders", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.String("order_no", 64).Nullable(false).Comment("订单号，同时作为 quota 的 payment_id")
+		builder.Integer("user_id", false, true).Nullable(false).Comment("User ID")
+		builder.String("product_id", 100).Nullable(false).Comment("产品 ID")
+		builder.Integer("quota", false, true).Nullable(false).Comment("购买的智慧果数量")
+		builder.Integer("price", false, true).Nullable(false).Comment("价格，单位为分")
+		builder.String("source", 20).Nullable(false).Comment("支付渠道：apple")
+		builder.String("status", 20).Nullable(false).Comment("状态：waiting/succeed")
+		builder.Timestamp("paid_at", 0).Nullable(true).Comment("支付完成时间")
+
+		builder.Unique("uk_order_no", "order_no")
+		builder.Index("idx_user_id", "user_id")
+	})
+
+	m.Schema("20240324").Create("payment_records", func(builder *migrate.Builder) {
+		builder.Increments("id")
+		builder.Timestamps(0)
+
+		builder.String("order_no", 64).Nullable(false).Comment("订单号")
+		builder.Integer("user_id", false, true).Nullable(false).Comment("User ID")
+		builder.String("source", 20).Nullable(false).Comment("支付渠道：apple")
+		builder.String("transaction_id", 100).Nullable(false).Comment("支付渠道的交易 ID")
+		builder.String("original_transaction_id", 100).Nullable(true).Comment("支付渠道的原始交易 ID")
+		builder.String("product_id", 100).Nullable(false).Comment("产品 ID")
+		builder.Integer("quantity", false, true).Nullable(false).Comment("购买数量")
+		builder.String("environment", 20).Nullable(true).Comment("支付环境：Production/Sandbox")
+		builder.Timestamp("purchased_at", 0).Nullable(true).Comment("购买时间")
+		builder.Text("payload").Nullable(true).Comment("支付渠道返回的交易信息")
+
+		builder.Unique("uk_source_transaction", "source", "transaction_id")
+		builder.Index("idx_order_no", "order_no")
+		builder.Index("idx_user_id", "user_id")
+	})
+}
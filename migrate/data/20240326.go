@@ -0,0 +1,14 @@
+package data
+
+import "github.com/mylxsw/eloquent/migrate"
+
+func Migrate20240326(m *migrate.Manager) {
+
+	m.Schema("20240326").Table("payment_orders", func(builder *migrate.Builder) {
+		builder.Integer("event_id", false, true).Nullable(true).After("paid_at").Comment("支付完成后创建的 payment_completed 事件 ID")
+	})
+
+	m.Schema("20240326").Table("payment_records", func(builder *migrate.Builder) {
+		builder.Index("idx_original_transaction_id", "source", "original_transaction_id")
+	})
+}
@@ -19,6 +19,16 @@ func Migrate(ctx context.Context, db *sql.DB) error {
 	m := migrate.NewManager(db).Init(ctx)
 
 	data.Migrate20240221(m)
+	data.Migrate20240301(m)
+	data.Migrate20240305(m)
+	data.Migrate20240310(m)
+	data.Migrate20240312(m)
+	data.Migrate20240315(m)
+	data.Migrate20240318(m)
+	data.Migrate20240320(m)
+	data.Migrate20240322(m)
+	data.Migrate20240324(m)
+	data.Migrate20240326(m)
 
 	return m.Run(ctx)
 }
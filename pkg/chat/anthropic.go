@@ -0,0 +1,388 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// anthropicDefaultMaxTokens max_tokens is required by the Anthropic Messages API
+const anthropicDefaultMaxTokens = 4096
+
+// AnthropicBackend Anthropic Messages API chat backend
+type AnthropicBackend struct {
+	serverURL string
+	apiKey    string
+	client    *http.Client
+}
+
+// NewAnthropicBackend create a new AnthropicBackend
+func NewAnthropicBackend(serverURL, apiKey string, pp *proxy.Proxy) *AnthropicBackend {
+	return &AnthropicBackend{
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		apiKey:    apiKey,
+		client:    &http.Client{Timeout: 180 * time.Second, Transport: pp.BuildTransport()},
+	}
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	MaxTokens int                `json:"max_tokens"`
+	Stream    bool               `json:"stream"`
+
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+// anthropicToolChoice auto/any/none, or tool with the name of the tool
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type   string                `json:"type"`
+	Text   string                `json:"text,omitempty"`
+	Source *anthropicImageSource `json:"source,omitempty"`
+
+	// ID, Name, Input are used by tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// ToolUseID, Content are used by tool_result blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		ID    string         `json:"id"`
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	// Index the index of the content block, in content_block_start/content_block_delta/content_block_stop events
+	Index        int                   `json:"index"`
+	ContentBlock anthropicContentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	// Usage the cumulative output tokens, only in message_delta event
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// anthropicUsage input tokens are returned in message_start, output tokens are returned in message_delta
+type anthropicUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+// anthropicFinishReason convert Anthropic stop reason to OpenAI finish reason
+func anthropicFinishReason(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	default:
+		return "stop"
+	}
+}
+
+// buildAnthropicRequest 系统消息合并到 system 字段，连续的相同角色消息合并为一条消息，
+// 助手消息中的工具调用转换为 tool_use 内容块，工具消息转换为用户消息中的 tool_result 内容块
+func buildAnthropicRequest(req BackendRequest) anthropicRequest {
+	ar := anthropicRequest{
+		Model:     req.Model.ID,
+		MaxTokens: req.MaxTokens,
+		Stream:    true,
+	}
+
+	if ar.MaxTokens <= 0 {
+		ar.MaxTokens = anthropicDefaultMaxTokens
+	}
+
+	var systems []string
+	var usedTools []string
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			systems = append(systems, msg.Content)
+			continue
+		}
+
+		role := msg.Role
+		blocks := make([]anthropicContentBlock, 0)
+		if msg.Role == "tool" {
+			// Anthropic 没有 tool 角色，工具执行结果作为用户消息返回
+			role = "user"
+			blocks = append(blocks, anthropicContentBlock{Type: "tool_result", ToolUseID: msg.ToolCallID, Content: msg.TextContent()})
+		} else if len(msg.MultipartContents) > 0 {
+			for _, content := range msg.MultipartContents {
+				if content.ImageURL != nil && content.ImageURL.URL != "" {
+					if !req.Model.SupportVision() {
+						continue
+					}
+
+					if strings.HasPrefix(content.ImageURL.URL, "data:") {
+						_, mimeType, err := misc.DecodeBase64ImageWithMime(content.ImageURL.URL)
+						if err != nil {
+							continue
+						}
+
+						blocks = append(blocks, anthropicContentBlock{
+							Type:   "image",
+							Source: &anthropicImageSource{Type: "base64", MediaType: mimeType, Data: misc.RemoveImageBase64Prefix(content.ImageURL.URL)},
+						})
+					} else {
+						blocks = append(blocks, anthropicContentBlock{
+							Type:   "image",
+							Source: &anthropicImageSource{Type: "url", URL: content.ImageURL.URL},
+						})
+					}
+
+					continue
+				}
+
+				if content.Text != "" {
+					blocks = append(blocks, anthropicContentBlock{Type: "text", Text: content.Text})
+				}
+			}
+		} else if msg.Content != "" {
+			blocks = append(blocks, anthropicContentBlock{Type: "text", Text: msg.Content})
+		}
+
+		for _, call := range msg.ToolCalls {
+			usedTools = append(usedTools, call.Function.Name)
+			blocks = append(blocks, anthropicContentBlock{
+				Type:  "tool_use",
+				ID:    call.ID,
+				Name:  call.Function.Name,
+				Input: anthropicToolInput(call.Function.Arguments),
+			})
+		}
+
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if len(ar.Messages) > 0 && ar.Messages[len(ar.Messages)-1].Role == role {
+			ar.Messages[len(ar.Messages)-1].Content = append(ar.Messages[len(ar.Messages)-1].Content, blocks...)
+			continue
+		}
+
+		ar.Messages = append(ar.Messages, anthropicMessage{Role: role, Content: blocks})
+	}
+
+	ar.System = strings.Join(systems, "\n\n")
+	ar.Tools = buildAnthropicTools(req.Tools, usedTools)
+	if len(ar.Tools) > 0 {
+		ar.ToolChoice = anthropicToolChoiceFor(req)
+	}
+
+	return ar
+}
+
+// anthropicToolChoiceFor 转换 tool_choice，请求中没有工具时（工具只来自历史消息中的 tool_use 内容块），不允许模型调用工具
+func anthropicToolChoiceFor(req BackendRequest) *anthropicToolChoice {
+	if len(req.Tools) == 0 {
+		return &anthropicToolChoice{Type: "none"}
+	}
+
+	switch mode, name := req.toolChoice(); mode {
+	case toolChoiceNone:
+		return &anthropicToolChoice{Type: "none"}
+	case toolChoiceRequired:
+		return &anthropicToolChoice{Type: "any"}
+	case toolChoiceFunction:
+		return &anthropicToolChoice{Type: "tool", Name: name}
+	default:
+		return &anthropicToolChoice{Type: "auto"}
+	}
+}
+
+// anthropicToolInput tool_use 的 input 必须是 JSON 对象，模型生成的参数无效时使用空对象
+func anthropicToolInput(arguments string) json.RawMessage {
+	var input map[string]any
+	if err := json.Unmarshal([]byte(arguments), &input); err != nil || input == nil {
+		return json.RawMessage("{}")
+	}
+
+	return json.RawMessage(arguments)
+}
+
+// buildAnthropicTools 转换请求中的工具定义，历史消息中调用过但是请求中没有定义的工具使用空的参数定义补充
+func buildAnthropicTools(tools []Tool, used []string) []anthropicTool {
+	res := make([]anthropicTool, 0, len(tools))
+	defined := make(map[string]bool)
+	for _, tool := range tools {
+		if tool.Function == nil {
+			continue
+		}
+
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+
+		defined[tool.Function.Name] = true
+		res = append(res, anthropicTool{Name: tool.Function.Name, Description: tool.Function.Description, InputSchema: schema})
+	}
+
+	for _, name := range used {
+		if !defined[name] {
+			defined[name] = true
+			res = append(res, anthropicTool{Name: name, InputSchema: map[string]any{"type": "object"}})
+		}
+	}
+
+	return res
+}
+
+// ChatStream implements Backend
+func (backend *AnthropicBackend) ChatStream(ctx context.Context, req BackendRequest) (<-chan StreamResponse, error) {
+	body, err := json.Marshal(buildAnthropicRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, backend.serverURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", backend.apiKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := backend.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &BackendError{StatusCode: resp.StatusCode, Message: string(data)}
+	}
+
+	res := make(chan StreamResponse)
+	go func() {
+		defer func() {
+			close(res)
+			_ = resp.Body.Close()
+		}()
+
+		send := func(data StreamResponse) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case res <- data:
+				return data.ErrorCode == ""
+			}
+		}
+
+		var id string
+		var inputTokens int64
+		// 内容块索引 => 工具调用索引，工具调用的参数通过 input_json_delta 增量返回
+		toolIndexes := make(map[int]int)
+		toolHasInput := make(map[int]bool)
+		err := readServerSentEvents(resp.Body, func(_ string, data string) bool {
+			var evt anthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &evt); err != nil {
+				return send(StreamResponse{ErrorCode: "READ_STREAM_FAILED", ErrorMessage: fmt.Sprintf("invalid stream event: %v", err)})
+			}
+
+			switch evt.Type {
+			case "message_start":
+				id = evt.Message.ID
+				inputTokens = evt.Message.Usage.InputTokens
+			case "content_block_start":
+				if evt.ContentBlock.Type == "tool_use" {
+					toolIndexes[evt.Index] = len(toolIndexes)
+					return send(newToolCallStreamResponse(id, ToolCall{
+						Index:    toolIndexes[evt.Index],
+						ID:       evt.ContentBlock.ID,
+						Type:     "function",
+						Function: Function{Name: evt.ContentBlock.Name},
+					}))
+				}
+			case "content_block_delta":
+				if evt.Delta.Type == "input_json_delta" {
+					toolIndex, ok := toolIndexes[evt.Index]
+					if !ok || evt.Delta.PartialJSON == "" {
+						return true
+					}
+
+					toolHasInput[evt.Index] = true
+					return send(newToolCallStreamResponse(id, ToolCall{Index: toolIndex, Function: Function{Arguments: evt.Delta.PartialJSON}}))
+				}
+
+				if evt.Delta.Text != "" {
+					return send(NewStreamResponse(id, evt.Delta.Text, ""))
+				}
+			case "content_block_stop":
+				// 没有参数的工具调用不会返回 input_json_delta，参数使用空对象
+				if toolIndex, ok := toolIndexes[evt.Index]; ok && !toolHasInput[evt.Index] {
+					return send(newToolCallStreamResponse(id, ToolCall{Index: toolIndex, Function: Function{Arguments: "{}"}}))
+				}
+			case "message_delta":
+				if reason := anthropicFinishReason(evt.Delta.StopReason); reason != "" {
+					resp := NewStreamResponse(id, "", reason)
+					resp.Usage = &Usage{
+						PromptTokens:     inputTokens,
+						CompletionTokens: evt.Usage.OutputTokens,
+						TotalTokens:      inputTokens + evt.Usage.OutputTokens,
+					}
+
+					return send(resp)
+				}
+			case "message_stop":
+				return false
+			case "error":
+				return send(StreamResponse{ErrorCode: evt.Error.Type, ErrorMessage: evt.Error.Message})
+			}
+
+			return true
+		})
+		if err != nil && ctx.Err() == nil {
+			send(StreamResponse{ErrorCode: "READ_STREAM_FAILED", ErrorMessage: fmt.Sprintf("read stream failed: %v", err)})
+		}
+	}()
+
+	return res, nil
+}
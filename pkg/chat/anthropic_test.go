@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestAnthropicBackend_ChatStreamToolUse(t *testing.T) {
+	srv := sseServer(t,
+		`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":10}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me check."}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":""}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_2","name":"now","input":{}}}`,
+		`{"type":"content_block_stop","index":2}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+		`{"type":"message_stop"}`,
+	)
+
+	backend := &AnthropicBackend{serverURL: srv.URL, client: srv.Client()}
+	stream, err := backend.ChatStream(context.Background(), BackendRequest{Model: estimateModel})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, calls, finishReason := readStream(t, stream)
+	if text != "Let me check." {
+		t.Errorf("unexpected text: %q", text)
+	}
+
+	if finishReason != "tool_calls" {
+		t.Errorf("expect finish reason tool_calls, got %q", finishReason)
+	}
+
+	want := []ToolCall{
+		{Index: 0, ID: "toolu_1", Type: "function", Function: Function{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		{Index: 1, ID: "toolu_2", Type: "function", Function: Function{Name: "now", Arguments: "{}"}},
+	}
+	if got, _ := json.Marshal(calls); string(got) != string(mustJSON(want)) {
+		t.Errorf("unexpected tool calls: %s", got)
+	}
+}
+
+func TestBuildAnthropicRequest_ToolChoice(t *testing.T) {
+	tools := []Tool{{Type: "function", Function: &FunctionDefinition{Name: "get_weather", Parameters: map[string]any{"type": "object"}}}}
+
+	testCases := []struct {
+		tools  []Tool
+		choice any
+		want   string
+	}{
+		{tools: tools, choice: nil, want: `{"type":"auto"}`},
+		{tools: tools, choice: "auto", want: `{"type":"auto"}`},
+		{tools: tools, choice: "none", want: `{"type":"none"}`},
+		{tools: tools, choice: "required", want: `{"type":"any"}`},
+		{tools: tools, choice: map[string]any{"type": "function", "function": map[string]any{"name": "get_weather"}}, want: `{"type":"tool","name":"get_weather"}`},
+		// 工具只来自历史消息时，不允许模型调用工具
+		{tools: nil, choice: nil, want: `{"type":"none"}`},
+	}
+
+	messages := Messages{
+		{Role: "user", Content: "weather?"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "toolu_1", Type: "function", Function: Function{Name: "get_weather", Arguments: `{"city":"Paris"}`}}}},
+		{Role: "tool", ToolCallID: "toolu_1", Content: "sunny"},
+	}
+
+	for _, tc := range testCases {
+		ar := buildAnthropicRequest(BackendRequest{Model: estimateModel, Messages: messages, Tools: tc.tools, ToolChoice: tc.choice})
+		if got := string(mustJSON(ar.ToolChoice)); got != tc.want {
+			t.Errorf("%v: expect %s, got %s", tc.choice, tc.want, got)
+		}
+
+		if len(ar.Messages) != 3 || ar.Messages[1].Content[0].Type != "tool_use" || ar.Messages[2].Content[0].Type != "tool_result" {
+			t.Errorf("unexpected messages: %s", mustJSON(ar.Messages))
+		}
+	}
+}
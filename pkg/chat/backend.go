@@ -0,0 +1,124 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
+	"io"
+	"strings"
+)
+
+// Backend 模型服务提供商，不同的服务提供商使用不同的协议，统一转换为 StreamResponse 返回
+//
+// 返回的 StreamResponse 只需要包含增量内容；上游返回了 Token 使用量时，通过 Usage 字段返回（可以是不包含 Choices 的单独分片），
+// Chatter 优先使用上游返回的使用量，没有返回时使用本地计算的结果；
+// 请求过程中出错时，返回包含 ErrorCode 的 StreamResponse 后关闭 channel
+type Backend interface {
+	// ChatStream initiate a streaming chat request
+	ChatStream(ctx context.Context, req BackendRequest) (<-chan StreamResponse, error)
+}
+
+// BackendRequest the request sent to the Backend
+type BackendRequest struct {
+	// Model the model used for the request
+	Model config.Model
+	// Messages the conversation context, including the system prompt
+	Messages Messages
+	// MaxTokens the maximum number of tokens to generate, 0 means unlimited
+	MaxTokens int
+
+	// Tools the tools the model may call, backends that do not support tool calling ignore it
+	Tools      []Tool
+	ToolChoice any
+}
+
+const (
+	toolChoiceAuto     = "auto"
+	toolChoiceNone     = "none"
+	toolChoiceRequired = "required"
+	toolChoiceFunction = "function"
+)
+
+// toolChoice 解析 OpenAI 格式的 tool_choice，返回模式（auto/none/required/function）以及指定的函数名称，没有指定时模式为空
+func (req BackendRequest) toolChoice() (mode string, name string) {
+	switch choice := req.ToolChoice.(type) {
+	case nil:
+		return "", ""
+	case string:
+		switch choice {
+		case toolChoiceNone, toolChoiceRequired:
+			return choice, ""
+		default:
+			return toolChoiceAuto, ""
+		}
+	}
+
+	// {"type": "function", "function": {"name": "my_function"}}
+	var named struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+
+	data, _ := json.Marshal(req.ToolChoice)
+	if err := json.Unmarshal(data, &named); err != nil || named.Function.Name == "" {
+		return toolChoiceAuto, ""
+	}
+
+	return toolChoiceFunction, named.Function.Name
+}
+
+// NewBackend create a Backend for the specified channel
+func NewBackend(ch config.Channel, pp *proxy.Proxy) (Backend, error) {
+	switch ch.Type {
+	case config.ChannelTypeOpenAI:
+		return NewOpenAIBackend(NewOpenAIClient(ch.OpenAIConfig(), pp)), nil
+	case config.ChannelTypeAnthropic:
+		return NewAnthropicBackend(ch.ServerURL, ch.APIKey, pp), nil
+	case config.ChannelTypeGemini:
+		return NewGeminiBackend(ch.ServerURL, ch.APIKey, pp), nil
+	default:
+		return nil, fmt.Errorf("unsupported channel type: %s", ch.Type)
+	}
+}
+
+// readServerSentEvents 读取 SSE 响应，每个事件调用一次 cb，cb 返回 false 时停止读取
+func readServerSentEvents(body io.Reader, cb func(event string, data string) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				if !cb(event, strings.Join(data, "\n")) {
+					return nil
+				}
+			}
+
+			event, data = "", nil
+			continue
+		}
+
+		if strings.HasPrefix(line, "event:") {
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		} else if strings.HasPrefix(line, "data:") {
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(data) > 0 {
+		cb(event, strings.Join(data, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// sseServer 返回固定的 SSE 响应
+func sseServer(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, evt := range events {
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", evt)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// readStream 读取流中的所有分片，返回回复内容、合并后的工具调用以及结束原因
+func readStream(t *testing.T, stream <-chan StreamResponse) (string, []ToolCall, string) {
+	t.Helper()
+
+	var text, finishReason string
+	calls := make([]ToolCall, 0)
+	for resp := range stream {
+		if resp.ErrorCode != "" {
+			t.Fatalf("unexpected stream error: %s %s", resp.ErrorCode, resp.ErrorMessage)
+		}
+
+		for _, choice := range resp.Choices {
+			text += choice.Delta.Content
+			calls = mergeToolCalls(calls, choice.Delta.ToolCalls)
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+
+	return text, calls, finishReason
+}
+
+func mustJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func TestBackendRequest_ToolChoice(t *testing.T) {
+	testCases := []struct {
+		choice   any
+		wantMode string
+		wantName string
+	}{
+		{choice: nil, wantMode: ""},
+		{choice: "auto", wantMode: toolChoiceAuto},
+		{choice: "none", wantMode: toolChoiceNone},
+		{choice: "required", wantMode: toolChoiceRequired},
+		{choice: map[string]any{"type": "function", "function": map[string]any{"name": "f"}}, wantMode: toolChoiceFunction, wantName: "f"},
+		{choice: map[string]any{"type": "function"}, wantMode: toolChoiceAuto},
+	}
+
+	for _, tc := range testCases {
+		mode, name := BackendRequest{ToolChoice: tc.choice}.toolChoice()
+		if mode != tc.wantMode || name != tc.wantName {
+			t.Errorf("%v: expect %s/%s, got %s/%s", tc.choice, tc.wantMode, tc.wantName, mode, name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// StreamBufferTTL 回复缓冲区的有效期，客户端需要在该时间内重新连接
+const StreamBufferTTL = 10 * time.Minute
+
+// ErrStreamBufferNotFound the stream buffer does not exist or has expired
+var ErrStreamBufferNotFound = errors.New("stream buffer not found or expired")
+
+// StreamBuffer 回复缓冲区，使用 Redis 保存每个回复已经生成的分片
+//
+// 客户端断开连接后，服务端继续生成回复并写入缓冲区，客户端重新连接后可以从最后收到的分片继续读取
+type StreamBuffer struct {
+	rds *redis.Client
+}
+
+// NewStreamBuffer create a new StreamBuffer
+func NewStreamBuffer(rds *redis.Client) *StreamBuffer {
+	return &StreamBuffer{rds: rds}
+}
+
+func streamBufferKey(answerID int64) string {
+	return fmt.Sprintf("chat:stream:%d", answerID)
+}
+
+func streamBufferOwnerKey(answerID int64) string {
+	return fmt.Sprintf("chat:stream:%d:owner", answerID)
+}
+
+func streamBufferDoneKey(answerID int64) string {
+	return fmt.Sprintf("chat:stream:%d:done", answerID)
+}
+
+// Create 创建回复缓冲区，只有回复所属的用户可以读取
+func (buf *StreamBuffer) Create(ctx context.Context, answerID int64, userID int64) error {
+	return buf.rds.Set(ctx, streamBufferOwnerKey(answerID), userID, StreamBufferTTL).Err()
+}
+
+// Append 追加一个分片，分片的序号从 0 开始
+func (buf *StreamBuffer) Append(ctx context.Context, answerID int64, chunk string) error {
+	key := streamBufferKey(answerID)
+
+	pipe := buf.rds.TxPipeline()
+	pipe.RPush(ctx, key, chunk)
+	pipe.Expire(ctx, key, StreamBufferTTL)
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+// Finish 标记回复已经生成完成，读取完所有分片后不再需要等待
+func (buf *StreamBuffer) Finish(ctx context.Context, answerID int64) error {
+	return buf.rds.Set(ctx, streamBufferDoneKey(answerID), 1, StreamBufferTTL).Err()
+}
+
+// Owner 获取回复缓冲区所属的用户
+func (buf *StreamBuffer) Owner(ctx context.Context, answerID int64) (int64, error) {
+	owner, err := buf.rds.Get(ctx, streamBufferOwnerKey(answerID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrStreamBufferNotFound
+		}
+
+		return 0, err
+	}
+
+	return strconv.ParseInt(owner, 10, 64)
+}
+
+// Read 读取从 from 开始的所有分片，done 表示回复已经生成完成，返回的分片之后不会再有新的分片
+func (buf *StreamBuffer) Read(ctx context.Context, answerID int64, from int64) (chunks []string, done bool, err error) {
+	// 先检查是否完成再读取分片，保证完成前写入的分片都能被读取到
+	exist, err := buf.rds.Exists(ctx, streamBufferDoneKey(answerID)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	chunks, err = buf.rds.LRange(ctx, streamBufferKey(answerID), from, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return chunks, exist > 0, nil
+}
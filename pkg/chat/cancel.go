@@ -0,0 +1,90 @@
+package chat
+
+import (
+	"context"
+	"github.com/mylxsw/asteria/log"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"sync"
+)
+
+// cancelChannel 取消请求广播使用的 Redis 频道
+const cancelChannel = "chat:cancel"
+
+// Canceller 取消正在生成的回复
+//
+// 回复可能在其它实例上生成，取消请求通过 Redis 广播到所有实例，由正在生成该回复的实例执行取消
+type Canceller struct {
+	rds *redis.Client
+
+	lock    sync.Mutex
+	cancels map[int64]context.CancelFunc
+}
+
+// NewCanceller create a new Canceller
+func NewCanceller(rds *redis.Client) *Canceller {
+	return &Canceller{rds: rds, cancels: make(map[int64]context.CancelFunc)}
+}
+
+// Register 注册正在生成的回复，返回的函数用于在生成结束后取消注册
+func (c *Canceller) Register(answerID int64, cancel context.CancelFunc) (unregister func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.cancels[answerID] = cancel
+
+	return func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		delete(c.cancels, answerID)
+	}
+}
+
+// Cancel 取消正在生成的回复，回复不在当前实例上生成时，广播给其它实例
+func (c *Canceller) Cancel(ctx context.Context, answerID int64) error {
+	if c.cancelLocal(answerID) {
+		return nil
+	}
+
+	return c.rds.Publish(ctx, cancelChannel, answerID).Err()
+}
+
+// cancelLocal 取消当前实例上生成的回复，回复不在当前实例上生成时返回 false
+func (c *Canceller) cancelLocal(answerID int64) bool {
+	c.lock.Lock()
+	cancel, ok := c.cancels[answerID]
+	c.lock.Unlock()
+
+	if ok {
+		cancel()
+	}
+
+	return ok
+}
+
+// Listen 监听其它实例广播的取消请求，直到 ctx 结束
+func (c *Canceller) Listen(ctx context.Context) {
+	sub := c.rds.Subscribe(ctx, cancelChannel)
+	defer func() { _ = sub.Close() }()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			answerID, err := strconv.ParseInt(msg.Payload, 10, 64)
+			if err != nil {
+				log.F(log.M{"payload": msg.Payload}).Warningf("invalid chat cancel message: %s", err)
+				continue
+			}
+
+			c.cancelLocal(answerID)
+		}
+	}
+}
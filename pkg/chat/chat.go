@@ -2,25 +2,41 @@ package chat
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/internal/coins"
+	"github.com/mylxsw/aidea-chat-server/pkg/knowledge"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
 	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/aidea-chat-server/pkg/tools"
 	"github.com/mylxsw/asteria/log"
 	"github.com/mylxsw/glacier/infra"
 	"github.com/mylxsw/go-utils/array"
+	"github.com/redis/go-redis/v9"
 	"github.com/sashabaranov/go-openai"
+	"net"
 	"strings"
 	"time"
 )
 
 // Chatter represents a chat completion API
 type Chatter struct {
-	conf   *config.Config   `autowire:"@"`
-	openai *OpenAIClient    `autowire:"@"`
-	repo   *repo.Repository `autowire:"@"`
+	conf  *config.Config   `autowire:"@"`
+	proxy *proxy.Proxy     `autowire:"@"`
+	repo  *repo.Repository `autowire:"@"`
+	tools *tools.Registry  `autowire:"@"`
+
+	knowledge *knowledge.Knowledge `autowire:"@"`
+	rds       *redis.Client        `autowire:"@"`
 
 	// models model_id => model mapping
 	models map[string]config.Model
+	// backends channel name => backend mapping
+	backends map[string]Backend
+	// summaryCache the cache of conversation summaries used by the summarize context strategy
+	summaryCache SummaryCache
 }
 
 // NewChatter creates a new Chatter instance
@@ -32,75 +48,362 @@ func NewChatter(resolver infra.Resolver) *Chatter {
 		return item.ID
 	})
 
+	chatter.backends = make(map[string]Backend)
+	for _, ch := range chatter.conf.Channels {
+		backend, err := NewBackend(ch, chatter.proxy)
+		if err != nil {
+			log.F(log.M{"channel": ch.Name}).Errorf("create chat backend failed: %s", err)
+			continue
+		}
+
+		chatter.backends[ch.Name] = backend
+	}
+
+	chatter.summaryCache = NewRedisSummaryCache(chatter.rds)
+
 	return chatter
 }
 
-// ChatStream for handling streaming chat requests
-func (chat *Chatter) ChatStream(ctx context.Context, req Request) (<-chan StreamResponse, error) {
-	// 根据 robotID 获取对应的模型
-	robot, err := chat.repo.Robot.GetRobotByID(ctx, req.RobotID)
+// Model returns the configuration of the specified model
+func (chat *Chatter) Model(modelID string) (config.Model, bool) {
+	model, ok := chat.models[modelID]
+	return model, ok
+}
+
+// resolveRobot 根据 robotID 获取对应的机器人以及模型配置
+func (chat *Chatter) resolveRobot(ctx context.Context, userID int64, robotID string) (*repo.Robot, config.Model, error) {
+	robot, err := chat.repo.Robot.GetUserRobot(ctx, userID, robotID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid robot: %w", err)
+		return nil, config.Model{}, fmt.Errorf("invalid robot: %w", err)
 	}
 
-	// TODO 根据机器人的类型进行不同的处理
-	if robot.Type != repo.RobotTypeModelDriven {
-		return nil, fmt.Errorf("unsupported robot type: %x", robot.Type)
+	if robot.Type != repo.RobotTypeModelDriven && robot.Type != repo.RobotTypeCustomServer {
+		return nil, config.Model{}, fmt.Errorf("unsupported robot type: %x", robot.Type)
 	}
 
-	// 查询模型配置信息
+	// 查询模型配置信息，对于自定义服务器的机器人，模型用于 Token 计算以及计费
 	model, ok := chat.models[robot.Model]
 	if !ok {
-		return nil, fmt.Errorf("model not found: %s", robot.Model)
+		return nil, config.Model{}, fmt.Errorf("model not found: %s", robot.Model)
 	}
 
-	// 确保上下文长度满足要求
-	req.Messages, _, err = ReduceContextByTokens(req.Messages, model.ID, model.MaxContextForInput())
+	return robot, model, nil
+}
+
+// contextReducer 根据机器人或者模型的配置选择上下文裁剪策略，机器人的配置优先
+func (chat *Chatter) contextReducer(robot *repo.Robot, model config.Model) ContextReducer {
+	conf := model.Context
+	if robot.RobotMeta.Context != nil && robot.RobotMeta.Context.Strategy != "" {
+		conf = *robot.RobotMeta.Context
+	}
+
+	switch conf.Strategy {
+	case config.ContextStrategyDropMiddle:
+		return DropMiddleReducer{}
+	case config.ContextStrategyCount:
+		return CountReducer{MaxTurns: conf.MaxTurns}
+	case config.ContextStrategySummarize:
+		return SummarizeReducer{
+			Summarizer:       chat.summarize,
+			Cache:            chat.summaryCache,
+			MaxSummaryTokens: chat.conf.ContextSummary.MaxTokens,
+		}
+	default:
+		return TruncateReducer{}
+	}
+}
+
+// reduceContext 确保上下文长度满足模型要求，机器人的系统提示语始终保留在会话的最前面
+func reduceContext(ctx context.Context, reducer ContextReducer, robot *repo.Robot, conversationID int64, messages Messages, model config.Model) (Messages, error) {
+	maxTokens := model.MaxContextForInput()
+
+	var prompt Messages
+	if strings.TrimSpace(robot.Prompt) != "" {
+		prompt = Messages{{Role: "system", Content: robot.Prompt}}
+		promptTokenCount, err := MessageTokenCount(prompt, model)
+		if err != nil {
+			return nil, err
+		}
+
+		maxTokens -= promptTokenCount
+	}
+
+	reduced, err := reducer.Reduce(ctx, ReduceRequest{
+		ConversationID: conversationID,
+		Messages:       messages,
+		Model:          model,
+		MaxTokens:      maxTokens,
+	})
 	if err != nil {
 		return nil, ErrContextExceedLimit
 	}
 
-	promptTokenCount, _ := MessageTokenCount(req.Messages, model.ID)
-	usage := Usage{
-		PromptTokens: int64(promptTokenCount),
-	}
-
-	// create openai request
-	openaiRequest := openai.ChatCompletionRequest{
-		Model:  model.ID,
-		Stream: true,
-		Messages: array.Map(req.Messages, func(item Message, _ int) openai.ChatCompletionMessage {
-			// If the model supports vision, the message content needs to be converted into the corresponding format
-			if model.SupportVision() {
-				contents := array.Map(item.MultipartContents, func(content *MultipartContent, _ int) openai.ChatMessagePart {
-					part := openai.ChatMessagePart{Text: content.Text, Type: openai.ChatMessagePartType(content.Type)}
-					if openai.ChatMessagePartType(content.Type) == openai.ChatMessagePartTypeImageURL {
-						url := content.ImageURL.URL
-						part.ImageURL = &openai.ChatMessageImageURL{
-							URL:    url,
-							Detail: openai.ImageURLDetail(content.ImageURL.Detail),
-						}
-					}
+	return append(prompt, reduced...), nil
+}
 
-					return part
-				})
+// summaryTimeout 生成上下文摘要的超时时间
+const summaryTimeout = 30 * time.Second
 
-				return openai.ChatCompletionMessage{
-					Role:         item.Role,
-					MultiContent: contents,
-				}
-			}
+// summarize 使用配置的摘要模型总结对话内容，实现 Summarizer
+func (chat *Chatter) summarize(ctx context.Context, previous string, messages Messages) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, summaryTimeout)
+	defer cancel()
 
-			return openai.ChatCompletionMessage{
-				Role:    item.Role,
-				Content: item.Content,
-			}
-		}),
+	summary, err := chat.complete(ctx, chat.conf.ContextSummary.Model, buildSummaryMessages(previous, messages), chat.conf.ContextSummary.MaxTokens)
+	if err != nil {
+		return "", err
+	}
+
+	if summary == "" {
+		return "", errors.New("summary is empty")
+	}
+
+	return summary, nil
+}
+
+// titlePrompt 生成会话标题使用的系统提示
+const titlePrompt = "Generate a short title for the conversation below in the language used by the user, " +
+	"no more than 10 words. Reply with the title only, without quotes or trailing punctuation."
+
+// TitleContextLength 生成会话标题时，提问以及回复最多使用的字符数
+const TitleContextLength = 1000
+
+// GenerateTitle 使用配置的标题模型为对话生成简短的标题
+func (chat *Chatter) GenerateTitle(ctx context.Context, question, answer string) (string, error) {
+	content := fmt.Sprintf("user: %s\n\nassistant: %s", misc.SubString(question, TitleContextLength), misc.SubString(answer, TitleContextLength))
+
+	title, err := chat.complete(ctx, chat.conf.ConversationTitle.Model, Messages{
+		{Role: "system", Content: titlePrompt},
+		{Role: "user", Content: content},
+	}, chat.conf.ConversationTitle.MaxTokens)
+	if err != nil {
+		return "", err
+	}
+
+	title = strings.Trim(title, "\"'“”‘’《》「」 \n")
+	if title == "" {
+		return "", errors.New("title is empty")
+	}
+
+	return title, nil
+}
+
+// complete 直接使用模型的主渠道完成一次对话，用于摘要、标题等内部任务，不经过机器人、工具以及计费
+func (chat *Chatter) complete(ctx context.Context, modelID string, messages Messages, maxTokens int) (string, error) {
+	model, ok := chat.models[modelID]
+	if !ok {
+		return "", fmt.Errorf("model not found: %s", modelID)
+	}
+
+	backend, ok := chat.backends[model.Channel]
+	if !ok {
+		return "", fmt.Errorf("channel not found: %s", model.Channel)
+	}
+
+	stream, err := backend.ChatStream(ctx, BackendRequest{
+		Model:     model,
+		Messages:  messages,
+		MaxTokens: maxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var content strings.Builder
+	for data := range stream {
+		if data.ErrorCode != "" {
+			return "", fmt.Errorf("[%s] %s", data.ErrorCode, data.ErrorMessage)
+		}
+
+		content.WriteString(data.DeltaText())
+	}
+
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	return strings.TrimSpace(content.String()), nil
+}
+
+// defaultEstimateCompletionTokens the number of completion tokens used for estimation when max_tokens is not specified
+const defaultEstimateCompletionTokens = 1000
+
+// EstimateQuota 预估本次请求需要消耗的智慧果数量
+func (chat *Chatter) EstimateQuota(ctx context.Context, req Request) (int64, error) {
+	robot, model, err := chat.resolveRobot(ctx, req.UserID, req.RobotID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 预估时不生成摘要，按照丢弃最早消息的方式计算
+	messages, err := reduceContext(ctx, TruncateReducer{}, robot, req.ConversationID, req.Messages, model)
+	if err != nil {
+		return 0, err
+	}
+
+	promptTokenCount, err := MessageTokenCount(messages, model)
+	if err != nil {
+		return 0, fmt.Errorf("count message tokens failed: %w", err)
+	}
+
+	promptTokenCount += ToolsTokenCount(toolDefinitions(req.Tools, chat.serverTools(robot)), model)
+
+	completionTokenCount := req.MaxTokens
+	if completionTokenCount <= 0 {
+		completionTokenCount = defaultEstimateCompletionTokens
+	}
+
+	return coins.GetTextModelCoins(model, int64(promptTokenCount+completionTokenCount)), nil
+}
+
+// channelBackend a backend with the name of the channel it belongs to
+type channelBackend struct {
+	channel string
+	backend Backend
+}
+
+// channelBackends 获取模型对应的服务提供商列表，第一个为主渠道，其余为备用渠道
+// 自定义服务器的机器人直接使用机器人的服务器
+func (chat *Chatter) channelBackends(robot *repo.Robot, model config.Model, preferBackup bool) ([]channelBackend, error) {
+	if robot.Type == repo.RobotTypeCustomServer {
+		// 自定义服务器的机器人，将会话转发到机器人的服务器，要求服务器兼容 OpenAI 的流式接口
+		// 服务器地址由用户提供，只允许访问公网地址，不使用代理
+		return []channelBackend{
+			{
+				channel: "robot:" + robot.RobotID,
+				backend: NewOpenAIBackend(NewPublicOpenAIClient(config.OpenAIConfig{
+					ServerURL:          strings.TrimSuffix(robot.ServerURL, "/"),
+					APIKey:             robot.ServerToken,
+					DisableStreamUsage: robot.RobotMeta.DisableStreamUsage,
+				})),
+			},
+		}, nil
+	}
+
+	channels := make([]string, 0, len(model.BackupChannels)+1)
+	if preferBackup && len(model.BackupChannels) > 0 {
+		// 客户端重试时，优先使用备用渠道
+		channels = append(append(channels, model.BackupChannels...), model.Channel)
+	} else {
+		channels = append(append(channels, model.Channel), model.BackupChannels...)
+	}
+
+	backends := make([]channelBackend, 0, len(channels))
+	for _, ch := range array.Uniq(channels) {
+		if backend, ok := chat.backends[ch]; ok {
+			backends = append(backends, channelBackend{channel: ch, backend: backend})
+		}
+	}
+
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("channel not found: %s", model.Channel)
+	}
+
+	return backends, nil
+}
+
+// shouldFailover 上游服务返回 5xx、请求超时或者读取流失败时，切换到备用渠道
+func shouldFailover(ctx context.Context, err error) bool {
+	// the request is canceled by the client
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var backendErr *BackendError
+	if errors.As(err, &backendErr) {
+		return backendErr.StatusCode >= 500
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode >= 500
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// openStream 依次尝试各个渠道，直到成功发起请求，返回成功的渠道在 backends 中的索引
+func (chat *Chatter) openStream(ctx context.Context, backends []channelBackend, req BackendRequest) (<-chan StreamResponse, int, error) {
+	var lastErr error
+	for i, cb := range backends {
+		stream, err := cb.backend.ChatStream(ctx, req)
+		if err == nil {
+			return stream, i, nil
+		}
+
+		lastErr = err
+		if !shouldFailover(ctx, err) {
+			break
+		}
+
+		log.F(log.M{"channel": cb.channel, "model": req.Model.ID}).Warningf("chat backend failed, try next channel: %s", err)
+	}
+
+	return nil, -1, lastErr
+}
+
+// ChatStream for handling streaming chat requests
+func (chat *Chatter) ChatStream(ctx context.Context, req Request) (<-chan StreamResponse, error) {
+	robot, model, err := chat.resolveRobot(ctx, req.UserID, req.RobotID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 从机器人关联的知识库中检索相关内容，追加到系统提示中
+	refs := chat.searchKnowledge(ctx, robot, req.Messages)
+	if len(refs) > 0 {
+		withKnowledge := *robot
+		withKnowledge.Prompt = strings.TrimSpace(robot.Prompt + "\n\n" + knowledge.BuildPrompt(refs))
+		robot = &withKnowledge
+	}
+
+	// 确保上下文长度满足要求
+	req.Messages, err = reduceContext(ctx, chat.contextReducer(robot, model), robot, req.ConversationID, req.Messages, model)
+	if err != nil {
+		return nil, err
+	}
+
+	// 机器人启用的内置工具由服务端执行，其它工具调用返回给客户端
+	serverTools := chat.serverTools(robot)
+	toolDefs := toolDefinitions(req.Tools, serverTools)
+
+	// 上游没有返回 token 使用量时，使用本地计算的结果，回复内容增量计算
+	tokenizer := TokenizerForModel(model)
+
+	// 工具定义也会占用上下文，计入 prompt tokens
+	toolsTokenCount := ToolsTokenCount(toolDefs, model)
+	promptTokenCount, _ := MessageTokenCount(req.Messages, model)
+	usage := Usage{
+		Model:        model.ID,
+		PromptTokens: int64(promptTokenCount + toolsTokenCount),
+	}
+
+	backends, err := chat.channelBackends(robot, model, FromContext(ctx).PreferBackup)
+	if err != nil {
+		return nil, err
 	}
 
 	startTime := time.Now()
 
-	stream, err := chat.openai.ChatStream(ctx, openaiRequest)
+	backendReq := BackendRequest{
+		Model:      model,
+		Messages:   req.Messages,
+		MaxTokens:  req.MaxTokens,
+		Tools:      toolDefs,
+		ToolChoice: req.ToolChoice,
+	}
+
+	stream, current, err := chat.openStream(ctx, backends, backendReq)
 	if err != nil {
 		if strings.Contains(err.Error(), "content management policy") {
 			log.With(err).Errorf("violation of Azure OpenAI content management policy")
@@ -110,73 +413,377 @@ func (chat *Chatter) ChatStream(ctx context.Context, req Request) (<-chan Stream
 		return nil, err
 	}
 
+	usage.Channel = backends[current].channel
+
 	res := make(chan StreamResponse)
 	go func() {
 		defer close(res)
 
-		replyText := ""
+		// 调用方停止读取后，请求的 context 会被取消，此时不再发送，避免协程阻塞
+		send := func(resp StreamResponse) bool {
+			select {
+			case res <- resp:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// 告知客户端回答引用的知识库内容
+		if len(refs) > 0 && robot.RobotMeta.OriginReference {
+			if !send(NewReferencesStreamResponse(refs)) {
+				return
+			}
+		}
+
+		// 每一轮对应一次模型请求，模型调用内置工具后，会携带工具执行结果发起下一轮请求
+		round := 0
+		roundText := ""
+		// 模型返回的工具调用是分片的，合并完成后作为一个完整的消息返回
+		toolCalls := make([]ToolCall, 0)
+		toolCallsSent := false
+		// 之前轮次的 token 使用量
+		var prevPromptTokens, prevCompletionTokens int64
+		// 当前轮次本地增量计算的 completion tokens，上游没有返回使用量时使用
+		var roundCompletionTokens int64
+		// 当前轮次上游返回的 token 使用量
+		var roundUsage *Usage
+		// 结束分片在上游返回使用量（通常在结束分片之后）后再返回给客户端
+		var finishResp *StreamResponse
+
+		updateUsage := func() {
+			usage.ConsumeInMilli = time.Since(startTime).Milliseconds()
+			if roundUsage != nil && (roundUsage.PromptTokens > 0 || roundUsage.CompletionTokens > 0) {
+				usage.PromptTokens = prevPromptTokens + roundUsage.PromptTokens
+				usage.CompletionTokens = prevCompletionTokens + roundUsage.CompletionTokens
+			} else {
+				usage.PromptTokens = prevPromptTokens + int64(promptTokenCount+toolsTokenCount)
+				usage.CompletionTokens = prevCompletionTokens + roundCompletionTokens
+			}
+
+			usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+		}
+
+		// usageSnapshot 返回使用量的副本，协程会继续更新 usage，发送给调用方的分片不能共享同一个对象
+		usageSnapshot := func() *Usage {
+			u := usage
+			u.Tools = append([]string(nil), usage.Tools...)
+			return &u
+		}
+
+		// 达到最大轮次后，内置工具调用不再执行
+		callServerTools := func() bool {
+			return round+1 < maxToolRounds && !toolCallsSent && isServerToolCalls(toolCalls, serverTools)
+		}
+
+		sendToolCalls := func(resp StreamResponse) StreamResponse {
+			if len(resp.Choices) == 0 {
+				resp.Choices = []StreamChoice{{Delta: Delta{Role: "assistant"}}}
+			}
+
+			resp.Choices[0].Delta.ToolCalls = toolCalls
+			toolCallsSent = true
+			return resp
+		}
+
+		// nextRound 执行模型调用的内置工具，并携带执行结果发起下一轮请求
+		nextRound := func() error {
+			updateUsage()
+			prevPromptTokens, prevCompletionTokens = usage.PromptTokens, usage.CompletionTokens
+
+			messages := append(append(Messages{}, backendReq.Messages...), Message{Role: "assistant", Content: roundText, ToolCalls: toolCalls})
+			for _, call := range toolCalls {
+				messages = append(messages, Message{
+					Role:       "tool",
+					Content:    chat.callTool(ctx, call, res, &usage),
+					ToolCallID: call.ID,
+				})
+			}
+
+			backendReq.Messages = messages
+			// 最后一轮不再提供内置工具，要求模型给出最终答复
+			if round+2 >= maxToolRounds {
+				backendReq.Tools = req.Tools
+				if len(req.Tools) == 0 {
+					backendReq.ToolChoice = nil
+				}
+			}
+
+			next, offset, err := chat.openStream(ctx, backends[current:], backendReq)
+			if err != nil {
+				return err
+			}
+
+			stream, current = next, current+offset
+			usage.Channel = backends[current].channel
+
+			round++
+			roundText, toolCalls, toolCallsSent = "", make([]ToolCall, 0), false
+			roundCompletionTokens, roundUsage, finishResp = 0, nil, nil
+			promptTokenCount, _ = MessageTokenCount(backendReq.Messages, model)
+			toolsTokenCount = ToolsTokenCount(backendReq.Tools, model)
+
+			return nil
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case data, ok := <-stream:
 				if !ok {
+					if callServerTools() {
+						if err := nextRound(); err != nil {
+							log.F(log.M{"model": model.ID, "round": round}).Errorf("chat with tool results failed: %s", err)
+							send(StreamResponse{ErrorCode: "TOOL_ROUND_FAILED", ErrorMessage: err.Error()})
+							return
+						}
+
+						continue
+					}
+
+					if finishResp != nil || (len(toolCalls) > 0 && !toolCallsSent) {
+						resp := NewStreamResponse("", "", "tool_calls")
+						if finishResp != nil {
+							resp = *finishResp
+						}
+
+						if len(toolCalls) > 0 && !toolCallsSent {
+							resp = sendToolCalls(resp)
+						}
+
+						updateUsage()
+						resp.Usage = usageSnapshot()
+						send(resp)
+					}
+
 					return
 				}
 
-				if data.Code != "" {
-					res <- StreamResponse{
-						ErrorMessage: data.ErrorMessage,
-						ErrorCode:    data.Code,
+				if data.ErrorCode != "" {
+					// 当前轮次还没有返回任何内容时，读取流失败可以透明的切换到备用渠道
+					if roundText == "" && len(toolCalls) == 0 && data.ErrorCode == "READ_STREAM_FAILED" && current+1 < len(backends) {
+						log.F(log.M{"channel": backends[current].channel, "model": model.ID}).
+							Warningf("read chat stream failed, try next channel: %s", data.ErrorMessage)
+
+						next, offset, err := chat.openStream(ctx, backends[current+1:], backendReq)
+						if err == nil {
+							stream, current = next, current+1+offset
+							usage.Channel = backends[current].channel
+							continue
+						}
 					}
+
+					send(data)
 					return
 				}
 
+				// 上游返回的使用量是当前轮次的累计值，以最后一次返回的为准
+				if data.Usage != nil {
+					roundUsage = data.Usage
+				}
+
+				// 只包含使用量的分片不返回给客户端
+				if len(data.Choices) == 0 {
+					continue
+				}
+
 				if usage.FirstLetterDelay == 0 {
 					usage.FirstLetterDelay = time.Since(startTime).Milliseconds()
 				}
 
-				resp := StreamResponse{
-					ID:      data.ChatResponse.ID,
-					Created: data.ChatResponse.Created,
-					Choices: []StreamChoice{
-						{
-							Index: 0,
-							Delta: Delta{
-								Content: array.Reduce(
-									data.ChatResponse.Choices,
-									func(carry string, item openai.ChatCompletionStreamChoice) string {
-										return carry + item.Delta.Content
-									},
-									"",
-								),
-								Role: "assistant",
-							},
-							FinishReason: string(data.ChatResponse.Choices[len(data.ChatResponse.Choices)-1].FinishReason),
-						},
-					},
+				resp := data
+				roundText += resp.DeltaText()
+				roundCompletionTokens += int64(tokenizer.Count(resp.DeltaText()))
+
+				hasToolCallDelta := false
+				for i, choice := range resp.Choices {
+					if len(choice.Delta.ToolCalls) > 0 {
+						hasToolCallDelta = true
+						toolCalls = mergeToolCalls(toolCalls, choice.Delta.ToolCalls)
+						for _, call := range choice.Delta.ToolCalls {
+							roundCompletionTokens += int64(tokenizer.Count(call.Function.Name + call.Function.Arguments))
+						}
+
+						resp.Choices[i].Delta.ToolCalls = nil
+					}
 				}
 
-				replyText += resp.DeltaText()
-				tokenCount, _ := MessageTokenCount(
-					append(req.Messages, Message{
-						Role:    "assistant",
-						Content: replyText,
-					}),
-					model.ID,
-				)
-
-				usage.ConsumeInMilli = time.Since(startTime).Milliseconds()
-				if tokenCount > 0 {
-					usage.CompletionTokens = int64(tokenCount - promptTokenCount)
-					usage.TotalTokens = int64(tokenCount)
+				finished := resp.Choices[0].FinishReason != ""
+				if hasToolCallDelta && !finished && resp.DeltaText() == "" {
+					// 工具调用分片不直接返回给客户端
+					continue
 				}
 
-				resp.Usage = &usage
-				res <- resp
+				if finished {
+					if callServerTools() {
+						// 内置工具调用由服务端执行，结束标记不返回给客户端，流结束后发起下一轮请求
+						if resp.DeltaText() != "" {
+							resp.Choices[0].FinishReason = ""
+							updateUsage()
+							resp.Usage = usageSnapshot()
+							if !send(resp) {
+								return
+							}
+						}
+
+						continue
+					}
+
+					// 结束分片等到流结束后，携带最终的使用量返回
+					finishResp = &resp
+					continue
+				}
+
+				updateUsage()
+				resp.Usage = usageSnapshot()
+				if !send(resp) {
+					return
+				}
 			}
 		}
 	}()
 
 	return res, nil
 }
+
+const (
+	// firstResponseTimeout the maximum waiting time for the first response of the chat stream
+	firstResponseTimeout = 60 * time.Second
+	// responseGapTimeout the maximum waiting time between two responses of the chat stream
+	responseGapTimeout = 30 * time.Second
+)
+
+// Chat 非流式聊天，等待模型回复完成后返回完整的结果
+//
+// 出错时同时返回已经生成的部分结果，等待时间过长时返回 ErrResponseGapTimeout，流返回错误时返回 *StreamError
+func (chat *Chatter) Chat(ctx context.Context, req Request) (*Completion, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := chat.ChatStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 提前返回时，继续读取剩余的响应，避免生成响应的协程阻塞
+	defer func() {
+		go func() {
+			for range stream {
+			}
+		}()
+	}()
+
+	comp := &Completion{Message: Message{Role: "assistant"}}
+
+	timer := time.NewTimer(firstResponseTimeout)
+	defer timer.Stop()
+
+	var content strings.Builder
+	defer func() { comp.Message.Content = content.String() }()
+
+	for {
+		select {
+		case <-timer.C:
+			return comp, ErrResponseGapTimeout
+		case <-ctx.Done():
+			return comp, ctx.Err()
+		case res, ok := <-stream:
+			if !ok {
+				return comp, nil
+			}
+
+			timer.Reset(responseGapTimeout)
+
+			if res.ErrorCode != "" {
+				return comp, &StreamError{Code: res.ErrorCode, Message: res.ErrorMessage}
+			}
+
+			if res.Usage != nil {
+				comp.Usage = res.Usage
+			}
+
+			if len(res.References) > 0 {
+				comp.References = res.References
+				continue
+			}
+
+			if res.ToolStep != nil {
+				if res.ToolStep.Status != ToolStepStatusRunning {
+					comp.ToolSteps = append(comp.ToolSteps, *res.ToolStep)
+				}
+
+				continue
+			}
+
+			if comp.ID == "" {
+				comp.ID = res.ID
+			}
+
+			content.WriteString(res.DeltaText())
+			for _, choice := range res.Choices {
+				comp.Message.ToolCalls = append(comp.Message.ToolCalls, choice.Delta.ToolCalls...)
+				if choice.FinishReason != "" {
+					comp.FinishReason = choice.FinishReason
+				}
+			}
+		}
+	}
+}
+
+// searchKnowledge 使用最后一条用户消息检索机器人关联的知识库，检索失败时不影响正常对话
+func (chat *Chatter) searchKnowledge(ctx context.Context, robot *repo.Robot, messages Messages) []knowledge.Reference {
+	if len(robot.RobotMeta.KnowledgeBases) == 0 {
+		return nil
+	}
+
+	var question string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			question = messages[i].TextContent()
+			break
+		}
+	}
+
+	refs, err := chat.knowledge.Search(ctx, robot.RobotMeta.KnowledgeBases, question)
+	if err != nil {
+		log.F(log.M{"robot_id": robot.RobotID, "knowledge_bases": robot.RobotMeta.KnowledgeBases}).Errorf("search knowledge base failed: %s", err)
+		return nil
+	}
+
+	return refs
+}
+
+// mergeToolCalls 将工具调用分片按照 index 合并，参数是增量返回的，需要拼接
+func mergeToolCalls(calls []ToolCall, fragments []ToolCall) []ToolCall {
+	for _, frag := range fragments {
+		idx := -1
+		for i, call := range calls {
+			if call.Index == frag.Index {
+				idx = i
+				break
+			}
+		}
+
+		if idx < 0 {
+			if frag.Type == "" {
+				frag.Type = "function"
+			}
+
+			calls = append(calls, frag)
+			continue
+		}
+
+		if frag.ID != "" {
+			calls[idx].ID = frag.ID
+		}
+
+		if frag.Function.Name != "" {
+			calls[idx].Function.Name = frag.Function.Name
+		}
+
+		calls[idx].Function.Arguments += frag.Function.Arguments
+	}
+
+	return calls
+}
@@ -1,8 +1,32 @@
 package chat
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrContextExceedLimit = errors.New("context length exceeds maximum limit")
 	ErrContentFilter      = errors.New("the request or response content contains sensitive words")
+	ErrResponseGapTimeout = errors.New("waiting time between two responses is too long, forced interruption")
 )
+
+// StreamError the chat stream returned an error frame
+type StreamError struct {
+	Code    string
+	Message string
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+// BackendError the upstream service returned an unexpected status code
+type BackendError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *BackendError) Error() string {
+	return fmt.Sprintf("upstream service returned status %d: %s", e.StatusCode, e.Message)
+}
@@ -0,0 +1,360 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GeminiBackend Google Gemini API chat backend
+type GeminiBackend struct {
+	serverURL string
+	apiKey    string
+	client    *http.Client
+}
+
+// NewGeminiBackend create a new GeminiBackend
+func NewGeminiBackend(serverURL, apiKey string, pp *proxy.Proxy) *GeminiBackend {
+	return &GeminiBackend{
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		apiKey:    apiKey,
+		client:    &http.Client{Timeout: 180 * time.Second, Transport: pp.BuildTransport()},
+	}
+}
+
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	ToolConfig        *geminiToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+type geminiToolConfig struct {
+	FunctionCallingConfig geminiFunctionCallingConfig `json:"functionCallingConfig"`
+}
+
+// geminiFunctionCallingConfig mode is AUTO/ANY/NONE, allowedFunctionNames is only used when mode is ANY
+type geminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	MaxOutputTokens int `json:"maxOutputTokens,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	InlineData       *geminiInlineData       `json:"inlineData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiFunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	Name     string `json:"name"`
+	Response any    `json:"response"`
+}
+
+type geminiInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type geminiStreamResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	// UsageMetadata the cumulative token usage of the request
+	UsageMetadata *struct {
+		PromptTokenCount     int64 `json:"promptTokenCount"`
+		CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+		TotalTokenCount      int64 `json:"totalTokenCount"`
+	} `json:"usageMetadata,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
+}
+
+// geminiFinishReason convert Gemini finish reason to OpenAI finish reason
+func geminiFinishReason(reason string) string {
+	switch reason {
+	case "", "FINISH_REASON_UNSPECIFIED":
+		return ""
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+// buildGeminiRequest 系统消息作为 systemInstruction，连续的相同角色消息合并为一条消息，
+// 助手消息中的工具调用转换为 functionCall，工具消息转换为用户消息中的 functionResponse
+func buildGeminiRequest(req BackendRequest) geminiRequest {
+	gr := geminiRequest{}
+	if req.MaxTokens > 0 {
+		gr.GenerationConfig = &geminiGenerationConfig{MaxOutputTokens: req.MaxTokens}
+	}
+
+	var systemParts []geminiPart
+	// 工具调用 ID => 函数名称，functionResponse 需要使用函数名称关联工具调用
+	toolNames := make(map[string]string)
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			systemParts = append(systemParts, geminiPart{Text: msg.Content})
+			continue
+		}
+
+		parts := make([]geminiPart, 0)
+		if msg.Role == "tool" {
+			if name, ok := toolNames[msg.ToolCallID]; ok {
+				parts = append(parts, geminiPart{FunctionResponse: &geminiFunctionResponse{
+					Name:     name,
+					Response: map[string]any{"content": msg.TextContent()},
+				}})
+			} else if text := msg.TextContent(); text != "" {
+				parts = append(parts, geminiPart{Text: text})
+			}
+		} else if len(msg.MultipartContents) > 0 {
+			for _, content := range msg.MultipartContents {
+				if content.ImageURL != nil && content.ImageURL.URL != "" {
+					// Gemini 只支持内联的图片数据
+					if !req.Model.SupportVision() || !strings.HasPrefix(content.ImageURL.URL, "data:") {
+						continue
+					}
+
+					_, mimeType, err := misc.DecodeBase64ImageWithMime(content.ImageURL.URL)
+					if err != nil {
+						continue
+					}
+
+					parts = append(parts, geminiPart{InlineData: &geminiInlineData{
+						MimeType: mimeType,
+						Data:     misc.RemoveImageBase64Prefix(content.ImageURL.URL),
+					}})
+					continue
+				}
+
+				if content.Text != "" {
+					parts = append(parts, geminiPart{Text: content.Text})
+				}
+			}
+		} else if msg.Content != "" {
+			parts = append(parts, geminiPart{Text: msg.Content})
+		}
+
+		for _, call := range msg.ToolCalls {
+			toolNames[call.ID] = call.Function.Name
+			parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{
+				Name: call.Function.Name,
+				// 与 Anthropic 相同，参数必须是 JSON 对象
+				Args: anthropicToolInput(call.Function.Arguments),
+			}})
+		}
+
+		if len(parts) == 0 {
+			continue
+		}
+
+		role := "user"
+		if msg.Role == "assistant" {
+			role = "model"
+		}
+
+		if len(gr.Contents) > 0 && gr.Contents[len(gr.Contents)-1].Role == role {
+			gr.Contents[len(gr.Contents)-1].Parts = append(gr.Contents[len(gr.Contents)-1].Parts, parts...)
+			continue
+		}
+
+		gr.Contents = append(gr.Contents, geminiContent{Role: role, Parts: parts})
+	}
+
+	if len(systemParts) > 0 {
+		gr.SystemInstruction = &geminiContent{Parts: systemParts}
+	}
+
+	declarations := make([]geminiFunctionDeclaration, 0, len(req.Tools))
+	for _, tool := range req.Tools {
+		if tool.Function != nil {
+			declarations = append(declarations, geminiFunctionDeclaration{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  tool.Function.Parameters,
+			})
+		}
+	}
+
+	if len(declarations) > 0 {
+		gr.Tools = []geminiTool{{FunctionDeclarations: declarations}}
+		gr.ToolConfig = geminiToolConfigFor(req)
+	}
+
+	return gr
+}
+
+// geminiToolConfigFor 转换 tool_choice，没有指定时使用 Gemini 的默认行为（AUTO）
+func geminiToolConfigFor(req BackendRequest) *geminiToolConfig {
+	switch mode, name := req.toolChoice(); mode {
+	case toolChoiceNone:
+		return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "NONE"}}
+	case toolChoiceRequired:
+		return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "ANY"}}
+	case toolChoiceFunction:
+		return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{name}}}
+	case toolChoiceAuto:
+		return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "AUTO"}}
+	default:
+		return nil
+	}
+}
+
+// ChatStream implements Backend
+func (backend *GeminiBackend) ChatStream(ctx context.Context, req BackendRequest) (<-chan StreamResponse, error) {
+	body, err := json.Marshal(buildGeminiRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1beta/models/%s:streamGenerateContent?alt=sse", backend.serverURL, url.PathEscape(req.Model.ID))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-goog-api-key", backend.apiKey)
+
+	resp, err := backend.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &BackendError{StatusCode: resp.StatusCode, Message: string(data)}
+	}
+
+	res := make(chan StreamResponse)
+	go func() {
+		defer func() {
+			close(res)
+			_ = resp.Body.Close()
+		}()
+
+		send := func(data StreamResponse) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case res <- data:
+				return data.ErrorCode == ""
+			}
+		}
+
+		id := misc.UUID()
+		// Gemini 不返回工具调用 ID，按照返回顺序生成
+		toolIndex := 0
+		err := readServerSentEvents(resp.Body, func(_ string, data string) bool {
+			var chunk geminiStreamResponse
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				return send(StreamResponse{ErrorCode: "READ_STREAM_FAILED", ErrorMessage: fmt.Sprintf("invalid stream event: %v", err)})
+			}
+
+			if chunk.Error != nil {
+				return send(StreamResponse{ErrorCode: chunk.Error.Status, ErrorMessage: chunk.Error.Message})
+			}
+
+			var usage *Usage
+			if chunk.UsageMetadata != nil {
+				usage = &Usage{
+					PromptTokens:     chunk.UsageMetadata.PromptTokenCount,
+					CompletionTokens: chunk.UsageMetadata.CandidatesTokenCount,
+					TotalTokens:      chunk.UsageMetadata.TotalTokenCount,
+				}
+			}
+
+			if len(chunk.Candidates) == 0 && usage != nil {
+				return send(StreamResponse{ID: id, Created: time.Now().Unix(), Usage: usage})
+			}
+
+			for _, candidate := range chunk.Candidates {
+				var text string
+				var calls []ToolCall
+				for _, part := range candidate.Content.Parts {
+					text += part.Text
+					if part.FunctionCall != nil {
+						calls = append(calls, geminiToolCall(toolIndex, *part.FunctionCall))
+						toolIndex++
+					}
+				}
+
+				finishReason := geminiFinishReason(candidate.FinishReason)
+				if finishReason == "stop" && toolIndex > 0 {
+					finishReason = "tool_calls"
+				}
+
+				resp := NewStreamResponse(id, text, finishReason)
+				resp.Choices[0].Delta.ToolCalls = calls
+				resp.Usage = usage
+				if !send(resp) {
+					return false
+				}
+			}
+
+			return true
+		})
+		if err != nil && ctx.Err() == nil {
+			send(StreamResponse{ErrorCode: "READ_STREAM_FAILED", ErrorMessage: fmt.Sprintf("read stream failed: %v", err)})
+		}
+	}()
+
+	return res, nil
+}
+
+// geminiToolCall Gemini 一次返回完整的函数调用，转换为包含完整参数的工具调用分片
+func geminiToolCall(index int, call geminiFunctionCall) ToolCall {
+	args := string(call.Args)
+	if args == "" || args == "null" {
+		args = "{}"
+	}
+
+	return ToolCall{
+		Index:    index,
+		ID:       "call_" + strings.ReplaceAll(misc.UUID(), "-", ""),
+		Type:     "function",
+		Function: Function{Name: call.Name, Arguments: args},
+	}
+}
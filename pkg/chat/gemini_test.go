@@ -0,0 +1,62 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGeminiBackend_ChatStreamFunctionCall(t *testing.T) {
+	srv := sseServer(t,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"Checking"}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}},{"functionCall":{"name":"now"}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"totalTokenCount":15}}`,
+	)
+
+	backend := &GeminiBackend{serverURL: srv.URL, client: srv.Client()}
+	stream, err := backend.ChatStream(context.Background(), BackendRequest{Model: estimateModel})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, calls, finishReason := readStream(t, stream)
+	if text != "Checking" || finishReason != "tool_calls" {
+		t.Errorf("unexpected text %q or finish reason %q", text, finishReason)
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("expect 2 tool calls, got %s", mustJSON(calls))
+	}
+
+	if calls[0].Function.Name != "get_weather" || calls[0].Function.Arguments != `{"city":"Paris"}` || calls[0].ID == "" {
+		t.Errorf("unexpected tool call: %+v", calls[0])
+	}
+
+	if calls[1].Function.Name != "now" || calls[1].Function.Arguments != "{}" || calls[1].ID == calls[0].ID {
+		t.Errorf("unexpected tool call: %+v", calls[1])
+	}
+}
+
+func TestBuildGeminiRequest_Tools(t *testing.T) {
+	gr := buildGeminiRequest(BackendRequest{
+		Model: estimateModel,
+		Messages: Messages{
+			{Role: "user", Content: "weather?"},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: Function{Name: "get_weather", Arguments: `{"city":"Paris"}`}}}},
+			{Role: "tool", ToolCallID: "call_1", Content: "sunny"},
+		},
+		Tools:      []Tool{{Type: "function", Function: &FunctionDefinition{Name: "get_weather", Parameters: map[string]any{"type": "object"}}}},
+		ToolChoice: map[string]any{"type": "function", "function": map[string]any{"name": "get_weather"}},
+	})
+
+	data := string(mustJSON(gr))
+	for _, want := range []string{
+		`"functionDeclarations":[{"name":"get_weather","parameters":{"type":"object"}}]`,
+		`"functionCallingConfig":{"mode":"ANY","allowedFunctionNames":["get_weather"]}`,
+		`{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}}]}`,
+		`{"role":"user","parts":[{"functionResponse":{"name":"get_weather","response":{"content":"sunny"}}}]}`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("expect %s in request: %s", want, data)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/asteria/log"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// imageBaseTokens 每张图片的基础 token 数量，low 模式下只计算该部分
+	imageBaseTokens = 85
+	// imageTileTokens high 模式下每个 512px 分块的 token 数量
+	imageTileTokens = 170
+	// imageFallbackTokens 无法获取图片尺寸时按照 high 模式下的最大 token 数量计算（768x2048 缩放后 2x4 个分块）
+	imageFallbackTokens = imageBaseTokens + imageTileTokens*8
+
+	// maxRemoteImageSize 远程图片的最大字节数，超过后不再读取
+	maxRemoteImageSize = 20 * 1024 * 1024
+	// maxImageSizeCacheEntries 图片尺寸缓存的最大数量，超过后清空缓存
+	maxImageSizeCacheEntries = 10000
+	// imageSizeFailureTTL 获取图片尺寸失败的结果只缓存较短的时间，避免临时的错误导致图片一直按照最大值计算
+	imageSizeFailureTTL = time.Minute
+	// imageSizeTimeout 单张远程图片获取尺寸的超时时间
+	imageSizeTimeout = 5 * time.Second
+	// imageSizeBudget 一次 token 计算中获取所有图片尺寸的总时间，超出后其余的图片按照最大值计算
+	imageSizeBudget = 10 * time.Second
+)
+
+// imageSize 图片尺寸，width 为 0 表示无法获取图片尺寸
+type imageSize struct {
+	width  int
+	height int
+	// expireAt the cached result expires at, zero means never expires
+	expireAt time.Time
+}
+
+var (
+	imageSizeCache     = make(map[string]imageSize)
+	imageSizeCacheLock sync.Mutex
+	imageHTTPClient    = misc.NewPublicHTTPClient(imageSizeTimeout)
+)
+
+// ImageTokenCount 计算图片消耗的 token 数量
+//
+// low 模式下固定为 85 个 token；high 以及 auto 模式下，图片先缩放到 2048x2048 以内，
+// 再缩放到短边为 768px，按照 512px 的分块数量计算，每个分块 170 个 token，再加上 85 个基础 token
+func ImageTokenCount(url string, detail string) int {
+	return imageTokenCount(url, detail, time.Now().Add(imageSizeBudget))
+}
+
+// imageTokenCount 计算图片消耗的 token 数量，超过 deadline 后不再获取远程图片的尺寸
+func imageTokenCount(url string, detail string, deadline time.Time) int {
+	if detail == "low" {
+		return imageBaseTokens
+	}
+
+	size := resolveImageSize(url, deadline)
+	return imageTokensForSize(size.width, size.height)
+}
+
+// imageTokensForSize 根据图片尺寸计算 high 模式下消耗的 token 数量，尺寸无效时按照最大值计算
+func imageTokensForSize(w, h int) int {
+	if w <= 0 || h <= 0 {
+		return imageFallbackTokens
+	}
+
+	width, height := float64(w), float64(h)
+	if width > 2048 || height > 2048 {
+		scale := 2048 / math.Max(width, height)
+		width, height = width*scale, height*scale
+	}
+
+	if math.Min(width, height) > 768 {
+		scale := 768 / math.Min(width, height)
+		width, height = width*scale, height*scale
+	}
+
+	tiles := int(math.Ceil(width/512) * math.Ceil(height/512))
+	return imageBaseTokens + imageTileTokens*tiles
+}
+
+// resolveImageSize 获取图片尺寸，结果按照图片内容（data URI）或者 URL 的哈希值缓存，
+// 超过 deadline 后不再请求远程图片，直接返回无效的尺寸
+func resolveImageSize(url string, deadline time.Time) imageSize {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+
+	imageSizeCacheLock.Lock()
+	size, ok := imageSizeCache[key]
+	imageSizeCacheLock.Unlock()
+	if ok && (size.expireAt.IsZero() || time.Now().Before(size.expireAt)) {
+		return size
+	}
+
+	if !strings.HasPrefix(url, "data:") && !time.Now().Before(deadline) {
+		return imageSize{}
+	}
+
+	width, height, err := readImageSize(url, deadline)
+	size = imageSize{width: width, height: height}
+	if err != nil {
+		log.F(log.M{"url": misc.SubString(url, 100)}).Warningf("read image size failed: %s", err)
+
+		// 获取失败的结果短暂缓存，避免重复请求，同时允许临时的错误恢复
+		size = imageSize{expireAt: time.Now().Add(imageSizeFailureTTL)}
+	}
+
+	imageSizeCacheLock.Lock()
+	defer imageSizeCacheLock.Unlock()
+
+	if len(imageSizeCache) >= maxImageSizeCacheEntries {
+		imageSizeCache = make(map[string]imageSize)
+	}
+	imageSizeCache[key] = size
+
+	return size
+}
+
+// readImageSize 读取图片尺寸，支持 data URI 以及 http(s) 远程图片，只读取图片头部信息
+func readImageSize(url string, deadline time.Time) (int, int, error) {
+	if strings.HasPrefix(url, "data:") {
+		data, _, err := misc.DecodeBase64ImageWithMime(url)
+		if err != nil {
+			return 0, 0, fmt.Errorf("decode base64 image failed: %w", err)
+		}
+
+		conf, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return conf.Width, conf.Height, nil
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return 0, 0, fmt.Errorf("unsupported image url")
+	}
+
+	if limit := time.Now().Add(imageSizeTimeout); limit.Before(deadline) {
+		deadline = limit
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	resp, err := imageHTTPClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if resp.ContentLength > maxRemoteImageSize {
+		return 0, 0, fmt.Errorf("image is too large: %d bytes", resp.ContentLength)
+	}
+
+	conf, _, err := image.DecodeConfig(io.LimitReader(resp.Body, maxRemoteImageSize))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return conf.Width, conf.Height, nil
+}
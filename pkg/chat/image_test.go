@@ -0,0 +1,70 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestImageTokensForSize(t *testing.T) {
+	testCases := []struct {
+		width  int
+		height int
+		want   int
+	}{
+		{width: 512, height: 512, want: 255},
+		{width: 1024, height: 1024, want: 765},
+		{width: 2048, height: 4096, want: 1105},
+		{width: 100, height: 3000, want: 85 + 170*4},
+		{width: 0, height: 0, want: imageFallbackTokens},
+		{width: -1, height: 100, want: imageFallbackTokens},
+	}
+
+	for _, tc := range testCases {
+		if got := imageTokensForSize(tc.width, tc.height); got != tc.want {
+			t.Errorf("%dx%d: expect %d, got %d", tc.width, tc.height, tc.want, got)
+		}
+	}
+}
+
+func TestImageTokenCount_DataURI(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1024, 1024))); err != nil {
+		t.Fatal(err)
+	}
+
+	url := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	if got := ImageTokenCount(url, "high"); got != 765 {
+		t.Errorf("expect 765, got %d", got)
+	}
+
+	if got := ImageTokenCount(url, "low"); got != imageBaseTokens {
+		t.Errorf("expect %d, got %d", imageBaseTokens, got)
+	}
+}
+
+func TestResolveImageSize_FailureExpires(t *testing.T) {
+	url := "ftp://example.com/image.png"
+	size := resolveImageSize(url, time.Now().Add(time.Second))
+	if size.width != 0 || size.expireAt.IsZero() {
+		t.Fatalf("failed lookups should be cached with an expiration time, got %+v", size)
+	}
+
+	if ttl := time.Until(size.expireAt); ttl > imageSizeFailureTTL {
+		t.Errorf("failure ttl should not exceed %s, got %s", imageSizeFailureTTL, ttl)
+	}
+}
+
+func TestResolveImageSize_DeadlineExceeded(t *testing.T) {
+	size := resolveImageSize("https://example.com/deadline-exceeded.png", time.Now().Add(-time.Second))
+	if size.width != 0 || !size.expireAt.IsZero() {
+		t.Errorf("remote images should not be fetched after the deadline, got %+v", size)
+	}
+
+	if got := imageTokenCount("https://example.com/deadline-exceeded.png", "auto", time.Now().Add(-time.Second)); got != imageFallbackTokens {
+		t.Errorf("expect %d, got %d", imageFallbackTokens, got)
+	}
+}
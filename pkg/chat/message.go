@@ -9,6 +9,27 @@ type Message struct {
 	Role              string              `json:"role"`
 	Content           string              `json:"content"`
 	MultipartContents []*MultipartContent `json:"multipart_content,omitempty"`
+
+	// ToolCalls the tool calls generated by the model (role is assistant)
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID the tool call that this message is responding to (role is tool)
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// TextContent returns the text content of the message, including the text parts of multipart contents
+func (msg Message) TextContent() string {
+	if len(msg.MultipartContents) == 0 {
+		return msg.Content
+	}
+
+	texts := make([]string, 0, len(msg.MultipartContents))
+	for _, part := range msg.MultipartContents {
+		if part.Type == "text" && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
 }
 
 type MultipartContent struct {
@@ -26,12 +47,12 @@ type ImageURL struct {
 	// By default, the model will use the auto setting which will look at the image input size and decide if it should use the low or high setting
 	//
 	// - `low` will disable the “high res” model. The model will receive a low-res 512px x 512px version of the image,
-	//   and represent the image with a budget of 65 tokens. This allows the API to return faster responses and consume
+	//   and represent the image with a budget of 85 tokens. This allows the API to return faster responses and consume
 	//   fewer input tokens for use cases that do not require high detail.
 	//
 	// - `high` will enable “high res” mode, which first allows the model to see the low res image and
 	//   then creates detailed crops of input images as 512px squares based on the input image size.
-	//   Each of the detailed crops uses twice the jwt budget (65 tokens) for a total of 129 tokens.
+	//   Each of the detailed crops uses 170 tokens, see ImageTokenCount.
 	Detail string `json:"detail,omitempty"`
 }
 
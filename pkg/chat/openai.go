@@ -5,47 +5,95 @@ import (
 	"errors"
 	"fmt"
 	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
 	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
+	"github.com/mylxsw/go-utils/array"
 	"github.com/sashabaranov/go-openai"
 	"io"
+	"net/http"
+	"strings"
 	"time"
 )
 
 type OpenAIClient struct {
-	conf  config.OpenAIConfig
-	proxy *proxy.Proxy
+	conf       config.OpenAIConfig
+	httpClient *http.Client
 }
 
 func NewOpenAIClient(conf config.OpenAIConfig, pp *proxy.Proxy) *OpenAIClient {
-	return &OpenAIClient{conf: conf, proxy: pp}
+	return &OpenAIClient{
+		conf:       conf,
+		httpClient: &http.Client{Timeout: 180 * time.Second, Transport: pp.BuildTransport()},
+	}
+}
+
+// NewPublicOpenAIClient 创建只允许访问公网地址的客户端，用于请求用户提供的服务器
+func NewPublicOpenAIClient(conf config.OpenAIConfig) *OpenAIClient {
+	return &OpenAIClient{
+		conf:       conf,
+		httpClient: misc.NewPublicHTTPClient(180 * time.Second),
+	}
 }
 
+// azureDeploymentNameReplacer Azure deployment names cannot contain "." and ":"
+var azureDeploymentNameReplacer = strings.NewReplacer(".", "", ":", "")
+
 // createClient create a new openai client
 func (client *OpenAIClient) createClient() *openai.Client {
 	conf := openai.DefaultConfig(client.conf.APIKey)
-	conf.BaseURL = client.conf.ServerURL
-	conf.OrgID = client.conf.Organization
+	if client.conf.ServerURL != "" {
+		conf.BaseURL = strings.TrimSuffix(client.conf.ServerURL, "/")
+	}
 
-	conf.HTTPClient.Timeout = 180 * time.Second
-	conf.HTTPClient.Transport = client.proxy.BuildTransport()
+	conf.OrgID = client.conf.Organization
+	conf.HTTPClient = client.httpClient
 
 	if client.conf.UseAzure {
 		conf.APIType = openai.APITypeAzure
 		conf.APIVersion = client.conf.AzureAPIVersion
-		if client.conf.AzureModelMapping != nil {
-			conf.AzureModelMapperFunc = func(model string) string {
-				if v, ok := client.conf.AzureModelMapping[model]; ok {
-					return v
-				}
-
-				return model
+		conf.AzureModelMapperFunc = func(model string) string {
+			if v, ok := client.conf.AzureModelMapping[model]; ok {
+				return v
 			}
+
+			return azureDeploymentNameReplacer.Replace(model)
 		}
 	}
 
 	return openai.NewClientWithConfig(conf)
 }
 
+// azureStreamUsageAPIVersion the first Azure OpenAI API version that supports stream_options
+var azureStreamUsageAPIVersion = time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+// streamUsageSupported whether to request the token usage in the streaming response
+func (client *OpenAIClient) streamUsageSupported() bool {
+	if client.conf.DisableStreamUsage {
+		return false
+	}
+
+	if !client.conf.UseAzure {
+		return true
+	}
+
+	date, ok := azureAPIVersionDate(client.conf.AzureAPIVersion)
+	return ok && !date.Before(azureStreamUsageAPIVersion)
+}
+
+// azureAPIVersionDate 解析 Azure API 版本中的日期，版本格式为 YYYY-MM-DD[-preview]
+func azureAPIVersionDate(version string) (time.Time, bool) {
+	if len(version) < len("2006-01-02") {
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse("2006-01-02", version[:len("2006-01-02")])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
 type OpenAIStreamResponse struct {
 	Code         string `json:"code,omitempty"`
 	ErrorMessage string `json:"error_message,omitempty"`
@@ -53,8 +101,13 @@ type OpenAIStreamResponse struct {
 }
 
 // ChatStream call the OpenAI interface to initiate a streaming chat request
+//
+// 除非渠道禁用，否则会要求上游服务在最后返回 Token 使用量（stream_options.include_usage）
 func (client *OpenAIClient) ChatStream(ctx context.Context, request openai.ChatCompletionRequest) (<-chan OpenAIStreamResponse, error) {
 	request.Stream = true
+	if client.streamUsageSupported() {
+		request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
 
 	stream, err := client.createClient().CreateChatCompletionStream(ctx, request)
 	if err != nil {
@@ -65,7 +118,7 @@ func (client *OpenAIClient) ChatStream(ctx context.Context, request openai.ChatC
 	go func() {
 		defer func() {
 			close(res)
-			stream.Close()
+			_ = stream.Close()
 		}()
 
 		for {
@@ -92,3 +145,179 @@ func (client *OpenAIClient) ChatStream(ctx context.Context, request openai.ChatC
 
 	return res, nil
 }
+
+// OpenAIBackend OpenAI compatible chat backend
+type OpenAIBackend struct {
+	client *OpenAIClient
+}
+
+// NewOpenAIBackend create a new OpenAIBackend
+func NewOpenAIBackend(client *OpenAIClient) *OpenAIBackend {
+	return &OpenAIBackend{client: client}
+}
+
+// ChatStream implements Backend
+func (backend *OpenAIBackend) ChatStream(ctx context.Context, req BackendRequest) (<-chan StreamResponse, error) {
+	openaiRequest := openai.ChatCompletionRequest{
+		Model:      req.Model.ID,
+		Stream:     true,
+		MaxTokens:  req.MaxTokens,
+		ToolChoice: req.ToolChoice,
+		Tools: array.Map(req.Tools, func(item Tool, _ int) openai.Tool {
+			tool := openai.Tool{Type: openai.ToolType(item.Type)}
+			if item.Function != nil {
+				tool.Function = &openai.FunctionDefinition{
+					Name:        item.Function.Name,
+					Description: item.Function.Description,
+					Parameters:  item.Function.Parameters,
+				}
+			}
+
+			return tool
+		}),
+		Messages: array.Map(req.Messages, func(item Message, _ int) openai.ChatCompletionMessage {
+			toolCalls := array.Map(item.ToolCalls, func(call ToolCall, _ int) openai.ToolCall {
+				return openai.ToolCall{
+					ID:       call.ID,
+					Type:     openai.ToolType(call.Type),
+					Function: openai.FunctionCall{Name: call.Function.Name, Arguments: call.Function.Arguments},
+				}
+			})
+
+			// If the model supports vision, the message content needs to be converted into the corresponding format
+			if req.Model.SupportVision() && len(item.MultipartContents) > 0 {
+				contents := array.Map(item.MultipartContents, func(content *MultipartContent, _ int) openai.ChatMessagePart {
+					part := openai.ChatMessagePart{Text: content.Text, Type: openai.ChatMessagePartType(content.Type)}
+					if openai.ChatMessagePartType(content.Type) == openai.ChatMessagePartTypeImageURL {
+						part.ImageURL = &openai.ChatMessageImageURL{
+							URL:    content.ImageURL.URL,
+							Detail: openai.ImageURLDetail(content.ImageURL.Detail),
+						}
+					}
+
+					return part
+				})
+
+				return openai.ChatCompletionMessage{
+					Role:         item.Role,
+					MultiContent: contents,
+					ToolCalls:    toolCalls,
+					ToolCallID:   item.ToolCallID,
+				}
+			}
+
+			return openai.ChatCompletionMessage{
+				Role:       item.Role,
+				Content:    item.Content,
+				ToolCalls:  toolCalls,
+				ToolCallID: item.ToolCallID,
+			}
+		}),
+	}
+
+	if len(openaiRequest.Tools) == 0 {
+		openaiRequest.Tools = nil
+		openaiRequest.ToolChoice = nil
+	}
+
+	stream, err := backend.client.ChatStream(ctx, openaiRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(chan StreamResponse)
+	go func() {
+		defer close(res)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data, ok := <-stream:
+				if !ok {
+					return
+				}
+
+				var resp StreamResponse
+				if data.Code != "" {
+					resp = StreamResponse{ErrorMessage: data.ErrorMessage, ErrorCode: data.Code}
+				} else if len(data.ChatResponse.Choices) == 0 {
+					// 开启 include_usage 后，最后一个分片只包含 Token 使用量，choices 为空
+					if data.ChatResponse.Usage == nil {
+						continue
+					}
+
+					resp = StreamResponse{ID: data.ChatResponse.ID, Created: data.ChatResponse.Created}
+				} else {
+					resp = StreamResponse{
+						ID:      data.ChatResponse.ID,
+						Created: data.ChatResponse.Created,
+						Choices: []StreamChoice{
+							{
+								Index: 0,
+								Delta: Delta{
+									Content: array.Reduce(
+										data.ChatResponse.Choices,
+										func(carry string, item openai.ChatCompletionStreamChoice) string {
+											return carry + item.Delta.Content
+										},
+										"",
+									),
+									Role:      "assistant",
+									ToolCalls: openaiToolCallDeltas(data.ChatResponse.Choices),
+								},
+								FinishReason: string(data.ChatResponse.Choices[len(data.ChatResponse.Choices)-1].FinishReason),
+							},
+						},
+					}
+				}
+
+				if data.Code == "" && data.ChatResponse.Usage != nil {
+					resp.Usage = &Usage{
+						PromptTokens:     int64(data.ChatResponse.Usage.PromptTokens),
+						CompletionTokens: int64(data.ChatResponse.Usage.CompletionTokens),
+						TotalTokens:      int64(data.ChatResponse.Usage.TotalTokens),
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case res <- resp:
+				}
+
+				if resp.ErrorCode != "" {
+					return
+				}
+			}
+		}
+	}()
+
+	return res, nil
+}
+
+// openaiToolCallDeltas convert the tool call fragments in the stream response
+func openaiToolCallDeltas(choices []openai.ChatCompletionStreamChoice) []ToolCall {
+	calls := make([]ToolCall, 0)
+	for _, choice := range choices {
+		for i, call := range choice.Delta.ToolCalls {
+			index := i
+			if call.Index != nil {
+				index = *call.Index
+			}
+
+			calls = append(calls, ToolCall{
+				Index:    index,
+				ID:       call.ID,
+				Type:     string(call.Type),
+				Function: Function{Name: call.Function.Name, Arguments: call.Function.Arguments},
+			})
+		}
+	}
+
+	if len(calls) == 0 {
+		return nil
+	}
+
+	return calls
+}
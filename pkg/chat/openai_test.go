@@ -0,0 +1,204 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIClient_StreamUsageSupported(t *testing.T) {
+	testCases := []struct {
+		conf config.OpenAIConfig
+		want bool
+	}{
+		{conf: config.OpenAIConfig{}, want: true},
+		{conf: config.OpenAIConfig{DisableStreamUsage: true}, want: false},
+		{conf: config.OpenAIConfig{UseAzure: true, AzureAPIVersion: "2023-05-15"}, want: false},
+		{conf: config.OpenAIConfig{UseAzure: true, AzureAPIVersion: "2024-09-01"}, want: true},
+		{conf: config.OpenAIConfig{UseAzure: true, AzureAPIVersion: "2024-08-01-preview"}, want: false},
+		{conf: config.OpenAIConfig{UseAzure: true, AzureAPIVersion: "2024-09-01-preview"}, want: true},
+		{conf: config.OpenAIConfig{UseAzure: true, AzureAPIVersion: "2024-10-21"}, want: true},
+		{conf: config.OpenAIConfig{UseAzure: true, AzureAPIVersion: "2025-01-01-preview"}, want: true},
+		{conf: config.OpenAIConfig{UseAzure: true, AzureAPIVersion: "latest"}, want: false},
+		{conf: config.OpenAIConfig{UseAzure: true, AzureAPIVersion: "2024-10-21", DisableStreamUsage: true}, want: false},
+	}
+
+	for _, tc := range testCases {
+		client := &OpenAIClient{conf: tc.conf}
+		if got := client.streamUsageSupported(); got != tc.want {
+			t.Errorf("%+v: expect %v, got %v", tc.conf, tc.want, got)
+		}
+	}
+}
+
+// openaiRequest 上游服务收到的请求
+type openaiRequest struct {
+	path    string
+	query   string
+	header  http.Header
+	payload map[string]any
+}
+
+// openaiServer 记录收到的请求并返回固定的 SSE 响应
+func openaiServer(t *testing.T, received *openaiRequest, events ...string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.path, received.query, received.header = r.URL.Path, r.URL.RawQuery, r.Header
+		_ = json.NewDecoder(r.Body).Decode(&received.payload)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, evt := range events {
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", evt)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestOpenAIBackend_ChatStreamUsage(t *testing.T) {
+	var received openaiRequest
+	srv := openaiServer(t, &received,
+		`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+		`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":" world"},"finish_reason":"stop"}]}`,
+		`{"id":"chatcmpl-1","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":2,"total_tokens":14}}`,
+		`[DONE]`,
+		`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"ignored"}}]}`,
+	)
+
+	client := &OpenAIClient{conf: config.OpenAIConfig{ServerURL: srv.URL + "/v1", APIKey: "sk-test"}, httpClient: srv.Client()}
+	stream, err := NewOpenAIBackend(client).ChatStream(context.Background(), BackendRequest{
+		Model:    config.Model{ID: "gpt-4o"},
+		Messages: Messages{{Role: "user", Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var text, finishReason string
+	var usage *Usage
+	for resp := range stream {
+		if resp.ErrorCode != "" {
+			t.Fatalf("unexpected stream error: %s %s", resp.ErrorCode, resp.ErrorMessage)
+		}
+
+		text += resp.DeltaText()
+		for _, choice := range resp.Choices {
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+
+		if resp.Usage != nil {
+			usage = resp.Usage
+		}
+	}
+
+	// [DONE] 之后的内容不再读取
+	if text != "Hello world" || finishReason != "stop" {
+		t.Errorf("unexpected reply: %q, finish reason %q", text, finishReason)
+	}
+
+	if usage == nil || usage.PromptTokens != 12 || usage.CompletionTokens != 2 || usage.TotalTokens != 14 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+
+	if received.path != "/v1/chat/completions" || received.header.Get("Authorization") != "Bearer sk-test" {
+		t.Errorf("unexpected request: %s %v", received.path, received.header)
+	}
+
+	if got := fmt.Sprint(received.payload["stream_options"]); got != "map[include_usage:true]" {
+		t.Errorf("stream usage should be requested, got %s", got)
+	}
+}
+
+func TestOpenAIBackend_ChatStreamAzure(t *testing.T) {
+	testCases := []struct {
+		apiVersion  string
+		mapping     map[string]string
+		wantPath    string
+		wantOptions bool
+	}{
+		{apiVersion: "2024-10-21", wantPath: "/openai/deployments/gpt-35-turbo/chat/completions", wantOptions: true},
+		{apiVersion: "2024-02-01", wantPath: "/openai/deployments/gpt-35-turbo/chat/completions", wantOptions: false},
+		{apiVersion: "2024-10-21", mapping: map[string]string{"gpt-3.5-turbo": "my-deployment"}, wantPath: "/openai/deployments/my-deployment/chat/completions", wantOptions: true},
+	}
+
+	for _, tc := range testCases {
+		var received openaiRequest
+		srv := openaiServer(t, &received, `{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"ok"},"finish_reason":"stop"}]}`, `[DONE]`)
+
+		client := &OpenAIClient{
+			conf: config.OpenAIConfig{
+				ServerURL:         srv.URL,
+				APIKey:            "azure-key",
+				UseAzure:          true,
+				AzureAPIVersion:   tc.apiVersion,
+				AzureModelMapping: tc.mapping,
+			},
+			httpClient: srv.Client(),
+		}
+
+		stream, err := NewOpenAIBackend(client).ChatStream(context.Background(), BackendRequest{
+			Model:    config.Model{ID: "gpt-3.5-turbo"},
+			Messages: Messages{{Role: "user", Content: "hi"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if text, _, _ := readStream(t, stream); text != "ok" {
+			t.Errorf("%s: unexpected reply: %q", tc.apiVersion, text)
+		}
+
+		if received.path != tc.wantPath || received.query != "api-version="+tc.apiVersion {
+			t.Errorf("%s: unexpected url: %s?%s", tc.apiVersion, received.path, received.query)
+		}
+
+		if received.header.Get("api-key") != "azure-key" || received.header.Get("Authorization") != "" {
+			t.Errorf("%s: unexpected auth headers: %v", tc.apiVersion, received.header)
+		}
+
+		if _, ok := received.payload["stream_options"]; ok != tc.wantOptions {
+			t.Errorf("%s: stream_options: expect %v, got %v", tc.apiVersion, tc.wantOptions, ok)
+		}
+	}
+}
+
+func TestOpenAIBackend_ChatStreamError(t *testing.T) {
+	var received openaiRequest
+	srv := openaiServer(t, &received,
+		`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hel"}}]}`,
+		`{"error":{"message":"The server had an error while processing your request","type":"server_error"}}`,
+	)
+
+	client := &OpenAIClient{conf: config.OpenAIConfig{ServerURL: srv.URL, APIKey: "sk-test"}, httpClient: srv.Client()}
+	stream, err := NewOpenAIBackend(client).ChatStream(context.Background(), BackendRequest{
+		Model:    config.Model{ID: "gpt-4o"},
+		Messages: Messages{{Role: "user", Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var text string
+	var last StreamResponse
+	for resp := range stream {
+		text += resp.DeltaText()
+		last = resp
+	}
+
+	if text != "Hel" || last.ErrorCode != "READ_STREAM_FAILED" {
+		t.Fatalf("unexpected stream: text %q, last %+v", text, last)
+	}
+
+	if want := "The server had an error while processing your request"; !strings.Contains(last.ErrorMessage, want) {
+		t.Errorf("error message should contain %q, got %q", want, last.ErrorMessage)
+	}
+}
@@ -1,8 +1,7 @@
 package chat
 
 import (
-	"github.com/mylxsw/aidea-chat-server/config"
-	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
+	"context"
 	"github.com/mylxsw/glacier/infra"
 )
 
@@ -10,7 +9,13 @@ type Provider struct{}
 
 func (Provider) Register(binder infra.Binder) {
 	binder.MustSingleton(NewChatter)
-	binder.MustSingleton(func(conf *config.Config, pp *proxy.Proxy) *OpenAIClient {
-		return NewOpenAIClient(conf.OpenAI, pp)
+	binder.MustSingleton(NewStreamBuffer)
+	binder.MustSingleton(NewCanceller)
+}
+
+// Daemon 监听其它实例广播的取消请求
+func (Provider) Daemon(ctx context.Context, resolver infra.Resolver) {
+	resolver.MustResolve(func(canceller *Canceller) {
+		canceller.Listen(ctx)
 	})
 }
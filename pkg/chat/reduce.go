@@ -0,0 +1,318 @@
+package chat
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/asteria/log"
+	"github.com/redis/go-redis/v9"
+	"strings"
+	"time"
+)
+
+// ContextReducer 上下文裁剪策略，将对话上下文裁剪到 MaxTokens 以内
+type ContextReducer interface {
+	Reduce(ctx context.Context, req ReduceRequest) (Messages, error)
+}
+
+// ReduceRequest the conversation context to be reduced
+type ReduceRequest struct {
+	// ConversationID the conversation the messages belong to, 0 means the conversation is not saved
+	ConversationID int64
+	Messages       Messages
+	Model          config.Model
+	MaxTokens      int
+}
+
+// TruncateReducer 丢弃最早的消息，system 消息始终保留
+type TruncateReducer struct{}
+
+// Reduce implements ContextReducer
+func (TruncateReducer) Reduce(_ context.Context, req ReduceRequest) (Messages, error) {
+	reduced, _, err := ReduceContextByTokens(req.Messages, req.Model, req.MaxTokens)
+	return reduced, err
+}
+
+// CountReducer 只保留最近的 MaxTurns 轮对话，再按照 token 数量裁剪
+type CountReducer struct {
+	MaxTurns int
+}
+
+// Reduce implements ContextReducer
+func (r CountReducer) Reduce(ctx context.Context, req ReduceRequest) (Messages, error) {
+	if r.MaxTurns > 0 {
+		pinned, rest := splitSystemMessages(req.Messages)
+		req.Messages = append(pinned, ReduceContextByCount(rest, r.MaxTurns)...)
+	}
+
+	return TruncateReducer{}.Reduce(ctx, req)
+}
+
+// DropMiddleReducer 保留第一轮对话（通常包含了用户的任务描述）以及最近的对话，丢弃中间的对话
+type DropMiddleReducer struct{}
+
+// Reduce implements ContextReducer
+func (DropMiddleReducer) Reduce(ctx context.Context, req ReduceRequest) (Messages, error) {
+	num, err := MessageTokenCount(req.Messages, req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	if num <= req.MaxTokens {
+		return req.Messages, nil
+	}
+
+	pinned, rest := splitSystemMessages(req.Messages)
+	turns := splitTurns(rest)
+	if len(turns) < 3 {
+		return TruncateReducer{}.Reduce(ctx, req)
+	}
+
+	// 从最近的对话开始，尽可能多的保留
+	kept := append(append(Messages{}, pinned...), turns[0]...)
+	total := countTokens(kept, req.Model) + replyPrimingTokens
+
+	var recent Messages
+	for i := len(turns) - 1; i > 0; i-- {
+		total += countTokens(turns[i], req.Model)
+		if total > req.MaxTokens {
+			break
+		}
+
+		recent = append(append(Messages{}, turns[i]...), recent...)
+	}
+
+	// 第一轮和最后一轮对话无法同时保留时，退化为丢弃最早的消息
+	if len(recent) == 0 {
+		return TruncateReducer{}.Reduce(ctx, req)
+	}
+
+	return append(kept, recent...), nil
+}
+
+// Summarizer 将对话内容总结为摘要，previous 为之前的摘要，需要合并到新的摘要中
+type Summarizer func(ctx context.Context, previous string, messages Messages) (string, error)
+
+// ContextSummary 会话的上下文摘要
+type ContextSummary struct {
+	// Count the number of messages (excluding system messages) covered by the summary
+	Count int `json:"count"`
+	// Hash the hash of the messages covered by the summary
+	Hash string `json:"hash"`
+	// Summary the summary content
+	Summary string `json:"summary"`
+}
+
+// SummaryCache 上下文摘要缓存，每个会话一份
+type SummaryCache interface {
+	Get(ctx context.Context, conversationID int64) (*ContextSummary, error)
+	Set(ctx context.Context, conversationID int64, summary ContextSummary) error
+}
+
+// RedisSummaryCache 使用 Redis 缓存上下文摘要
+type RedisSummaryCache struct {
+	rds *redis.Client
+}
+
+// NewRedisSummaryCache create a new RedisSummaryCache
+func NewRedisSummaryCache(rds *redis.Client) *RedisSummaryCache {
+	return &RedisSummaryCache{rds: rds}
+}
+
+// summaryCacheTTL 摘要缓存的有效期，会话长时间不活跃后缓存失效
+const summaryCacheTTL = 7 * 24 * time.Hour
+
+func summaryCacheKey(conversationID int64) string {
+	return fmt.Sprintf("chat:context-summary:%d", conversationID)
+}
+
+// Get implements SummaryCache, returns nil when the summary does not exist
+func (cache *RedisSummaryCache) Get(ctx context.Context, conversationID int64) (*ContextSummary, error) {
+	data, err := cache.rds.Get(ctx, summaryCacheKey(conversationID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var summary ContextSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// Set implements SummaryCache
+func (cache *RedisSummaryCache) Set(ctx context.Context, conversationID int64, summary ContextSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	return cache.rds.Set(ctx, summaryCacheKey(conversationID), data, summaryCacheTTL).Err()
+}
+
+// SummarizeReducer 将较早的对话总结为摘要，摘要按照会话缓存，会话增长时在之前摘要的基础上增量总结
+//
+// 没有会话 ID（如匿名用户、OpenAI 兼容接口）或者总结失败时，退化为丢弃最早的消息
+type SummarizeReducer struct {
+	Summarizer Summarizer
+	Cache      SummaryCache
+	// MaxSummaryTokens the maximum number of tokens of the summary, reserved from the context
+	MaxSummaryTokens int
+}
+
+// Reduce implements ContextReducer
+func (r SummarizeReducer) Reduce(ctx context.Context, req ReduceRequest) (Messages, error) {
+	num, err := MessageTokenCount(req.Messages, req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	if num <= req.MaxTokens || req.ConversationID == 0 {
+		return TruncateReducer{}.Reduce(ctx, req)
+	}
+
+	pinned, rest := splitSystemMessages(req.Messages)
+
+	// 最近的对话保留原文，为摘要预留空间
+	recent, _, err := ReduceContextByTokens(rest, req.Model, req.MaxTokens-r.MaxSummaryTokens-countTokens(pinned, req.Model))
+	if err != nil || len(recent) >= len(rest) {
+		return TruncateReducer{}.Reduce(ctx, req)
+	}
+
+	older := rest[:len(rest)-len(recent)]
+	summary, err := r.summarize(ctx, req.ConversationID, older)
+	if err != nil {
+		log.F(log.M{"conversation_id": req.ConversationID, "model": req.Model.ID}).Warningf("summarize context failed, fallback to truncate: %s", err)
+		return TruncateReducer{}.Reduce(ctx, req)
+	}
+
+	messages := append(append(Messages{}, pinned...), Message{
+		Role:    "system",
+		Content: "Summary of the earlier conversation:\n" + summary,
+	})
+
+	return TruncateReducer{}.Reduce(ctx, ReduceRequest{
+		ConversationID: req.ConversationID,
+		Messages:       append(messages, recent...),
+		Model:          req.Model,
+		MaxTokens:      req.MaxTokens,
+	})
+}
+
+// summarize 获取较早对话的摘要，缓存的摘要覆盖的消息是 older 的前缀时，只需要总结新增的消息
+func (r SummarizeReducer) summarize(ctx context.Context, conversationID int64, older Messages) (string, error) {
+	cached, err := r.Cache.Get(ctx, conversationID)
+	if err != nil {
+		log.F(log.M{"conversation_id": conversationID}).Warningf("get context summary from cache failed: %s", err)
+	}
+
+	var previous string
+	pending := older
+	if cached != nil && cached.Count <= len(older) && cached.Hash == hashMessages(older[:cached.Count]) {
+		if cached.Count == len(older) {
+			return cached.Summary, nil
+		}
+
+		previous, pending = cached.Summary, older[cached.Count:]
+	}
+
+	summary, err := r.Summarizer(ctx, previous, pending)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.Cache.Set(ctx, conversationID, ContextSummary{
+		Count:   len(older),
+		Hash:    hashMessages(older),
+		Summary: summary,
+	}); err != nil {
+		log.F(log.M{"conversation_id": conversationID}).Warningf("save context summary to cache failed: %s", err)
+	}
+
+	return summary, nil
+}
+
+// summaryPrompt 生成摘要使用的系统提示
+const summaryPrompt = "You are a conversation summarizer. Summarize the conversation below concisely in the language used by the user. " +
+	"Keep key facts, decisions, names, numbers and unresolved questions. Reply with the summary only."
+
+// buildSummaryMessages 构建生成摘要的请求消息
+func buildSummaryMessages(previous string, messages Messages) Messages {
+	var sb strings.Builder
+	if previous != "" {
+		sb.WriteString("Previous summary:\n")
+		sb.WriteString(previous)
+		sb.WriteString("\n\nNew messages:\n")
+	}
+
+	for _, msg := range messages {
+		text := msg.TextContent()
+		for _, call := range msg.ToolCalls {
+			text += fmt.Sprintf("\n[call %s: %s]", call.Function.Name, call.Function.Arguments)
+		}
+
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("%s: %s\n\n", msg.Role, text))
+	}
+
+	return Messages{
+		{Role: "system", Content: summaryPrompt},
+		{Role: "user", Content: sb.String()},
+	}
+}
+
+// splitSystemMessages 分离 system 消息和其它消息
+func splitSystemMessages(messages Messages) (system Messages, rest Messages) {
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			system = append(system, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+
+	return system, rest
+}
+
+// splitTurns 按照 user 消息将对话拆分为多轮，每一轮以 user 消息开始
+func splitTurns(messages Messages) []Messages {
+	turns := make([]Messages, 0)
+	for _, msg := range messages {
+		if msg.Role == "user" || len(turns) == 0 {
+			turns = append(turns, Messages{})
+		}
+
+		turns[len(turns)-1] = append(turns[len(turns)-1], msg)
+	}
+
+	return turns
+}
+
+// countTokens 计算消息的 token 数量，不包含回复的前缀
+func countTokens(messages Messages, model config.Model) int {
+	if len(messages) == 0 {
+		return 0
+	}
+
+	num, _ := MessageTokenCount(messages, model)
+	return num - replyPrimingTokens
+}
+
+// hashMessages 计算消息列表的哈希值，用于判断缓存的摘要是否仍然有效
+func hashMessages(messages Messages) string {
+	data, _ := json.Marshal(messages)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
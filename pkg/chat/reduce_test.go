@@ -0,0 +1,233 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// memorySummaryCache 进程内的摘要缓存
+type memorySummaryCache map[int64]ContextSummary
+
+func (cache memorySummaryCache) Get(_ context.Context, conversationID int64) (*ContextSummary, error) {
+	summary, ok := cache[conversationID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &summary, nil
+}
+
+func (cache memorySummaryCache) Set(_ context.Context, conversationID int64, summary ContextSummary) error {
+	cache[conversationID] = summary
+	return nil
+}
+
+// summarizerCall 一次生成摘要的请求
+type summarizerCall struct {
+	previous string
+	messages string
+}
+
+// stubSummarizer 记录每次生成摘要的请求，摘要内容为请求的序号
+type stubSummarizer struct {
+	calls []summarizerCall
+	err   error
+}
+
+func (s *stubSummarizer) Summarize(_ context.Context, previous string, messages Messages) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	s.calls = append(s.calls, summarizerCall{previous: previous, messages: roles(messages)})
+	return fmt.Sprintf("summary-%d", len(s.calls)), nil
+}
+
+func TestDropMiddleReducer(t *testing.T) {
+	long := strings.Repeat("a", 400)
+
+	messages := Messages{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "task"},
+		{Role: "assistant", Content: "ok"},
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "q3"},
+		{Role: "assistant", Content: "a3"},
+		{Role: "user", Content: "q4"},
+	}
+
+	reduced, err := DropMiddleReducer{}.Reduce(context.Background(), ReduceRequest{Messages: messages, Model: estimateModel, MaxTokens: 80})
+	if err != nil {
+		t.Fatalf("reduce failed: %v", err)
+	}
+
+	// 保留 system 消息、第一轮对话以及最近的对话，丢弃中间的对话
+	if got, want := roles(reduced), "system:sys,user:task,assistant:ok,user:q3,assistant:a3,user:q4"; got != want {
+		t.Errorf("expect %s, got %s", want, got)
+	}
+
+	// 上下文没有超出限制时不裁剪
+	reduced, err = DropMiddleReducer{}.Reduce(context.Background(), ReduceRequest{Messages: messages, Model: estimateModel, MaxTokens: 1000})
+	if err != nil {
+		t.Fatalf("reduce failed: %v", err)
+	}
+
+	if len(reduced) != len(messages) {
+		t.Errorf("messages should not be reduced, got %s", roles(reduced))
+	}
+
+	// 对话少于三轮时，丢弃最早的消息
+	reduced, err = DropMiddleReducer{}.Reduce(context.Background(), ReduceRequest{Messages: Messages{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "q2"},
+	}, Model: estimateModel, MaxTokens: 80})
+	if err != nil {
+		t.Fatalf("reduce failed: %v", err)
+	}
+
+	if got, want := roles(reduced), "system:sys,user:q2"; got != want {
+		t.Errorf("expect %s, got %s", want, got)
+	}
+}
+
+func TestCountReducer(t *testing.T) {
+	messages := Messages{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "q1"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "q2"},
+		{Role: "assistant", Content: "a2"},
+		{Role: "user", Content: "q3"},
+	}
+
+	testCases := []struct {
+		maxTurns  int
+		maxTokens int
+		want      string
+	}{
+		{maxTurns: 1, maxTokens: 1000, want: "system:sys,user:q2,assistant:a2,user:q3"},
+		{maxTurns: 5, maxTokens: 1000, want: "system:sys,user:q1,assistant:a1,user:q2,assistant:a2,user:q3"},
+		{maxTurns: 0, maxTokens: 1000, want: "system:sys,user:q1,assistant:a1,user:q2,assistant:a2,user:q3"},
+		// 按照轮数裁剪后，仍然需要满足 token 数量的限制
+		{maxTurns: 2, maxTokens: 15, want: "system:sys,user:q3"},
+	}
+
+	for _, tc := range testCases {
+		reduced, err := CountReducer{MaxTurns: tc.maxTurns}.Reduce(context.Background(), ReduceRequest{Messages: messages, Model: estimateModel, MaxTokens: tc.maxTokens})
+		if err != nil {
+			t.Fatalf("max turns %d: reduce failed: %v", tc.maxTurns, err)
+		}
+
+		if got := roles(reduced); got != tc.want {
+			t.Errorf("max turns %d: expect %s, got %s", tc.maxTurns, tc.want, got)
+		}
+	}
+}
+
+func TestSummarizeReducer(t *testing.T) {
+	long := func(c string) string { return strings.Repeat(c, 400) }
+
+	messages := Messages{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: long("a")},
+		{Role: "assistant", Content: long("b")},
+		{Role: "user", Content: long("c")},
+		{Role: "assistant", Content: long("d")},
+		{Role: "user", Content: "q3"},
+	}
+
+	summarizer := &stubSummarizer{}
+	cache := memorySummaryCache{}
+	reducer := SummarizeReducer{Summarizer: summarizer.Summarize, Cache: cache, MaxSummaryTokens: 20}
+
+	reduce := func(messages Messages) string {
+		t.Helper()
+
+		reduced, err := reducer.Reduce(context.Background(), ReduceRequest{ConversationID: 1, Messages: messages, Model: estimateModel, MaxTokens: 150})
+		if err != nil {
+			t.Fatalf("reduce failed: %v", err)
+		}
+
+		return roles(reduced)
+	}
+
+	// 较早的对话总结为摘要，保留 system 消息以及最近的一轮对话
+	if got, want := reduce(messages), "system:sys,system:Summary of the earlier conversation:\nsummary-1,user:q3"; got != want {
+		t.Errorf("expect %q, got %q", want, got)
+	}
+
+	if len(summarizer.calls) != 1 || summarizer.calls[0].previous != "" || summarizer.calls[0].messages != roles(messages[1:5]) {
+		t.Fatalf("unexpected summarizer calls: %+v", summarizer.calls)
+	}
+
+	// 被总结的消息没有变化时，直接使用缓存的摘要
+	if got := reduce(messages); !strings.Contains(got, "summary-1") || len(summarizer.calls) != 1 {
+		t.Errorf("cached summary should be reused, got %q, %d calls", got, len(summarizer.calls))
+	}
+
+	// 会话增长时，只总结新增的消息并合并之前的摘要
+	grown := append(append(Messages{}, messages...), Message{Role: "assistant", Content: long("e")}, Message{Role: "user", Content: long("f")})
+	if got := reduce(grown); !strings.Contains(got, "summary-2") || !strings.HasSuffix(got, "user:"+long("f")) {
+		t.Errorf("unexpected reduced messages: %q", got)
+	}
+
+	if len(summarizer.calls) != 2 || summarizer.calls[1].previous != "summary-1" || summarizer.calls[1].messages != roles(grown[5:7]) {
+		t.Fatalf("only the new messages should be summarized: %+v", summarizer.calls[1:])
+	}
+
+	// 较早的消息被修改（如编辑了提问）时，缓存失效，重新总结所有较早的消息
+	edited := append(Messages{}, grown...)
+	edited[1] = Message{Role: "user", Content: long("x")}
+	if got := reduce(edited); !strings.Contains(got, "summary-3") {
+		t.Errorf("unexpected reduced messages: %q", got)
+	}
+
+	if len(summarizer.calls) != 3 || summarizer.calls[2].previous != "" || summarizer.calls[2].messages != roles(edited[1:7]) {
+		t.Fatalf("all older messages should be summarized again: %+v", summarizer.calls[2:])
+	}
+
+	if cache[1].Count != 6 || cache[1].Hash != hashMessages(edited[1:7]) {
+		t.Errorf("unexpected cached summary: %+v", cache[1])
+	}
+}
+
+func TestSummarizeReducer_Fallback(t *testing.T) {
+	long := strings.Repeat("a", 400)
+	messages := Messages{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "q2"},
+	}
+
+	testCases := map[string]struct {
+		conversationID int64
+		summarizer     *stubSummarizer
+	}{
+		"conversation is not saved": {conversationID: 0, summarizer: &stubSummarizer{}},
+		"summarize failed":          {conversationID: 1, summarizer: &stubSummarizer{err: errors.New("summarize failed")}},
+	}
+
+	for name, tc := range testCases {
+		reducer := SummarizeReducer{Summarizer: tc.summarizer.Summarize, Cache: memorySummaryCache{}, MaxSummaryTokens: 20}
+		reduced, err := reducer.Reduce(context.Background(), ReduceRequest{ConversationID: tc.conversationID, Messages: messages, Model: estimateModel, MaxTokens: 150})
+		if err != nil {
+			t.Fatalf("%s: reduce failed: %v", name, err)
+		}
+
+		// 退化为丢弃最早的消息
+		if got, want := roles(reduced), "system:sys,user:q2"; got != want {
+			t.Errorf("%s: expect %s, got %s", name, want, got)
+		}
+
+		if len(tc.summarizer.calls) != 0 {
+			t.Errorf("%s: summarizer should not be called", name)
+		}
+	}
+}
@@ -2,7 +2,31 @@ package chat
 
 // Request represents a request structure for chat completion API.
 type Request struct {
+	// UserID the user who initiated the request, used for robot permission check
+	UserID    int64    `json:"-"`
 	RobotID   string   `json:"robot_id"`
 	Messages  Messages `json:"messages"`
 	MaxTokens int      `json:"max_tokens,omitempty"`
+	// ConversationID the conversation the request belongs to, used to cache the context summary, 0 means not saved
+	ConversationID int64 `json:"-"`
+
+	// Tools a list of tools the model may call
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolChoice controls which (if any) tool is called by the model, none/auto or {"type": "function", "function": {"name": "my_function"}}
+	ToolChoice any `json:"tool_choice,omitempty"`
+}
+
+// Tool a tool the model may call, currently only function is supported
+type Tool struct {
+	// Type the type of the tool, currently only function is supported
+	Type     string              `json:"type"`
+	Function *FunctionDefinition `json:"function,omitempty"`
+}
+
+// FunctionDefinition the definition of a function that the model may call
+type FunctionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Parameters the parameters the function accepts, described as a JSON Schema object
+	Parameters any `json:"parameters"`
 }
@@ -2,6 +2,7 @@ package chat
 
 import (
 	"encoding/json"
+	"github.com/mylxsw/aidea-chat-server/pkg/knowledge"
 	"github.com/mylxsw/go-utils/array"
 	"time"
 )
@@ -16,6 +17,10 @@ type StreamResponse struct {
 
 	// Usage only the last message contains Usage information
 	Usage *Usage `json:"usage,omitempty"`
+	// ToolStep the execution step of the built-in tool, not included in the normal message frame
+	ToolStep *ToolStep `json:"tool_step,omitempty"`
+	// References the knowledge base content referenced by the answer, only when the robot enables origin reference
+	References []knowledge.Reference `json:"references,omitempty"`
 
 	// ErrorCode An error code if the model encounters an error while generating the response.
 	ErrorCode string `json:"error_code,omitempty"`
@@ -24,10 +29,20 @@ type StreamResponse struct {
 }
 
 type Usage struct {
+	// Model the model used for the chat completion
+	Model string `json:"model,omitempty"`
+	// Channel the channel that actually served the request
+	Channel string `json:"-"`
+
 	CompletionTokens int64 `json:"completion_tokens,omitempty"`
 	PromptTokens     int64 `json:"prompt_tokens,omitempty"`
 	TotalTokens      int64 `json:"total_tokens,omitempty"`
 
+	// Tools the built-in tools called during the chat
+	Tools []string `json:"tools,omitempty"`
+	// ToolQuota the coins consumed by the built-in tools
+	ToolQuota int64 `json:"tool_quota,omitempty"`
+
 	// FirstLetterDelay first character delay time, in milliseconds
 	FirstLetterDelay int64 `json:"first_letter_delay,omitempty"`
 	// ConsumeInMilli total consume time, in milliseconds
@@ -57,6 +72,13 @@ func NewStreamResponse(id string, delta string, finishReason string) StreamRespo
 	}
 }
 
+// newToolCallStreamResponse creates a new StreamResponse with a tool call fragment, used by backends to return tool calls
+func newToolCallStreamResponse(id string, call ToolCall) StreamResponse {
+	resp := NewStreamResponse(id, "", "")
+	resp.Choices[0].Delta.ToolCalls = []ToolCall{call}
+	return resp
+}
+
 func NewSystemStreamResponse(id string, delta string, finishReason string) StreamResponse {
 	return StreamResponse{
 		ID:      id,
@@ -74,6 +96,64 @@ func NewSystemStreamResponse(id string, delta string, finishReason string) Strea
 	}
 }
 
+// ToolStep 内置工具的执行步骤
+type ToolStep struct {
+	// ID the id of the tool call
+	ID string `json:"id"`
+	// Name the name of the tool
+	Name string `json:"name"`
+	// Arguments the arguments generated by the model
+	Arguments string `json:"arguments"`
+	// Status running, succeed or failed
+	Status string `json:"status"`
+	// Result the result of the tool, only when status is succeed
+	Result string `json:"result,omitempty"`
+	// Error the error message, only when status is failed
+	Error string `json:"error,omitempty"`
+}
+
+const (
+	ToolStepStatusRunning = "running"
+	ToolStepStatusSucceed = "succeed"
+	ToolStepStatusFailed  = "failed"
+)
+
+// NewToolStepStreamResponse creates a new StreamResponse for the built-in tool execution step
+func NewToolStepStreamResponse(id string, step ToolStep) StreamResponse {
+	return StreamResponse{
+		ID:       id,
+		Created:  time.Now().Unix(),
+		Choices:  []StreamChoice{},
+		ToolStep: &step,
+	}
+}
+
+// NewReferencesStreamResponse creates a new StreamResponse for the referenced knowledge base content
+func NewReferencesStreamResponse(refs []knowledge.Reference) StreamResponse {
+	return StreamResponse{
+		ID:         "references",
+		Created:    time.Now().Unix(),
+		Choices:    []StreamChoice{},
+		References: refs,
+	}
+}
+
+// Completion the complete result of a non-streaming chat request
+type Completion struct {
+	// ID A unique identifier for the chat completion.
+	ID string `json:"id"`
+	// Message the reply message, including the tool calls that should be executed by the client
+	Message Message `json:"message"`
+	// FinishReason the reason the model stopped generating tokens
+	FinishReason string `json:"finish_reason,omitempty"`
+	// References the knowledge base content referenced by the answer, only when the robot enables origin reference
+	References []knowledge.Reference `json:"references,omitempty"`
+	// ToolSteps the results of the built-in tools executed during the chat
+	ToolSteps []ToolStep `json:"tool_steps,omitempty"`
+	// Usage the token usage of the request
+	Usage *Usage `json:"usage,omitempty"`
+}
+
 // DeltaText returns the delta content of the first choice.
 func (resp StreamResponse) DeltaText() string {
 	return array.Reduce(resp.Choices, func(carry string, item StreamChoice) string { return carry + item.Delta.Content }, "")
@@ -107,6 +187,8 @@ type ToolCall struct {
 	ID string `json:"id"`
 	// Type The type of the tool. Currently, only function is supported
 	Type string `json:"type"`
+	// Function The function that the model called.
+	Function Function `json:"function"`
 }
 
 type Function struct {
@@ -1,11 +1,12 @@
 package chat
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/mylxsw/go-utils/array"
-	"github.com/pkoukk/tiktoken-go"
+	"github.com/mylxsw/aidea-chat-server/config"
 	"strings"
+	"time"
 )
 
 // ReduceContextByCount 减少对话上下文到指定的上下文窗口大小
@@ -19,51 +20,78 @@ func ReduceContextByCount(messages Messages, maxCount int) Messages {
 	return messages
 }
 
-// ReduceContextByTokens 递归减少对话上下文
-func ReduceContextByTokens(messages Messages, model string, maxTokens int) (reducedMessages Messages, tokenCount int, err error) {
-	num, err := MessageTokenCount(messages, model)
-	if err != nil {
-		return nil, 0, fmt.Errorf("message token count: %v", err)
-	}
-
-	if num <= maxTokens {
-		// 第一个消息应该是 user 消息
-		if len(messages) > 1 && messages[0].Role == "assistant" {
-			return messages[1:], num, nil
+// ReduceContextByTokens 从最早的消息开始丢弃，直到上下文满足 maxTokens 的限制，system 消息始终保留
+func ReduceContextByTokens(messages Messages, model config.Model, maxTokens int) (reducedMessages Messages, tokenCount int, err error) {
+	// 每条消息的 token 数量只计算一次，避免重复计算整个上下文
+	counts := make([]int, len(messages))
+	total := replyPrimingTokens
+	for i, msg := range messages {
+		num, err := MessageTokenCount(Messages{msg}, model)
+		if err != nil {
+			return nil, 0, fmt.Errorf("message token count: %v", err)
 		}
 
-		return messages, num, nil
+		counts[i] = num - replyPrimingTokens
+		total += counts[i]
 	}
 
-	if len(messages) <= 1 {
-		return nil, 0, errors.New("conversation context is too long and cannot be generated further")
+	dropped := make([]bool, len(messages))
+	// 第一个非 system 消息应该是 user 消息，工具调用结果需要和发起调用的 assistant 消息一起出现
+	dropLeading := func() {
+		for i := 0; i < len(messages)-1; i++ {
+			if dropped[i] || messages[i].Role == "system" {
+				continue
+			}
+
+			if messages[i].Role != "assistant" && messages[i].Role != "tool" {
+				return
+			}
+
+			dropped[i] = true
+			total -= counts[i]
+		}
 	}
 
-	return ReduceContextByTokens(messages[1:], model, maxTokens)
-}
+	dropLeading()
+	for total > maxTokens {
+		idx := -1
+		for i := 0; i < len(messages)-1; i++ {
+			if !dropped[i] && messages[i].Role != "system" {
+				idx = i
+				break
+			}
+		}
 
-// MessageTokenCount 计算对话上下文的 token 数量
-// TODO 不同厂商模型的 Token 计算方式可能不同，需要根据厂商模型进行区分
-func MessageTokenCount(messages Messages, model string) (numTokens int, err error) {
-	_model := model
+		if idx < 0 {
+			return nil, 0, errors.New("conversation context is too long and cannot be generated further")
+		}
 
-	// 所有非 gpt-3.5-turbo/gpt-4 的模型，都按照 gpt-3.5 的方式处理
-	if !array.In(_model, []string{"gpt-3.5-turbo", "gpt-4"}) {
-		_model = "gpt-3.5-turbo"
+		dropped[idx] = true
+		total -= counts[idx]
+		dropLeading()
 	}
 
-	tkm, err := tiktoken.EncodingForModel(_model)
-	if err != nil {
-		return 0, fmt.Errorf("EncodingForModel: %v", err)
+	for i, msg := range messages {
+		if !dropped[i] {
+			reducedMessages = append(reducedMessages, msg)
+		}
 	}
 
-	var tokensPerMessage int
-	if strings.HasPrefix(_model, "gpt-3.5-turbo") {
+	return reducedMessages, total, nil
+}
+
+// replyPrimingTokens every reply is primed with <|start|>assistant<|message|>
+const replyPrimingTokens = 3
+
+// MessageTokenCount 计算对话上下文的 token 数量，使用模型配置的 Tokenizer
+func MessageTokenCount(messages Messages, model config.Model) (numTokens int, err error) {
+	tokenizer := TokenizerForModel(model)
+	// 获取远程图片尺寸的总时间有限制，避免请求被大量的图片阻塞
+	imageDeadline := time.Now().Add(imageSizeBudget)
+
+	tokensPerMessage := 3
+	if strings.HasPrefix(model.ID, "gpt-3.5-turbo") {
 		tokensPerMessage = 4
-	} else if strings.HasPrefix(_model, "gpt-4") {
-		tokensPerMessage = 3
-	} else {
-		tokensPerMessage = 3
 	}
 
 	for _, message := range messages {
@@ -71,32 +99,41 @@ func MessageTokenCount(messages Messages, model string) (numTokens int, err erro
 		if len(message.MultipartContents) > 0 {
 			for _, content := range message.MultipartContents {
 				if content.Type == "image_url" {
-					// 智谱的 GLM 4V 模型，图片的 token 计算方式不同
-					if model == "glm-4v" {
-						numTokens += 1047
-					} else {
-						if content.ImageURL.Detail == "low" {
-							numTokens += 65
-						} else {
-							// TODO 【价格昂贵，尽量避免】这里可能为 high 或者 auto，简单起见，auto 按照 high 处理
-							// 简单起见，这里假设 high 时大图为 2048x2048，切割为 16 个小图
-							//
-							// high will enable “high res” mode, which first allows the _model to see the low res image
-							// and then creates detailed crops of input images as 512px squares based on the input image size.
-							// Each of the detailed crops uses twice the token budget (65 tokens) for a total of 129 tokens
-							numTokens += 129 * 16
-						}
+					// 部分模型（如智谱的 GLM 4V）每张图片固定消耗的 token 数量
+					if model.ImageTokens > 0 {
+						numTokens += model.ImageTokens
+					} else if content.ImageURL != nil {
+						numTokens += imageTokenCount(content.ImageURL.URL, content.ImageURL.Detail, imageDeadline)
 					}
-
 				} else {
-					numTokens += len(tkm.Encode(content.Text, nil, nil))
+					numTokens += tokenizer.Count(content.Text)
 				}
 			}
 		} else {
-			numTokens += len(tkm.Encode(message.Content, nil, nil))
+			numTokens += tokenizer.Count(message.Content)
 		}
-		numTokens += len(tkm.Encode(message.Role, nil, nil))
+
+		for _, call := range message.ToolCalls {
+			numTokens += tokenizer.Count(call.Function.Name + call.Function.Arguments)
+		}
+
+		numTokens += tokenizer.Count(message.Role)
 	}
-	numTokens += 3
+	numTokens += replyPrimingTokens
 	return numTokens, nil
 }
+
+// ToolsTokenCount 估算工具定义占用的 token 数量，工具定义会作为上下文的一部分发送给模型
+func ToolsTokenCount(tools []Tool, model config.Model) int {
+	if len(tools) == 0 {
+		return 0
+	}
+
+	data, err := json.Marshal(tools)
+	if err != nil {
+		return 0
+	}
+
+	num, _ := MessageTokenCount(Messages{{Role: "system", Content: string(data)}}, model)
+	return num
+}
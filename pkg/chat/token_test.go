@@ -0,0 +1,134 @@
+package chat
+
+import (
+	"github.com/mylxsw/aidea-chat-server/config"
+	"strings"
+	"testing"
+)
+
+// estimateModel 使用字符估算的模型，测试不依赖 tiktoken 编码文件
+var estimateModel = config.Model{ID: "test-model", Tokenizer: TokenizerEstimate}
+
+func roles(messages Messages) string {
+	res := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		res = append(res, msg.Role+":"+msg.Content)
+	}
+
+	return strings.Join(res, ",")
+}
+
+func TestReduceContextByTokens(t *testing.T) {
+	long := strings.Repeat("a", 400)
+
+	testCases := []struct {
+		name      string
+		messages  Messages
+		maxTokens int
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "fits",
+			messages: Messages{
+				{Role: "system", Content: "sys"},
+				{Role: "user", Content: "q1"},
+				{Role: "assistant", Content: "a1"},
+				{Role: "user", Content: "q2"},
+			},
+			maxTokens: 1000,
+			want:      "system:sys,user:q1,assistant:a1,user:q2",
+		},
+		{
+			name: "drop earliest turn and keep system",
+			messages: Messages{
+				{Role: "system", Content: "sys"},
+				{Role: "user", Content: long},
+				{Role: "assistant", Content: long},
+				{Role: "user", Content: "q2"},
+			},
+			maxTokens: 20,
+			want:      "system:sys,user:q2",
+		},
+		{
+			name: "tool results are dropped with the assistant message",
+			messages: Messages{
+				{Role: "user", Content: long},
+				{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: Function{Name: "calculator", Arguments: `{"expression":"1+1"}`}}}},
+				{Role: "tool", Content: "2", ToolCallID: "call_1"},
+				{Role: "assistant", Content: "a1"},
+				{Role: "user", Content: "q2"},
+			},
+			maxTokens: 20,
+			want:      "user:q2",
+		},
+		{
+			name: "keep as many recent turns as possible",
+			messages: Messages{
+				{Role: "user", Content: long},
+				{Role: "assistant", Content: "a1"},
+				{Role: "user", Content: "q2"},
+				{Role: "assistant", Content: "a2"},
+				{Role: "user", Content: "q3"},
+			},
+			maxTokens: 50,
+			want:      "user:q2,assistant:a2,user:q3",
+		},
+		{
+			name: "last message is too long",
+			messages: Messages{
+				{Role: "system", Content: "sys"},
+				{Role: "user", Content: long},
+			},
+			maxTokens: 20,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reduced, count, err := ReduceContextByTokens(tc.messages, estimateModel, tc.maxTokens)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expect error, got %s", roles(reduced))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got := roles(reduced); got != tc.want {
+				t.Errorf("expect %s, got %s", tc.want, got)
+			}
+
+			expectCount, _ := MessageTokenCount(reduced, estimateModel)
+			if count != expectCount {
+				t.Errorf("token count should be %d, got %d", expectCount, count)
+			}
+
+			if count > tc.maxTokens {
+				t.Errorf("token count %d exceeds the limit %d", count, tc.maxTokens)
+			}
+		})
+	}
+}
+
+func TestReduceContextByCount(t *testing.T) {
+	messages := Messages{
+		{Role: "user", Content: "q1"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "q2"},
+		{Role: "assistant", Content: "a2"},
+		{Role: "user", Content: "q3"},
+	}
+
+	if got := roles(ReduceContextByCount(messages, 1)); got != "user:q2,assistant:a2,user:q3" {
+		t.Errorf("unexpected messages: %s", got)
+	}
+
+	if got := roles(ReduceContextByCount(messages, 5)); got != roles(messages) {
+		t.Errorf("unexpected messages: %s", got)
+	}
+}
@@ -0,0 +1,139 @@
+package chat
+
+import (
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/asteria/log"
+	"github.com/pkoukk/tiktoken-go"
+	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// TokenizerCL100K tiktoken cl100k_base, used by gpt-3.5-turbo/gpt-4
+	TokenizerCL100K = "cl100k_base"
+	// TokenizerO200K tiktoken o200k_base, used by gpt-4o
+	TokenizerO200K = "o200k_base"
+	// TokenizerP50K tiktoken p50k_base
+	TokenizerP50K = "p50k_base"
+	// TokenizerR50K tiktoken r50k_base
+	TokenizerR50K = "r50k_base"
+	// TokenizerEstimate character based estimation, for models without a public tokenizer
+	TokenizerEstimate = "estimate"
+)
+
+// Tokenizer 计算文本的 token 数量
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// TiktokenTokenizer 使用 tiktoken 编码计算 token 数量
+type TiktokenTokenizer struct {
+	tkm *tiktoken.Tiktoken
+}
+
+func (t TiktokenTokenizer) Count(text string) int {
+	return len(t.tkm.Encode(text, nil, nil))
+}
+
+// EstimateTokenizer 按照字符数估算 token 数量：CJK 等非 ASCII 字符每个字符按 1 个 token 计算，ASCII 字符每 4 个字符按 1 个 token 计算
+type EstimateTokenizer struct{}
+
+func (EstimateTokenizer) Count(text string) int {
+	var ascii, others int
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else if !unicode.IsSpace(r) {
+			others++
+		}
+	}
+
+	return others + (ascii+3)/4
+}
+
+// 使用内置的编码文件，避免运行时从网络下载
+func init() {
+	tiktoken.SetBpeLoader(tiktokenloader.NewOfflineLoader())
+}
+
+// tiktokenEncodings tokenizer name => tiktoken encoding name
+var tiktokenEncodings = map[string]string{
+	TokenizerCL100K: tiktoken.MODEL_CL100K_BASE,
+	TokenizerO200K:  tiktoken.MODEL_O200K_BASE,
+	TokenizerP50K:   tiktoken.MODEL_P50K_BASE,
+	TokenizerR50K:   tiktoken.MODEL_R50K_BASE,
+}
+
+// tokenizerRetryInterval 编码加载失败后，重新尝试加载的间隔
+const tokenizerRetryInterval = 5 * time.Minute
+
+var (
+	tokenizers     = make(map[string]Tokenizer)
+	tokenizersLock sync.Mutex
+	// tokenizerFailedAt tokenizer name => the time when loading failed
+	tokenizerFailedAt = make(map[string]time.Time)
+)
+
+// GetTokenizer 获取指定名称的 Tokenizer，未知的名称或者编码加载失败时使用字符估算
+func GetTokenizer(name string) Tokenizer {
+	tokenizersLock.Lock()
+	defer tokenizersLock.Unlock()
+
+	if tokenizer, ok := tokenizers[name]; ok {
+		return tokenizer
+	}
+
+	var tokenizer Tokenizer = EstimateTokenizer{}
+	if encoding, ok := tiktokenEncodings[name]; ok {
+		if failedAt, ok := tokenizerFailedAt[name]; ok && time.Since(failedAt) < tokenizerRetryInterval {
+			return tokenizer
+		}
+
+		tkm, err := tiktoken.GetEncoding(encoding)
+		if err != nil {
+			// 加载失败时不缓存，一段时间后重新尝试加载
+			log.F(log.M{"tokenizer": name}).Errorf("load tiktoken encoding failed, fallback to estimation: %s", err)
+			tokenizerFailedAt[name] = time.Now()
+			return tokenizer
+		}
+
+		tokenizer = TiktokenTokenizer{tkm: tkm}
+	} else if name != TokenizerEstimate {
+		log.F(log.M{"tokenizer": name}).Warningf("unknown tokenizer, fallback to estimation")
+	}
+
+	tokenizers[name] = tokenizer
+	return tokenizer
+}
+
+// TokenizerForModel 获取模型使用的 Tokenizer，未配置时 OpenAI 的模型使用 tiktoken 对应的编码，其它模型使用 cl100k_base
+func TokenizerForModel(model config.Model) Tokenizer {
+	return GetTokenizer(tokenizerNameForModel(model))
+}
+
+// tokenizerNameForModel 获取模型使用的 Tokenizer 名称
+func tokenizerNameForModel(model config.Model) string {
+	if model.Tokenizer != "" {
+		return model.Tokenizer
+	}
+
+	encoding, ok := tiktoken.MODEL_TO_ENCODING[model.ID]
+	if !ok {
+		for prefix, enc := range tiktoken.MODEL_PREFIX_TO_ENCODING {
+			if strings.HasPrefix(model.ID, prefix) {
+				encoding = enc
+				break
+			}
+		}
+	}
+
+	if _, ok := tiktokenEncodings[encoding]; ok {
+		return encoding
+	}
+
+	return TokenizerCL100K
+}
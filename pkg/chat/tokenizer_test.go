@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"github.com/mylxsw/aidea-chat-server/config"
+	"testing"
+)
+
+func TestTokenizerNameForModel(t *testing.T) {
+	testCases := []struct {
+		model config.Model
+		want  string
+	}{
+		{model: config.Model{ID: "gpt-4o"}, want: TokenizerO200K},
+		{model: config.Model{ID: "gpt-4o-mini"}, want: TokenizerO200K},
+		{model: config.Model{ID: "gpt-4o-2024-05-13"}, want: TokenizerO200K},
+		{model: config.Model{ID: "gpt-4"}, want: TokenizerCL100K},
+		{model: config.Model{ID: "gpt-4-turbo"}, want: TokenizerCL100K},
+		{model: config.Model{ID: "gpt-3.5-turbo-0125"}, want: TokenizerCL100K},
+		{model: config.Model{ID: "claude-3-opus"}, want: TokenizerCL100K},
+		{model: config.Model{ID: "gpt-4o", Tokenizer: TokenizerEstimate}, want: TokenizerEstimate},
+		{model: config.Model{ID: "glm-4", Tokenizer: TokenizerO200K}, want: TokenizerO200K},
+	}
+
+	for _, tc := range testCases {
+		if got := tokenizerNameForModel(tc.model); got != tc.want {
+			t.Errorf("%s: expect %s, got %s", tc.model.ID, tc.want, got)
+		}
+	}
+}
+
+func TestEstimateTokenizer_Count(t *testing.T) {
+	testCases := []struct {
+		text string
+		want int
+	}{
+		{text: "", want: 0},
+		{text: "abc", want: 1},
+		{text: "abcd", want: 1},
+		{text: "abcde", want: 2},
+		{text: "你好世界", want: 4},
+		{text: "你好 world", want: 4},
+	}
+
+	for _, tc := range testCases {
+		if got := (EstimateTokenizer{}).Count(tc.text); got != tc.want {
+			t.Errorf("%q: expect %d, got %d", tc.text, tc.want, got)
+		}
+	}
+}
+
+func TestGetTokenizer_Unknown(t *testing.T) {
+	if _, ok := GetTokenizer("unknown-tokenizer").(EstimateTokenizer); !ok {
+		t.Error("unknown tokenizer should fallback to estimation")
+	}
+}
+
+func TestGetTokenizer_Tiktoken(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: TokenizerCL100K, text: "hello world", want: 2},
+		{name: TokenizerCL100K, text: "tiktoken is great!", want: 6},
+		{name: TokenizerO200K, text: "hello world", want: 2},
+		{name: TokenizerO200K, text: "你好，世界", want: 3},
+	}
+
+	for _, tc := range testCases {
+		tokenizer, ok := GetTokenizer(tc.name).(TiktokenTokenizer)
+		if !ok {
+			t.Fatalf("%s: expect tiktoken tokenizer, got %T", tc.name, GetTokenizer(tc.name))
+		}
+
+		if got := tokenizer.Count(tc.text); got != tc.want {
+			t.Errorf("%s %q: expect %d tokens, got %d", tc.name, tc.text, tc.want, got)
+		}
+	}
+
+	// o200k_base 的词表更大，中文使用更少的 token
+	if cl100k, o200k := GetTokenizer(TokenizerCL100K).Count("你好，世界"), GetTokenizer(TokenizerO200K).Count("你好，世界"); o200k >= cl100k {
+		t.Errorf("o200k_base should use fewer tokens than cl100k_base for chinese, got %d and %d", o200k, cl100k)
+	}
+}
@@ -0,0 +1,110 @@
+package chat
+
+import (
+	"context"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/aidea-chat-server/pkg/tools"
+	"github.com/mylxsw/asteria/log"
+	"sort"
+	"time"
+)
+
+// maxToolRounds 内置工具调用的最大轮次，避免模型反复调用工具
+const maxToolRounds = 5
+
+// toolCallTimeout 单次内置工具调用的超时时间
+const toolCallTimeout = 30 * time.Second
+
+// serverTools 返回机器人启用的内置工具，名称 => 工具
+func (chat *Chatter) serverTools(robot *repo.Robot) map[string]tools.Tool {
+	enabled := make(map[string]tools.Tool)
+	for _, tool := range chat.tools.Tools(robot.RobotMeta.Tools) {
+		enabled[tool.Name()] = tool
+	}
+
+	return enabled
+}
+
+// toolDefinitions 合并客户端提供的工具以及内置工具，客户端工具优先
+func toolDefinitions(clientTools []Tool, serverTools map[string]tools.Tool) []Tool {
+	definitions := append(make([]Tool, 0, len(clientTools)+len(serverTools)), clientTools...)
+	names := make([]string, 0, len(serverTools))
+	for name := range serverTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tool := serverTools[name]
+		definitions = append(definitions, Tool{
+			Type: "function",
+			Function: &FunctionDefinition{
+				Name:        tool.Name(),
+				Description: tool.Description(),
+				Parameters:  tool.Parameters(),
+			},
+		})
+	}
+
+	return definitions
+}
+
+// isServerToolCalls 模型调用的是否全部为内置工具，只要有一个客户端工具，所有的工具调用都交给客户端处理
+func isServerToolCalls(calls []ToolCall, serverTools map[string]tools.Tool) bool {
+	if len(calls) == 0 {
+		return false
+	}
+
+	for _, call := range calls {
+		if _, ok := serverTools[call.Function.Name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// callTool 执行内置工具，执行过程通过独立的消息帧告知客户端，返回给模型的工具执行结果
+func (chat *Chatter) callTool(ctx context.Context, call ToolCall, res chan<- StreamResponse, usage *Usage) string {
+	step := ToolStep{
+		ID:        call.ID,
+		Name:      call.Function.Name,
+		Arguments: call.Function.Arguments,
+		Status:    ToolStepStatusRunning,
+	}
+	if !sendToolStep(ctx, res, step) {
+		return "Error: " + ctx.Err().Error()
+	}
+
+	toolCtx, cancel := context.WithTimeout(ctx, toolCallTimeout)
+	defer cancel()
+
+	result, err := chat.tools.Call(toolCtx, call.Function.Name, call.Function.Arguments)
+	if err != nil {
+		log.F(log.M{"tool": call.Function.Name, "arguments": call.Function.Arguments}).Warningf("call built-in tool failed: %s", err)
+
+		step.Status, step.Error = ToolStepStatusFailed, err.Error()
+		sendToolStep(ctx, res, step)
+
+		return "Error: " + err.Error()
+	}
+
+	// 只有执行成功的工具调用才计费
+	usage.Tools = append(usage.Tools, call.Function.Name)
+	usage.ToolQuota += chat.conf.ToolPrices[call.Function.Name]
+
+	step.Status, step.Result = ToolStepStatusSucceed, result
+	sendToolStep(ctx, res, step)
+
+	return result
+}
+
+// sendToolStep 告知客户端工具的执行过程，调用方停止读取（context 被取消）时返回 false
+func sendToolStep(ctx context.Context, res chan<- StreamResponse, step ToolStep) bool {
+	select {
+	case res <- NewToolStepStreamResponse(step.ID, step):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,93 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
+	"github.com/sashabaranov/go-openai"
+	"strings"
+	"time"
+)
+
+// embeddingBatchSize 每次请求最多生成的向量数量
+const embeddingBatchSize = 64
+
+// Embedder 文本向量化
+type Embedder interface {
+	// Embed returns the embedding vectors of the texts, in the same order as the input,
+	// and the number of tokens consumed
+	Embed(ctx context.Context, texts []string) ([][]float32, int64, error)
+}
+
+// OpenAIEmbedder 使用 OpenAI 兼容的 Embedding 接口生成向量
+type OpenAIEmbedder struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAIEmbedder create a new OpenAIEmbedder
+func NewOpenAIEmbedder(ch config.Channel, model string, pp *proxy.Proxy) *OpenAIEmbedder {
+	conf := openai.DefaultConfig(ch.APIKey)
+	conf.BaseURL = ch.ServerURL
+	conf.OrgID = ch.Organization
+	conf.HTTPClient.Timeout = 60 * time.Second
+	conf.HTTPClient.Transport = pp.BuildTransport()
+
+	if ch.UseAzure {
+		conf.APIType = openai.APITypeAzure
+		conf.APIVersion = ch.AzureAPIVersion
+		conf.AzureModelMapperFunc = func(model string) string {
+			if v, ok := ch.AzureModelMapping[model]; ok {
+				return v
+			}
+
+			return model
+		}
+	}
+
+	return &OpenAIEmbedder{client: openai.NewClientWithConfig(conf), model: model}
+}
+
+func (embedder *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, int64, error) {
+	vectors := make([][]float32, len(texts))
+	var tokens int64
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := start + embeddingBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		input := make([]string, 0, end-start)
+		for _, text := range texts[start:end] {
+			// OpenAI suggests replacing newlines with a single space
+			input = append(input, strings.ReplaceAll(text, "\n", " "))
+		}
+
+		resp, err := embedder.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+			Input: input,
+			Model: openai.EmbeddingModel(embedder.model),
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("create embeddings failed: %w", err)
+		}
+
+		tokens += int64(resp.Usage.TotalTokens)
+
+		for _, item := range resp.Data {
+			if item.Index < 0 || start+item.Index >= end {
+				return nil, 0, fmt.Errorf("invalid embedding index: %d", item.Index)
+			}
+
+			vectors[start+item.Index] = item.Embedding
+		}
+	}
+
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, 0, fmt.Errorf("missing embedding for text %d", i)
+		}
+	}
+
+	return vectors, tokens, nil
+}
@@ -0,0 +1,239 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/internal/coins"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/go-utils/array"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEmptyDocument      = errors.New("document content is empty")
+	ErrDisabled           = errors.New("knowledge base is not enabled")
+	ErrChunkLimitExceeded = errors.New("the knowledge base is full, delete some documents and try again")
+)
+
+// Reference 检索到的知识库内容，用于注入 Prompt 以及展示引用来源
+type Reference struct {
+	KBID       string  `json:"kb_id"`
+	DocumentID int64   `json:"document_id"`
+	Title      string  `json:"title"`
+	Content    string  `json:"content"`
+	Score      float64 `json:"score"`
+}
+
+// DocumentRepo 知识库文档的持久化，由 repo.KnowledgeRepo 实现
+type DocumentRepo interface {
+	CreateDocument(ctx context.Context, doc repo.KnowledgeDocument) (*repo.KnowledgeDocument, error)
+	GetDocuments(ctx context.Context, userID int64, kbID string) ([]repo.KnowledgeDocument, error)
+	GetDocumentsByIDs(ctx context.Context, ids []int64) ([]repo.KnowledgeDocument, error)
+	DeleteDocument(ctx context.Context, userID int64, kbID string, id int64) error
+	DeleteKnowledgeBase(ctx context.Context, userID int64, kbID string) error
+}
+
+// QuotaConsumer 扣除用户的智慧果，由 repo.QuotaRepo 实现
+type QuotaConsumer interface {
+	QuotaConsume(ctx context.Context, userID int64, used int64, meta repo.QuotaUsedMeta) error
+}
+
+// Knowledge 知识库服务，负责文档分块、向量化以及检索
+type Knowledge struct {
+	conf     config.Knowledge
+	repo     DocumentRepo
+	quota    QuotaConsumer
+	embedder Embedder
+	store    VectorStore
+}
+
+// NewKnowledge create a new knowledge base service
+func NewKnowledge(conf config.Knowledge, rp DocumentRepo, quota QuotaConsumer, embedder Embedder, store VectorStore) *Knowledge {
+	return &Knowledge{conf: conf, repo: rp, quota: quota, embedder: embedder, store: store}
+}
+
+// NewDisabledKnowledge create a knowledge base service that is not configured,
+// searching returns no references and adding documents returns ErrDisabled
+func NewDisabledKnowledge(conf config.Knowledge, rp DocumentRepo) *Knowledge {
+	return &Knowledge{conf: conf, repo: rp}
+}
+
+// Enabled whether the embedding channel and vector store are configured
+func (kb *Knowledge) Enabled() bool {
+	return kb.embedder != nil && kb.store != nil
+}
+
+// AddDocument 添加文档到知识库，文档会被分块并向量化，生成向量消耗的 Token 按照 EmbeddingPrice 扣除智慧果
+func (kb *Knowledge) AddDocument(ctx context.Context, userID int64, kbID, title, contentType, content string) (*repo.KnowledgeDocument, error) {
+	if !kb.Enabled() {
+		return nil, ErrDisabled
+	}
+
+	segments := array.Filter(
+		array.Map(misc.TextSplit(content, kb.conf.ChunkSize), func(item string, _ int) string { return strings.TrimSpace(item) }),
+		func(item string, _ int) bool { return item != "" },
+	)
+	if len(segments) == 0 {
+		return nil, ErrEmptyDocument
+	}
+
+	// 检索时需要加载知识库的所有分块，上传时限制分块数量
+	docs, err := kb.repo.GetDocuments(ctx, userID, kbID)
+	if err != nil {
+		return nil, fmt.Errorf("query documents failed: %w", err)
+	}
+
+	chunkCount := int64(len(segments))
+	for _, doc := range docs {
+		chunkCount += doc.ChunkCount
+	}
+
+	if chunkCount > kb.conf.MaxChunks {
+		return nil, fmt.Errorf("%w: %d chunks at most", ErrChunkLimitExceeded, kb.conf.MaxChunks)
+	}
+
+	// 先完成向量化，避免生成向量失败时留下没有分块的文档
+	vectors, tokens, err := kb.embedder.Embed(ctx, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	// 向量已经生成，无论文档是否保存成功都需要扣费
+	defer kb.chargeEmbedding(userID, tokens)
+
+	doc, err := kb.repo.CreateDocument(ctx, repo.KnowledgeDocument{
+		KBID:          kbID,
+		UserID:        userID,
+		Title:         title,
+		ContentType:   contentType,
+		ContentLength: int64(len([]rune(content))),
+		ChunkCount:    int64(len(segments)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create document failed: %w", err)
+	}
+
+	chunks := make([]repo.KnowledgeChunk, 0, len(segments))
+	for i, segment := range segments {
+		chunks = append(chunks, repo.KnowledgeChunk{
+			KBID:       kbID,
+			DocumentID: doc.ID,
+			Seq:        int64(i),
+			Content:    segment,
+			Embedding:  vectors[i],
+		})
+	}
+
+	if err := kb.store.Add(ctx, chunks); err != nil {
+		if err := kb.repo.DeleteDocument(ctx, userID, kbID, doc.ID); err != nil {
+			log.F(log.M{"kb_id": kbID, "document_id": doc.ID}).Errorf("rollback document failed: %s", err)
+		}
+
+		return nil, fmt.Errorf("save document chunks failed: %w", err)
+	}
+
+	return doc, nil
+}
+
+// chargeEmbedding 按照 Token 数量扣除生成向量消耗的智慧果，扣费失败不影响文档的上传
+func (kb *Knowledge) chargeEmbedding(userID int64, tokens int64) {
+	cost := coins.GetTextModelCoins(config.Model{ID: kb.conf.EmbeddingModel, Price: kb.conf.EmbeddingPrice}, tokens)
+	if cost <= 0 || kb.quota == nil {
+		return
+	}
+
+	// the request context may have been canceled by the client
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := kb.quota.QuotaConsume(ctx, userID, cost, repo.NewQuotaUsedMeta("knowledge", kb.conf.EmbeddingModel)); err != nil {
+		log.F(log.M{"user_id": userID, "tokens": tokens, "cost": cost}).Errorf("consume knowledge embedding quota failed: %s", err)
+	}
+}
+
+// DeleteDocument 删除知识库文档
+func (kb *Knowledge) DeleteDocument(ctx context.Context, userID int64, kbID string, id int64) error {
+	if err := kb.repo.DeleteDocument(ctx, userID, kbID, id); err != nil {
+		return err
+	}
+
+	if kb.store == nil {
+		return nil
+	}
+
+	return kb.store.DeleteDocument(ctx, kbID, id)
+}
+
+// DeleteKnowledgeBase 删除知识库
+func (kb *Knowledge) DeleteKnowledgeBase(ctx context.Context, userID int64, kbID string) error {
+	if err := kb.repo.DeleteKnowledgeBase(ctx, userID, kbID); err != nil {
+		return err
+	}
+
+	if kb.store == nil {
+		return nil
+	}
+
+	return kb.store.DeleteKnowledgeBase(ctx, kbID)
+}
+
+// Search 在知识库中检索与 query 最相关的内容，知识库没有启用时不返回任何内容
+func (kb *Knowledge) Search(ctx context.Context, kbIDs []string, query string) ([]Reference, error) {
+	query = strings.TrimSpace(query)
+	if !kb.Enabled() || len(kbIDs) == 0 || query == "" {
+		return []Reference{}, nil
+	}
+
+	vectors, _, err := kb.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := kb.store.Search(ctx, kbIDs, vectors[0], kb.conf.TopK)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks = array.Filter(chunks, func(item ScoredChunk, _ int) bool { return item.Score >= kb.conf.MinScore })
+	if len(chunks) == 0 {
+		return []Reference{}, nil
+	}
+
+	docs, err := kb.repo.GetDocumentsByIDs(ctx, array.Uniq(array.Map(chunks, func(item ScoredChunk, _ int) int64 { return item.DocumentID })))
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[int64]string)
+	for _, doc := range docs {
+		titles[doc.ID] = doc.Title
+	}
+
+	return array.Map(chunks, func(item ScoredChunk, _ int) Reference {
+		return Reference{
+			KBID:       item.KBID,
+			DocumentID: item.DocumentID,
+			Title:      titles[item.DocumentID],
+			Content:    item.Content,
+			Score:      item.Score,
+		}
+	}), nil
+}
+
+// BuildPrompt 把检索到的内容构建为 Prompt，追加到机器人的系统提示中
+func BuildPrompt(refs []Reference) string {
+	var sb strings.Builder
+	sb.WriteString("Answer the question with the help of the following knowledge base content. ")
+	sb.WriteString("If the content is not relevant to the question, ignore it.\n")
+
+	for i, ref := range refs {
+		sb.WriteString(fmt.Sprintf("\n[%d] %s\n%s\n", i+1, ref.Title, ref.Content))
+	}
+
+	return sb.String()
+}
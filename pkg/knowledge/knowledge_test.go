@@ -0,0 +1,216 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"reflect"
+	"testing"
+)
+
+type stubDocumentRepo struct {
+	docs   map[int64]repo.KnowledgeDocument
+	nextID int64
+}
+
+func newStubDocumentRepo() *stubDocumentRepo {
+	return &stubDocumentRepo{docs: make(map[int64]repo.KnowledgeDocument)}
+}
+
+func (rp *stubDocumentRepo) CreateDocument(_ context.Context, doc repo.KnowledgeDocument) (*repo.KnowledgeDocument, error) {
+	rp.nextID++
+	doc.ID = rp.nextID
+	rp.docs[doc.ID] = doc
+	return &doc, nil
+}
+
+func (rp *stubDocumentRepo) GetDocuments(_ context.Context, userID int64, kbID string) ([]repo.KnowledgeDocument, error) {
+	docs := make([]repo.KnowledgeDocument, 0)
+	for _, doc := range rp.docs {
+		if doc.UserID == userID && doc.KBID == kbID {
+			docs = append(docs, doc)
+		}
+	}
+
+	return docs, nil
+}
+
+func (rp *stubDocumentRepo) GetDocumentsByIDs(_ context.Context, ids []int64) ([]repo.KnowledgeDocument, error) {
+	docs := make([]repo.KnowledgeDocument, 0)
+	for _, id := range ids {
+		if doc, ok := rp.docs[id]; ok {
+			docs = append(docs, doc)
+		}
+	}
+
+	return docs, nil
+}
+
+func (rp *stubDocumentRepo) DeleteDocument(_ context.Context, _ int64, _ string, id int64) error {
+	if _, ok := rp.docs[id]; !ok {
+		return repo.ErrNotFound
+	}
+
+	delete(rp.docs, id)
+	return nil
+}
+
+func (rp *stubDocumentRepo) DeleteKnowledgeBase(context.Context, int64, string) error {
+	return nil
+}
+
+// stubEmbedder 文本的向量为 vectors 中对应的值，每个文本消耗 10 个 Token
+type stubEmbedder struct {
+	vectors map[string][]float32
+	err     error
+}
+
+func (embedder stubEmbedder) Embed(_ context.Context, texts []string) ([][]float32, int64, error) {
+	if embedder.err != nil {
+		return nil, 0, embedder.err
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vector, ok := embedder.vectors[text]
+		if !ok {
+			vector = []float32{1, 1}
+		}
+
+		vectors = append(vectors, vector)
+	}
+
+	return vectors, int64(len(texts) * 10), nil
+}
+
+type stubQuota struct {
+	consumed map[int64]int64
+}
+
+func (quota *stubQuota) QuotaConsume(_ context.Context, userID int64, used int64, _ repo.QuotaUsedMeta) error {
+	quota.consumed[userID] += used
+	return nil
+}
+
+// failedStore 保存分块总是失败
+type failedStore struct {
+	*MemoryStore
+}
+
+func (failedStore) Add(context.Context, []repo.KnowledgeChunk) error {
+	return errors.New("store is unavailable")
+}
+
+func newTestKnowledge(embedder Embedder, store VectorStore) (*Knowledge, *stubDocumentRepo, *stubQuota) {
+	conf := config.Knowledge{ChunkSize: 5, TopK: 2, MinScore: 0.5, MaxChunks: 4, EmbeddingModel: "test-embedding", EmbeddingPrice: 100}
+	rp, quota := newStubDocumentRepo(), &stubQuota{consumed: make(map[int64]int64)}
+
+	return NewKnowledge(conf, rp, quota, embedder, store), rp, quota
+}
+
+func TestKnowledge_AddDocument(t *testing.T) {
+	kb, rp, quota := newTestKnowledge(stubEmbedder{}, NewMemoryStore())
+
+	doc, err := kb.AddDocument(context.Background(), 1, "kb1", "doc", "text", "hello world")
+	if err != nil {
+		t.Fatalf("add document failed: %v", err)
+	}
+
+	// "hello", " worl", "d" 三个分块，共 30 个 Token，每 1K Token 100 个智慧果
+	if doc.ChunkCount != 3 || len(rp.docs) != 1 {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	if quota.consumed[1] != 3 {
+		t.Errorf("expect 3 coins consumed, got %d", quota.consumed[1])
+	}
+
+	// 超出知识库的分块数量限制
+	if _, err := kb.AddDocument(context.Background(), 1, "kb1", "doc2", "text", "hello world"); !errors.Is(err, ErrChunkLimitExceeded) {
+		t.Errorf("expect chunk limit exceeded, got %v", err)
+	}
+
+	if len(rp.docs) != 1 || quota.consumed[1] != 3 {
+		t.Errorf("rejected document should not be saved or charged: %d documents, %d coins", len(rp.docs), quota.consumed[1])
+	}
+
+	// 分块数量按照知识库分别计算
+	if _, err := kb.AddDocument(context.Background(), 1, "kb2", "doc2", "text", "hello world"); err != nil {
+		t.Errorf("add document to another knowledge base failed: %v", err)
+	}
+}
+
+func TestKnowledge_AddDocumentRollback(t *testing.T) {
+	// 生成向量失败时不创建文档，也不扣费
+	kb, rp, quota := newTestKnowledge(stubEmbedder{err: errors.New("embedding failed")}, NewMemoryStore())
+	if _, err := kb.AddDocument(context.Background(), 1, "kb1", "doc", "text", "hello world"); err == nil {
+		t.Fatal("expect error, got nil")
+	}
+
+	if len(rp.docs) != 0 || quota.consumed[1] != 0 {
+		t.Errorf("expect nothing saved or charged: %d documents, %d coins", len(rp.docs), quota.consumed[1])
+	}
+
+	// 保存分块失败时删除已经创建的文档，已经生成的向量仍然扣费
+	kb, rp, quota = newTestKnowledge(stubEmbedder{}, failedStore{NewMemoryStore()})
+	if _, err := kb.AddDocument(context.Background(), 1, "kb1", "doc", "text", "hello world"); err == nil {
+		t.Fatal("expect error, got nil")
+	}
+
+	if len(rp.docs) != 0 {
+		t.Errorf("document should be rolled back, got %d documents", len(rp.docs))
+	}
+
+	if quota.consumed[1] != 3 {
+		t.Errorf("expect 3 coins consumed, got %d", quota.consumed[1])
+	}
+}
+
+func TestKnowledge_Search(t *testing.T) {
+	embedder := stubEmbedder{vectors: map[string][]float32{
+		"aaaaa": {1, 0},
+		"bbbbb": {0, 1},
+		"ccccc": {1, 0.2},
+		"query": {1, 0},
+	}}
+
+	kb, _, _ := newTestKnowledge(embedder, NewMemoryStore())
+	if _, err := kb.AddDocument(context.Background(), 1, "kb1", "doc", "text", "aaaaabbbbbccccc"); err != nil {
+		t.Fatalf("add document failed: %v", err)
+	}
+
+	refs, err := kb.Search(context.Background(), []string{"kb1"}, "query")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	// 按照相似度排序，最多返回 TopK 个
+	contents := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		contents = append(contents, ref.Content)
+	}
+
+	if !reflect.DeepEqual(contents, []string{"aaaaa", "ccccc"}) {
+		t.Errorf("unexpected references: %v", contents)
+	}
+
+	if refs[0].Title != "doc" || refs[0].Score < refs[1].Score {
+		t.Errorf("unexpected reference: %+v", refs[0])
+	}
+
+	// 相似度低于 MinScore 的分块被忽略
+	kb.conf.TopK = 3
+	refs, err = kb.Search(context.Background(), []string{"kb1"}, "query")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	if len(refs) != 2 {
+		t.Errorf("chunks below the min score should be ignored, got %d references", len(refs))
+	}
+
+	if refs, _ := kb.Search(context.Background(), []string{"kb2"}, "query"); len(refs) != 0 {
+		t.Errorf("expect no references from another knowledge base, got %v", refs)
+	}
+}
@@ -0,0 +1,48 @@
+package knowledge
+
+import (
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/proxy"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+)
+
+type Provider struct{}
+
+func (Provider) Register(binder infra.Binder) {
+	// 知识库是可选功能，配置不正确时禁用知识库，不影响聊天等其它功能
+	binder.MustSingleton(func(conf *config.Config, rp *repo.Repository, pp *proxy.Proxy) *Knowledge {
+		kb, err := newKnowledge(conf, rp, pp)
+		if err != nil {
+			log.Warningf("knowledge base is disabled: %s", err)
+			return NewDisabledKnowledge(conf.Knowledge, rp.Knowledge)
+		}
+
+		return kb
+	})
+}
+
+func newKnowledge(conf *config.Config, rp *repo.Repository, pp *proxy.Proxy) (*Knowledge, error) {
+	ch, ok := conf.Channel(conf.Knowledge.Channel)
+	if !ok {
+		return nil, fmt.Errorf("knowledge embedding channel not found: %s", conf.Knowledge.Channel)
+	}
+
+	if ch.Type != config.ChannelTypeOpenAI {
+		return nil, fmt.Errorf("knowledge embedding channel must be openai type: %s", ch.Name)
+	}
+
+	var store VectorStore
+	switch conf.Knowledge.Store {
+	case config.KnowledgeStoreMySQL:
+		store = NewMySQLStore(rp.Knowledge, conf.Knowledge.MaxChunks)
+	case config.KnowledgeStoreMemory:
+		store = NewMemoryStore()
+	default:
+		return nil, fmt.Errorf("unsupported knowledge store: %s", conf.Knowledge.Store)
+	}
+
+	return NewKnowledge(conf.Knowledge, rp.Knowledge, rp.Quota, NewOpenAIEmbedder(ch, conf.Knowledge.EmbeddingModel, pp), store), nil
+}
@@ -0,0 +1,61 @@
+package knowledge
+
+import (
+	"context"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"math"
+	"sort"
+)
+
+// ScoredChunk 检索到的分块以及相似度
+type ScoredChunk struct {
+	repo.KnowledgeChunk
+	Score float64
+}
+
+// VectorStore 向量存储
+type VectorStore interface {
+	// Add save the chunks and their embeddings
+	Add(ctx context.Context, chunks []repo.KnowledgeChunk) error
+	// Search returns the topK chunks most similar to the vector in the specified knowledge bases
+	Search(ctx context.Context, kbIDs []string, vector []float32, topK int) ([]ScoredChunk, error)
+	// DeleteDocument delete all chunks of the document
+	DeleteDocument(ctx context.Context, kbID string, documentID int64) error
+	// DeleteKnowledgeBase delete all chunks of the knowledge base
+	DeleteKnowledgeBase(ctx context.Context, kbID string) error
+}
+
+// cosineSimilarity 计算两个向量的余弦相似度
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// topKChunks 计算所有分块与 vector 的相似度，返回最相似的 topK 个分块
+func topKChunks(chunks []repo.KnowledgeChunk, vector []float32, topK int) []ScoredChunk {
+	scored := make([]ScoredChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		scored = append(scored, ScoredChunk{KnowledgeChunk: chunk, Score: cosineSimilarity(chunk.Embedding, vector)})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	if len(scored) > topK {
+		scored = scored[:topK]
+	}
+
+	return scored
+}
@@ -0,0 +1,65 @@
+package knowledge
+
+import (
+	"context"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"sync"
+)
+
+// MemoryStore 进程内的向量索引，服务重启后数据丢失，仅用于开发测试
+type MemoryStore struct {
+	lock sync.RWMutex
+	// chunks kb_id => chunks
+	chunks map[string][]repo.KnowledgeChunk
+}
+
+// NewMemoryStore create a new MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{chunks: make(map[string][]repo.KnowledgeChunk)}
+}
+
+func (store *MemoryStore) Add(_ context.Context, chunks []repo.KnowledgeChunk) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	for _, chunk := range chunks {
+		store.chunks[chunk.KBID] = append(store.chunks[chunk.KBID], chunk)
+	}
+
+	return nil
+}
+
+func (store *MemoryStore) Search(_ context.Context, kbIDs []string, vector []float32, topK int) ([]ScoredChunk, error) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	chunks := make([]repo.KnowledgeChunk, 0)
+	for _, kbID := range kbIDs {
+		chunks = append(chunks, store.chunks[kbID]...)
+	}
+
+	return topKChunks(chunks, vector, topK), nil
+}
+
+func (store *MemoryStore) DeleteDocument(_ context.Context, kbID string, documentID int64) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	remain := make([]repo.KnowledgeChunk, 0, len(store.chunks[kbID]))
+	for _, chunk := range store.chunks[kbID] {
+		if chunk.DocumentID != documentID {
+			remain = append(remain, chunk)
+		}
+	}
+
+	store.chunks[kbID] = remain
+	return nil
+}
+
+func (store *MemoryStore) DeleteKnowledgeBase(_ context.Context, kbID string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	delete(store.chunks, kbID)
+	return nil
+}
@@ -0,0 +1,44 @@
+package knowledge
+
+import (
+	"context"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+)
+
+// MySQLStore 向量保存在 MySQL 中，检索时加载知识库的分块在进程内计算相似度，适用于中小规模的知识库
+//
+// 每次提问都会把分块以及向量加载到内存中，这种方式无法扩展到大规模的知识库，
+// 因此上传文档时每个知识库的分块数量限制为 maxChunks，需要更大规模时应该使用专门的向量数据库
+type MySQLStore struct {
+	repo      *repo.KnowledgeRepo
+	maxChunks int64
+}
+
+// NewMySQLStore create a new MySQLStore
+func NewMySQLStore(rp *repo.KnowledgeRepo, maxChunks int64) *MySQLStore {
+	return &MySQLStore{repo: rp, maxChunks: maxChunks}
+}
+
+func (store *MySQLStore) Add(ctx context.Context, chunks []repo.KnowledgeChunk) error {
+	return store.repo.SaveChunks(ctx, chunks)
+}
+
+func (store *MySQLStore) Search(ctx context.Context, kbIDs []string, vector []float32, topK int) ([]ScoredChunk, error) {
+	// 每个知识库的分块数量在上传时已经限制，加载所有的分块参与检索
+	chunks, err := store.repo.GetChunks(ctx, kbIDs, store.maxChunks*int64(len(kbIDs)))
+	if err != nil {
+		return nil, err
+	}
+
+	return topKChunks(chunks, vector, topK), nil
+}
+
+// DeleteDocument 分块在删除文档时已经在同一个事务中删除
+func (store *MySQLStore) DeleteDocument(context.Context, string, int64) error {
+	return nil
+}
+
+// DeleteKnowledgeBase 分块在删除知识库时已经在同一个事务中删除
+func (store *MySQLStore) DeleteKnowledgeBase(context.Context, string) error {
+	return nil
+}
@@ -0,0 +1,62 @@
+package knowledge
+
+import (
+	"github.com/mylxsw/aidea-chat-server/pkg/repo"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{name: "same direction", a: []float32{1, 2, 3}, b: []float32{2, 4, 6}, want: 1},
+		{name: "opposite", a: []float32{1, 0}, b: []float32{-1, 0}, want: -1},
+		{name: "orthogonal", a: []float32{1, 0}, b: []float32{0, 1}, want: 0},
+		{name: "45 degrees", a: []float32{1, 0}, b: []float32{1, 1}, want: math.Sqrt2 / 2},
+		{name: "zero vector", a: []float32{0, 0}, b: []float32{1, 1}, want: 0},
+		{name: "dimension mismatch", a: []float32{1, 0}, b: []float32{1, 0, 0}, want: 0},
+		{name: "empty", a: []float32{}, b: []float32{}, want: 0},
+	}
+
+	for _, tc := range testCases {
+		if got := cosineSimilarity(tc.a, tc.b); math.Abs(got-tc.want) > 1e-6 {
+			t.Errorf("%s: expect %f, got %f", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestTopKChunks(t *testing.T) {
+	chunks := []repo.KnowledgeChunk{
+		{ID: 1, Embedding: []float32{0, 1}},
+		{ID: 2, Embedding: []float32{1, 0}},
+		{ID: 3, Embedding: []float32{1, 1}},
+		{ID: 4, Embedding: []float32{-1, 0}},
+		{ID: 5, Embedding: []float32{1, 0}},
+	}
+
+	ids := func(scored []ScoredChunk) []int64 {
+		res := make([]int64, 0, len(scored))
+		for _, item := range scored {
+			res = append(res, item.ID)
+		}
+
+		return res
+	}
+
+	// 相似度相同的分块保持原有的顺序
+	if got := ids(topKChunks(chunks, []float32{1, 0}, 3)); !reflect.DeepEqual(got, []int64{2, 5, 3}) {
+		t.Errorf("unexpected top 3 chunks: %v", got)
+	}
+
+	if got := ids(topKChunks(chunks, []float32{1, 0}, 10)); !reflect.DeepEqual(got, []int64{2, 5, 3, 1, 4}) {
+		t.Errorf("all chunks should be returned when topK is larger: %v", got)
+	}
+
+	if got := topKChunks(nil, []float32{1, 0}, 3); len(got) != 0 {
+		t.Errorf("expect no chunks, got %v", got)
+	}
+}
@@ -1,28 +1,49 @@
 package mail
 
 import (
+	"fmt"
 	"github.com/mylxsw/aidea-chat-server/config"
 	"gopkg.in/gomail.v2"
 )
 
 type Sender struct {
-	conf   config.Mail
-	dialer *gomail.Dialer
+	conf     config.Mail
+	dialer   *gomail.Dialer
+	renderer *Renderer
 }
 
-func NewSender(conf config.Mail) *Sender {
+func NewSender(conf config.Mail, renderer *Renderer) *Sender {
 	dialer := gomail.NewDialer(conf.Host, conf.Port, conf.Username, conf.Password)
 	dialer.SSL = conf.UseSSL
 
-	return &Sender{conf: conf, dialer: dialer}
+	return &Sender{conf: conf, dialer: dialer, renderer: renderer}
 }
 
-func (m *Sender) Send(to []string, subject, body string) error {
+// Send 发送邮件，html 不为空时，text 作为纯文本备选内容
+func (m *Sender) Send(to []string, subject, text, html string) error {
 	msg := gomail.NewMessage()
-	msg.SetAddressHeader("From", m.conf.Username, m.conf.From)
+	msg.SetAddressHeader("From", m.conf.FromAddress, m.conf.From)
 	msg.SetHeader("To", to...)
 	msg.SetHeader("Subject", subject)
-	msg.SetBody("text/plain", body)
+	msg.SetBody("text/plain", text)
+	if html != "" {
+		msg.AddAlternative("text/html", html)
+	}
 
 	return m.dialer.DialAndSend(msg)
 }
+
+// SendText 发送纯文本邮件，标题添加品牌前缀
+func (m *Sender) SendText(to []string, subject, body string) error {
+	return m.Send(to, fmt.Sprintf("【%s】%s", m.conf.Brand, subject), body, "")
+}
+
+// SendTemplate 使用客户端语言渲染邮件模板并发送
+func (m *Sender) SendTemplate(to []string, lang string, name string, data map[string]any) error {
+	msg, err := m.renderer.Render(lang, name, data)
+	if err != nil {
+		return err
+	}
+
+	return m.Send(to, msg.Subject, msg.Text, msg.HTML)
+}
@@ -8,7 +8,10 @@ import (
 type Provider struct{}
 
 func (Provider) Register(binder infra.Binder) {
-	binder.MustSingleton(func(conf *config.Config) *Sender {
-		return NewSender(conf.Mail)
+	binder.MustSingleton(func(conf *config.Config) *Renderer {
+		return NewRenderer(conf.Mail)
+	})
+	binder.MustSingleton(func(conf *config.Config, renderer *Renderer) *Sender {
+		return NewSender(conf.Mail, renderer)
 	})
 }
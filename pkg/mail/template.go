@@ -0,0 +1,198 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	htmltpl "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	texttpl "text/template"
+	"time"
+)
+
+const (
+	// TemplateVerifyCode 验证码邮件，参数为 Code、ExpireMinutes
+	TemplateVerifyCode = "verify_code"
+	// TemplateSignupVerifyCode 注册验证码邮件，参数为 Code、ExpireMinutes
+	TemplateSignupVerifyCode = "signup_verify_code"
+)
+
+const (
+	// LanguageZH 中文
+	LanguageZH = "zh"
+	// LanguageEN 英文
+	LanguageEN = "en"
+	// DefaultLanguage 客户端没有指定语言时使用的语言
+	DefaultLanguage = LanguageZH
+)
+
+// Templates 所有内置的邮件模板
+var Templates = []string{TemplateVerifyCode, TemplateSignupVerifyCode}
+
+// SampleData 模板的示例数据，用于预览模板
+func SampleData(name string) map[string]any {
+	switch name {
+	case TemplateVerifyCode, TemplateSignupVerifyCode:
+		return map[string]any{"Code": "123456", "ExpireMinutes": 10}
+	default:
+		return map[string]any{}
+	}
+}
+
+// ErrTemplateNotFound 邮件模板不存在
+var ErrTemplateNotFound = errors.New("mail template not found")
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// Message 渲染后的邮件
+type Message struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// TemplateData 模板中可用的变量
+type TemplateData struct {
+	Brand   string
+	Lang    string
+	Subject string
+	Year    int
+	Data    map[string]any
+}
+
+// Renderer 邮件模板渲染器
+//
+// 每个模板由 <lang>/<name>.txt 和 <lang>/<name>.html 两个文件组成：
+// txt 文件定义 subject 和 body（纯文本内容），html 文件定义 content，嵌入到 layout.html 中，
+// <lang>/footer.html 定义 footer。配置了模板目录时，优先使用目录中的文件
+type Renderer struct {
+	brand       string
+	templateDir string
+}
+
+// NewRenderer 创建邮件模板渲染器
+func NewRenderer(conf config.Mail) *Renderer {
+	return &Renderer{brand: conf.Brand, templateDir: conf.TemplateDir}
+}
+
+// Language 将客户端语言（例如 zh-CN、en_US）转换为模板语言，为空时使用默认语言，不支持的语言使用英文
+func Language(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	switch {
+	case lang == "":
+		return DefaultLanguage
+	case strings.HasPrefix(lang, LanguageZH):
+		return LanguageZH
+	default:
+		return LanguageEN
+	}
+}
+
+// Render 使用客户端语言渲染模板，模板没有对应语言的版本时使用默认语言
+func (r *Renderer) Render(lang string, name string, data map[string]any) (*Message, error) {
+	lang = Language(lang)
+	if !r.exists(path.Join(lang, name+".txt")) {
+		lang = DefaultLanguage
+	}
+
+	if !r.exists(path.Join(lang, name+".txt")) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	vars := TemplateData{Brand: r.brand, Lang: lang, Year: time.Now().Year(), Data: data}
+
+	text, err := r.read(path.Join(lang, name+".txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	textTpl, err := texttpl.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse mail template %s/%s.txt failed: %w", lang, name, err)
+	}
+
+	var msg Message
+	if msg.Subject, err = executeText(textTpl, "subject", vars); err != nil {
+		return nil, err
+	}
+	if msg.Text, err = executeText(textTpl, "body", vars); err != nil {
+		return nil, err
+	}
+
+	// html 是可选的，没有时只发送纯文本内容
+	if !r.exists(path.Join(lang, name+".html")) {
+		return &msg, nil
+	}
+
+	htmlTpl := htmltpl.New("mail").Option("missingkey=zero")
+	for _, file := range []string{"layout.html", path.Join(lang, "footer.html"), path.Join(lang, name+".html")} {
+		content, err := r.read(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := htmlTpl.New(file).Parse(content); err != nil {
+			return nil, fmt.Errorf("parse mail template %s failed: %w", file, err)
+		}
+	}
+
+	vars.Subject = msg.Subject
+
+	var buf bytes.Buffer
+	if err := htmlTpl.ExecuteTemplate(&buf, "layout.html", vars); err != nil {
+		return nil, fmt.Errorf("render mail template %s/%s.html failed: %w", lang, name, err)
+	}
+
+	msg.HTML = buf.String()
+
+	return &msg, nil
+}
+
+func executeText(tpl *texttpl.Template, name string, vars TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, name, vars); err != nil {
+		return "", fmt.Errorf("render mail template %s failed: %w", name, err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// exists 模板文件是否存在于模板目录或者内置模板中
+func (r *Renderer) exists(file string) bool {
+	if r.templateDir != "" {
+		if _, err := os.Stat(filepath.Join(r.templateDir, filepath.FromSlash(file))); err == nil {
+			return true
+		}
+	}
+
+	_, err := fs.Stat(builtinTemplates, path.Join("templates", file))
+	return err == nil
+}
+
+// read 读取模板文件，优先使用模板目录中的文件
+func (r *Renderer) read(file string) (string, error) {
+	if r.templateDir != "" {
+		data, err := os.ReadFile(filepath.Join(r.templateDir, filepath.FromSlash(file)))
+		if err == nil {
+			return string(data), nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	data, err := builtinTemplates.ReadFile(path.Join("templates", file))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,113 @@
+package mail
+
+import (
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTemplate 在模板目录中写入模板文件
+func writeTemplate(t *testing.T, dir, file, content string) {
+	t.Helper()
+
+	file = filepath.Join(dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	testCases := map[string]string{
+		"":      DefaultLanguage,
+		"zh":    LanguageZH,
+		"zh-CN": LanguageZH,
+		"zh_TW": LanguageZH,
+		"en-US": LanguageEN,
+		"fr":    LanguageEN,
+	}
+
+	for lang, want := range testCases {
+		if got := Language(lang); got != want {
+			t.Errorf("%q: expect %s, got %s", lang, want, got)
+		}
+	}
+}
+
+func TestRenderer_Builtin(t *testing.T) {
+	renderer := NewRenderer(config.Mail{Brand: "TestBrand"})
+
+	msg, err := renderer.Render("en-US", TemplateVerifyCode, map[string]any{"Code": "654321", "ExpireMinutes": 5})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if msg.Subject != "[TestBrand] Your verification code" {
+		t.Errorf("unexpected subject: %s", msg.Subject)
+	}
+
+	if !strings.Contains(msg.Text, "654321") || !strings.Contains(msg.HTML, "654321") || !strings.Contains(msg.HTML, "TestBrand") {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
+
+func TestRenderer_FallbackToDefaultLanguage(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, DefaultLanguage+"/notice.txt", `{{define "subject"}}通知{{end}}{{define "body"}}内容{{end}}`)
+
+	// 模板没有英文版本时，使用默认语言的版本
+	msg, err := NewRenderer(config.Mail{TemplateDir: dir}).Render("en", "notice", nil)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if msg.Subject != "通知" || msg.Text != "内容" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
+
+func TestRenderer_TextOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "en/notice.txt", `{{define "subject"}}[{{.Brand}}] Notice{{end}}{{define "body"}}Hello {{.Data.Name}}{{end}}`)
+
+	msg, err := NewRenderer(config.Mail{Brand: "TestBrand", TemplateDir: dir}).Render("en", "notice", map[string]any{"Name": "Tom"})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if msg.Subject != "[TestBrand] Notice" || msg.Text != "Hello Tom" || msg.HTML != "" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
+
+func TestRenderer_TemplateDirOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "zh/verify_code.txt", `{{define "subject"}}自定义验证码{{end}}{{define "body"}}验证码 {{.Data.Code}}{{end}}`)
+
+	msg, err := NewRenderer(config.Mail{Brand: "TestBrand", TemplateDir: dir}).Render("zh", TemplateVerifyCode, map[string]any{"Code": "654321"})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if msg.Subject != "自定义验证码" || msg.Text != "验证码 654321" {
+		t.Errorf("template dir should override the builtin template: %+v", msg)
+	}
+
+	// 模板目录中没有的文件使用内置模板
+	if !strings.Contains(msg.HTML, "654321") {
+		t.Errorf("builtin html template should be used: %s", msg.HTML)
+	}
+}
+
+func TestRenderer_TemplateNotFound(t *testing.T) {
+	_, err := NewRenderer(config.Mail{TemplateDir: t.TempDir()}).Render("en", "not_exists", nil)
+	if !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expect template not found, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package misc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// reservedNetworks 除了标准库可以判断的私有地址以外，其它不允许访问的网段
+var reservedNetworks = []*net.IPNet{
+	// "this" network
+	mustParseCIDR("0.0.0.0/8"),
+	// carrier-grade NAT, usually used as internal addresses in cloud VPCs
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+
+	return network
+}
+
+// IsPublicIP 是否为公网地址
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewPublicHTTPClient 创建只允许访问公网地址的 HTTP 客户端，用于请求用户提供的 URL，避免访问内网服务
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !IsPublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("access to address %s is not allowed", host)
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
+
+// CheckPublicURL 检查用户提供的 URL 是否为 http/https 协议，并且域名解析的所有地址都是公网地址
+//
+// 请求时仍然需要使用 NewPublicHTTPClient，避免域名解析结果在检查之后发生变化
+func CheckPublicURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("invalid url")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolve host %s failed: %w", u.Hostname(), err)
+	}
+
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return fmt.Errorf("access to address %s is not allowed", addr.IP)
+		}
+	}
+
+	return nil
+}
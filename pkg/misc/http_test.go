@@ -0,0 +1,75 @@
+package misc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	testCases := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "8.8.8.8", want: true},
+		{ip: "2606:4700:4700::1111", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "10.0.0.1", want: false},
+		{ip: "172.16.5.4", want: false},
+		{ip: "192.168.1.1", want: false},
+		{ip: "169.254.169.254", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "0.1.2.3", want: false},
+		{ip: "100.64.0.1", want: false},
+		{ip: "100.127.255.254", want: false},
+		{ip: "100.128.0.1", want: true},
+		{ip: "::1", want: false},
+		{ip: "::ffff:100.100.100.200", want: false},
+		{ip: "fd00::1", want: false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsPublicIP(net.ParseIP(tc.ip)); got != tc.want {
+			t.Errorf("%s: expect %v, got %v", tc.ip, tc.want, got)
+		}
+	}
+
+	if IsPublicIP(nil) {
+		t.Error("nil ip should not be public")
+	}
+}
+
+func TestCheckPublicURL(t *testing.T) {
+	testCases := []string{
+		"http://127.0.0.1:8080/v1",
+		"http://localhost/v1",
+		"https://[::1]/v1",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.8/v1",
+		"ftp://8.8.8.8/v1",
+		"http:///v1",
+		"not a url",
+	}
+
+	for _, rawURL := range testCases {
+		if err := CheckPublicURL(context.Background(), rawURL); err == nil {
+			t.Errorf("%s: expect error, got nil", rawURL)
+		}
+	}
+
+	if err := CheckPublicURL(context.Background(), "https://8.8.8.8/v1"); err != nil {
+		t.Errorf("public ip should be allowed: %v", err)
+	}
+}
+
+func TestNewPublicHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	if _, err := NewPublicHTTPClient(time.Second).Get(srv.URL); err == nil {
+		t.Error("request to loopback address should be rejected")
+	}
+}
@@ -109,6 +109,11 @@ func NewStreamWriter[T InitRequest[T]](useWebSocket bool, enableCors bool, r *ht
 	return sw, &req, nil
 }
 
+// NewSSEStreamWriter 创建 SSE 模式的 StreamWriter，用于调用方自行读取请求内容的场景
+func NewSSEStreamWriter(enableCors bool, r *http.Request, w http.ResponseWriter) *StreamWriter {
+	return &StreamWriter{r: r, w: w, enableCors: enableCors}
+}
+
 func (sw *StreamWriter) initSSE() {
 	if sw.ws != nil {
 		return
@@ -163,6 +168,44 @@ func (sw *StreamWriter) WriteStream(payload any) error {
 	return nil
 }
 
+// StreamControlCancel 客户端在 WebSocket 连接上发送 {"type": "cancel"} 取消正在生成的回复
+const StreamControlCancel = "cancel"
+
+// StreamControlMessage 客户端在 WebSocket 连接上发送的控制消息
+type StreamControlMessage struct {
+	Type string `json:"type"`
+}
+
+// OnCancel 持续读取 WebSocket 连接上的后续消息，收到取消消息时调用 cb，连接关闭后停止读取
+//
+// 只能调用一次，SSE 模式下客户端无法发送消息，需要使用取消接口
+func (sw *StreamWriter) OnCancel(cb func()) {
+	if sw.ws == nil {
+		return
+	}
+
+	go func() {
+		for {
+			_, data, err := sw.ws.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var msg StreamControlMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				if sw.debug {
+					log.Debugf("invalid websocket control message: %s", string(data))
+				}
+				continue
+			}
+
+			if msg.Type == StreamControlCancel {
+				cb()
+			}
+		}
+	}()
+}
+
 func (sw *StreamWriter) wrapRawResponse(w http.ResponseWriter, cb func()) {
 	// 允许跨域
 	if sw.enableCors {
@@ -0,0 +1,283 @@
+package payment
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	jwtlib "github.com/dgrijalva/jwt-go"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	appleProductionURL = "https://api.storekit.itunes.apple.com"
+	appleSandboxURL    = "https://api.storekit-sandbox.itunes.apple.com"
+)
+
+// appleRootCAFingerprints 交易信息签名证书链的根证书（Apple Root CA - G3）的 SHA-256 指纹
+//
+// https://www.apple.com/certificateauthority/
+var appleRootCAFingerprints = []string{
+	"63343abfb89a6a03ebb57e9b3f5fa7be7c4f5c756f3017b3a8c488c3653e9179",
+}
+
+var (
+	// ErrAppleNotConfigured Apple 应用内购买未配置
+	ErrAppleNotConfigured = errors.New("apple in-app purchase is not configured")
+	// ErrTransactionNotFound 交易不存在
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+// AppleTransactionTypeConsumable 消耗型项目，智慧果只能通过消耗型项目购买
+const AppleTransactionTypeConsumable = "Consumable"
+
+// AppleTransaction App Store Server API 返回的交易信息（JWSTransactionDecodedPayload）
+//
+// https://developer.apple.com/documentation/appstoreserverapi/jwstransactiondecodedpayload
+type AppleTransaction struct {
+	TransactionID         string `json:"transactionId"`
+	OriginalTransactionID string `json:"originalTransactionId"`
+	BundleID              string `json:"bundleId"`
+	ProductID             string `json:"productId"`
+	// PurchaseDate milliseconds since 1970
+	PurchaseDate int64  `json:"purchaseDate"`
+	Quantity     int64  `json:"quantity"`
+	Type         string `json:"type"`
+	// AppAccountToken the UUID set by the app when the purchase is made, the order no is used
+	AppAccountToken string `json:"appAccountToken,omitempty"`
+	Environment     string `json:"environment"`
+	// RevocationDate milliseconds since 1970, the transaction is refunded or revoked when it is not zero
+	RevocationDate int64 `json:"revocationDate,omitempty"`
+	SignedDate     int64 `json:"signedDate"`
+}
+
+// Valid 交易信息中没有标准的 JWT 声明，不需要校验
+func (trans *AppleTransaction) Valid() error {
+	return nil
+}
+
+// PurchasedAt 购买时间
+func (trans *AppleTransaction) PurchasedAt() time.Time {
+	return time.UnixMilli(trans.PurchaseDate)
+}
+
+// Consumable 是否为消耗型项目的交易
+func (trans *AppleTransaction) Consumable() bool {
+	return trans.Type == AppleTransactionTypeConsumable
+}
+
+// Revoked 交易是否已经退款或者被撤销
+func (trans *AppleTransaction) Revoked() bool {
+	return trans.RevocationDate > 0
+}
+
+// Apple App Store Server API 客户端
+type Apple struct {
+	conf config.AppleIAP
+	key  *ecdsa.PrivateKey
+}
+
+// NewApple 创建 App Store Server API 客户端
+func NewApple(conf config.AppleIAP) (*Apple, error) {
+	if !conf.Enabled() {
+		return &Apple{conf: conf}, nil
+	}
+
+	key, err := jwtlib.ParseECPrivateKeyFromPEM([]byte(conf.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse apple in-app purchase key failed: %w", err)
+	}
+
+	return &Apple{conf: conf, key: key}, nil
+}
+
+// Enabled 是否配置了 Apple 应用内购买
+func (apple *Apple) Enabled() bool {
+	return apple.key != nil
+}
+
+// Transaction 查询交易信息
+//
+// 先在生产环境查询，交易不存在时在沙盒环境查询（App 审核以及 TestFlight 使用沙盒环境），配置了 sandbox 时只在沙盒环境查询
+func (apple *Apple) Transaction(ctx context.Context, transactionID string) (*AppleTransaction, error) {
+	if !apple.Enabled() {
+		return nil, ErrAppleNotConfigured
+	}
+
+	servers := []string{appleProductionURL, appleSandboxURL}
+	if apple.conf.Sandbox {
+		servers = []string{appleSandboxURL}
+	}
+
+	for _, server := range servers {
+		trans, err := apple.transaction(ctx, server, transactionID)
+		if errors.Is(err, ErrTransactionNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if trans.BundleID != apple.conf.BundleID {
+			return nil, fmt.Errorf("bundle id mismatch: %s", trans.BundleID)
+		}
+
+		return trans, nil
+	}
+
+	return nil, ErrTransactionNotFound
+}
+
+type appleTransactionResponse struct {
+	SignedTransactionInfo string `json:"signedTransactionInfo"`
+	ErrorCode             int64  `json:"errorCode,omitempty"`
+	ErrorMessage          string `json:"errorMessage,omitempty"`
+}
+
+func (apple *Apple) transaction(ctx context.Context, server string, transactionID string) (*AppleTransaction, error) {
+	token, err := apple.token()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := misc.RestyClient(2).R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		Get(server + "/inApps/v1/transactions/" + url.PathEscape(transactionID))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() == http.StatusNotFound {
+		return nil, ErrTransactionNotFound
+	}
+
+	var ret appleTransactionResponse
+	if err := json.Unmarshal(resp.Body(), &ret); err != nil {
+		return nil, fmt.Errorf("decode apple transaction response failed (status %d): %w", resp.StatusCode(), err)
+	}
+
+	if resp.IsError() || ret.SignedTransactionInfo == "" {
+		return nil, fmt.Errorf("request apple transaction failed: [%d] %s", ret.ErrorCode, ret.ErrorMessage)
+	}
+
+	return parseSignedTransaction(ret.SignedTransactionInfo)
+}
+
+// token 生成调用 App Store Server API 的 JWT，有效期最长为 1 小时
+func (apple *Apple) token() (string, error) {
+	now := time.Now()
+	token := jwtlib.NewWithClaims(jwtlib.SigningMethodES256, jwtlib.MapClaims{
+		"iss": apple.conf.IssuerID,
+		"iat": now.Unix(),
+		"exp": now.Add(20 * time.Minute).Unix(),
+		"aud": "appstoreconnect-v1",
+		"bid": apple.conf.BundleID,
+	})
+	token.Header["kid"] = apple.conf.KeyID
+
+	return token.SignedString(apple.key)
+}
+
+// parseSignedTransaction 解析 JWS 格式的交易信息，并使用 x5c 证书链校验签名
+//
+// 交易信息是通过 TLS 直接从 App Store Server API 获取的，这里校验签名，以及证书链是否由 Apple 根证书签发
+func parseSignedTransaction(signed string) (*AppleTransaction, error) {
+	var trans AppleTransaction
+	_, err := jwtlib.ParseWithClaims(signed, &trans, func(token *jwtlib.Token) (any, error) {
+		if _, ok := token.Method.(*jwtlib.SigningMethodECDSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		chain, ok := token.Header["x5c"].([]any)
+		if !ok || len(chain) < 2 {
+			return nil, errors.New("x5c certificate chain is missing")
+		}
+
+		certs := make([]*x509.Certificate, 0, len(chain))
+		for _, item := range chain {
+			raw, ok := item.(string)
+			if !ok {
+				return nil, errors.New("invalid x5c header")
+			}
+
+			der, err := base64.StdEncoding.DecodeString(raw)
+			if err != nil {
+				return nil, fmt.Errorf("decode x5c certificate failed: %w", err)
+			}
+
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("parse x5c certificate failed: %w", err)
+			}
+
+			certs = append(certs, cert)
+		}
+
+		// 根证书必须是固定的 Apple 根证书，不能信任 x5c 中自带的任意根证书
+		root := certs[len(certs)-1]
+		if !pinnedAppleRootCA(root) {
+			return nil, errors.New("the root certificate is not apple root ca")
+		}
+
+		intermediates, roots := x509.NewCertPool(), x509.NewCertPool()
+		for _, cert := range certs[1 : len(certs)-1] {
+			intermediates.AddCert(cert)
+		}
+		roots.AddCert(root)
+
+		if _, err := certs[0].Verify(x509.VerifyOptions{
+			Intermediates: intermediates,
+			Roots:         roots,
+			CurrentTime:   time.UnixMilli(signedDate(token)),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return nil, fmt.Errorf("verify x5c certificate chain failed: %w", err)
+		}
+
+		key, ok := certs[0].PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, errors.New("unexpected public key type")
+		}
+
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parse signed transaction failed: %w", err)
+	}
+
+	return &trans, nil
+}
+
+// signedDate 交易信息的签名时间，用于校验签名时证书是否有效
+func signedDate(token *jwtlib.Token) int64 {
+	if trans, ok := token.Claims.(*AppleTransaction); ok && trans.SignedDate > 0 {
+		return trans.SignedDate
+	}
+
+	return time.Now().UnixMilli()
+}
+
+// pinnedAppleRootCA 证书是否为固定的 Apple 根证书
+func pinnedAppleRootCA(cert *x509.Certificate) bool {
+	sum := sha256.Sum256(cert.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	for _, pinned := range appleRootCAFingerprints {
+		if fingerprint == pinned {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,172 @@
+package payment
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	jwtlib "github.com/dgrijalva/jwt-go"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, isCA bool, notAfter time.Time) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	issuer, signer := tpl, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCert{cert: cert, key: key}
+}
+
+// pinTestRootCA 使用测试根证书替换 Apple 根证书
+func pinTestRootCA(t *testing.T, root *testCert) {
+	t.Helper()
+
+	sum := sha256.Sum256(root.cert.Raw)
+	original := appleRootCAFingerprints
+	appleRootCAFingerprints = []string{hex.EncodeToString(sum[:])}
+	t.Cleanup(func() { appleRootCAFingerprints = original })
+}
+
+func signTestTransaction(t *testing.T, trans *AppleTransaction, key *ecdsa.PrivateKey, chain ...*testCert) string {
+	t.Helper()
+
+	x5c := make([]any, 0, len(chain))
+	for _, c := range chain {
+		x5c = append(x5c, base64.StdEncoding.EncodeToString(c.cert.Raw))
+	}
+
+	token := jwtlib.NewWithClaims(jwtlib.SigningMethodES256, trans)
+	token.Header["x5c"] = x5c
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func testTransaction() *AppleTransaction {
+	return &AppleTransaction{
+		TransactionID:   "2000000000000001",
+		BundleID:        "cc.aicode.aidea",
+		ProductID:       "cc.aicode.aidea.coins.100",
+		Quantity:        1,
+		Type:            AppleTransactionTypeConsumable,
+		AppAccountToken: "4f6c2b1e-8d1a-4d2b-9a6b-2f7c8e0d1a3b",
+		Environment:     "Production",
+		SignedDate:      time.Now().UnixMilli(),
+	}
+}
+
+func TestParseSignedTransaction(t *testing.T) {
+	notAfter := time.Now().Add(24 * time.Hour)
+	root := newTestCert(t, "Test Root CA", nil, true, notAfter)
+	intermediate := newTestCert(t, "Test Intermediate CA", root, true, notAfter)
+	leaf := newTestCert(t, "Test Leaf", intermediate, false, notAfter)
+	pinTestRootCA(t, root)
+
+	signed := signTestTransaction(t, testTransaction(), leaf.key, leaf, intermediate, root)
+	trans, err := parseSignedTransaction(signed)
+	if err != nil {
+		t.Fatalf("parse signed transaction failed: %v", err)
+	}
+
+	if trans.TransactionID != "2000000000000001" || trans.AppAccountToken != "4f6c2b1e-8d1a-4d2b-9a6b-2f7c8e0d1a3b" {
+		t.Errorf("unexpected transaction: %+v", trans)
+	}
+
+	if !trans.Consumable() {
+		t.Errorf("expect consumable transaction")
+	}
+}
+
+func TestParseSignedTransactionRejected(t *testing.T) {
+	notAfter := time.Now().Add(24 * time.Hour)
+	root := newTestCert(t, "Test Root CA", nil, true, notAfter)
+	intermediate := newTestCert(t, "Test Intermediate CA", root, true, notAfter)
+	leaf := newTestCert(t, "Test Leaf", intermediate, false, notAfter)
+
+	// 证书链完整，但是根证书不是固定的根证书
+	other := newTestCert(t, "Apple Root CA - G3", nil, true, notAfter)
+	otherIntermediate := newTestCert(t, "Test Intermediate CA", other, true, notAfter)
+	otherLeaf := newTestCert(t, "Test Leaf", otherIntermediate, false, notAfter)
+
+	expiredLeaf := newTestCert(t, "Test Leaf", intermediate, false, time.Now().Add(-time.Minute))
+
+	pinTestRootCA(t, root)
+
+	tampered := signTestTransaction(t, testTransaction(), leaf.key, leaf, intermediate, root)
+	parts := strings.Split(tampered, ".")
+	forged := testTransaction()
+	forged.Quantity = 100
+	parts[1] = strings.Split(signTestTransaction(t, forged, leaf.key, leaf, intermediate, root), ".")[1]
+	tampered = strings.Join(parts, ".")
+
+	testCases := map[string]string{
+		"unpinned root":         signTestTransaction(t, testTransaction(), otherLeaf.key, otherLeaf, otherIntermediate, other),
+		"pinned root appended":  signTestTransaction(t, testTransaction(), otherLeaf.key, otherLeaf, otherIntermediate, root),
+		"signed by another key": signTestTransaction(t, testTransaction(), otherLeaf.key, leaf, intermediate, root),
+		"missing x5c":           signTestTransaction(t, testTransaction(), leaf.key),
+		"expired certificate":   signTestTransaction(t, testTransaction(), expiredLeaf.key, expiredLeaf, intermediate, root),
+		"tampered payload":      tampered,
+	}
+
+	for name, signed := range testCases {
+		if _, err := parseSignedTransaction(signed); err == nil {
+			t.Errorf("%s: expect error, got nil", name)
+		}
+	}
+}
+
+func TestAppleRootCAPinned(t *testing.T) {
+	if len(appleRootCAFingerprints) == 0 {
+		t.Fatal("apple root ca fingerprint is not pinned")
+	}
+
+	for _, fingerprint := range appleRootCAFingerprints {
+		if raw, err := hex.DecodeString(fingerprint); err != nil || len(raw) != sha256.Size {
+			t.Errorf("invalid fingerprint: %s", fingerprint)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package payment
+
+import (
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/glacier/infra"
+)
+
+type Provider struct{}
+
+func (Provider) Register(binder infra.Binder) {
+	binder.MustSingleton(func(conf *config.Config) (*Apple, error) {
+		return NewApple(conf.Payment.Apple)
+	})
+}
@@ -0,0 +1,223 @@
+package repo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
+	"gopkg.in/guregu/null.v3"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix 用户 API Key 的前缀，用于和 JWT 区分
+const APIKeyPrefix = "sk-"
+
+const (
+	// APIKeyScopeChat access to the chat APIs
+	APIKeyScopeChat = "chat"
+	// APIKeyScopeRobots access to the robot management APIs
+	APIKeyScopeRobots = "robots"
+	// APIKeyScopeUsers access to the user information APIs (API key management is not included)
+	APIKeyScopeUsers = "users"
+)
+
+// APIKeyScopes all supported API key scopes
+var APIKeyScopes = []string{APIKeyScopeChat, APIKeyScopeRobots, APIKeyScopeUsers}
+
+// APIKeyRepo 用户 API Key 仓库
+type APIKeyRepo struct {
+	db   *sql.DB
+	conf *config.Config
+}
+
+// NewAPIKeyRepo create a new APIKeyRepo
+func NewAPIKeyRepo(db *sql.DB, conf *config.Config) *APIKeyRepo {
+	return &APIKeyRepo{db: db, conf: conf}
+}
+
+// APIKey 用户 API Key，不包含 Key 本身
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Name       string     `json:"name"`
+	KeyPreview string     `json:"key_preview"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiredAt  *time.Time `json:"expired_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Expired whether the API key has expired
+func (key APIKey) Expired() bool {
+	return key.ExpiredAt != nil && key.ExpiredAt.Before(time.Now())
+}
+
+// HasScope whether the API key has the specified scope
+func (key APIKey) HasScope(scope string) bool {
+	return array.In(scope, key.Scopes)
+}
+
+func buildAPIKeyFromModel(m model.UserApiKeysN) APIKey {
+	key := APIKey{
+		ID:         m.Id.ValueOrZero(),
+		UserID:     m.UserId.ValueOrZero(),
+		Name:       m.Name.ValueOrZero(),
+		KeyPreview: m.KeyPreview.ValueOrZero(),
+		Scopes:     array.Filter(strings.Split(m.Scopes.ValueOrZero(), ","), func(item string, _ int) bool { return item != "" }),
+		CreatedAt:  m.CreatedAt.ValueOrZero(),
+	}
+
+	if m.LastUsedAt.Valid {
+		key.LastUsedAt = &m.LastUsedAt.Time
+	}
+
+	if m.ExpiredAt.Valid {
+		key.ExpiredAt = &m.ExpiredAt.Time
+	}
+
+	return key
+}
+
+// hashAPIKey API Key 只保存 SHA256 哈希值
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey whether the credential looks like an API key
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// CreateAPIKey 创建 API Key，返回的明文 Key 只在创建时返回一次
+func (repo *APIKeyRepo) CreateAPIKey(ctx context.Context, userID int64, name string, scopes []string, expiredAt *time.Time) (string, *APIKey, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+
+	key := APIKeyPrefix + hex.EncodeToString(buf)
+	m := model.UserApiKeysN{
+		UserId:     null.IntFrom(userID),
+		Name:       null.StringFrom(name),
+		KeyHash:    null.StringFrom(hashAPIKey(key)),
+		KeyPreview: null.StringFrom(key[:len(APIKeyPrefix)+4] + "..." + key[len(key)-4:]),
+		Scopes:     null.StringFrom(strings.Join(scopes, ",")),
+	}
+	if expiredAt != nil {
+		m.ExpiredAt = null.TimeFrom(*expiredAt)
+	}
+
+	id, err := model.NewUserApiKeysModel(repo.db).Save(ctx, m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	m.Id = null.IntFrom(id)
+	m.CreatedAt = null.TimeFrom(time.Now())
+
+	ret := buildAPIKeyFromModel(m)
+	return key, &ret, nil
+}
+
+// GetAPIKeys 获取用户的所有 API Key
+func (repo *APIKeyRepo) GetAPIKeys(ctx context.Context, userID int64) ([]APIKey, error) {
+	keys, err := model.NewUserApiKeysModel(repo.db).Get(
+		ctx,
+		query.Builder().
+			Where(model.FieldUserApiKeysUserId, userID).
+			OrderBy(model.FieldUserApiKeysId, "DESC"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(keys, func(item model.UserApiKeysN, _ int) APIKey {
+		return buildAPIKeyFromModel(item)
+	}), nil
+}
+
+// GetAPIKeyByKey 根据明文 Key 查询 API Key
+func (repo *APIKeyRepo) GetAPIKeyByKey(ctx context.Context, key string) (*APIKey, error) {
+	m, err := model.NewUserApiKeysModel(repo.db).First(ctx, query.Builder().Where(model.FieldUserApiKeysKeyHash, hashAPIKey(key)))
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := buildAPIKeyFromModel(*m)
+	return &ret, nil
+}
+
+// UpdateAPIKey 更新 API Key 的名称以及访问范围，expiredAt 不为空时同时更新过期时间
+func (repo *APIKeyRepo) UpdateAPIKey(ctx context.Context, userID int64, id int64, name string, scopes []string, expiredAt *time.Time) error {
+	q := query.Builder().
+		Where(model.FieldUserApiKeysId, id).
+		Where(model.FieldUserApiKeysUserId, userID)
+
+	exist, err := model.NewUserApiKeysModel(repo.db).Exists(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return ErrNotFound
+	}
+
+	fields := query.KV{
+		model.FieldUserApiKeysName:   name,
+		model.FieldUserApiKeysScopes: strings.Join(scopes, ","),
+	}
+	if expiredAt != nil {
+		fields[model.FieldUserApiKeysExpiredAt] = *expiredAt
+	}
+
+	_, err = model.NewUserApiKeysModel(repo.db).UpdateFields(ctx, fields, q)
+
+	return err
+}
+
+// DeleteAPIKey 撤销 API Key
+func (repo *APIKeyRepo) DeleteAPIKey(ctx context.Context, userID int64, id int64) error {
+	deleted, err := model.NewUserApiKeysModel(repo.db).Delete(
+		ctx,
+		query.Builder().
+			Where(model.FieldUserApiKeysId, id).
+			Where(model.FieldUserApiKeysUserId, userID),
+	)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// TouchAPIKey 更新 API Key 的最后使用时间，一分钟内最多更新一次
+func (repo *APIKeyRepo) TouchAPIKey(ctx context.Context, id int64) error {
+	_, err := model.NewUserApiKeysModel(repo.db).UpdateFields(
+		ctx,
+		query.KV{model.FieldUserApiKeysLastUsedAt: time.Now()},
+		query.Builder().
+			Where(model.FieldUserApiKeysId, id).
+			WhereGroup(func(builder query.Condition) {
+				builder.WhereNull(model.FieldUserApiKeysLastUsedAt).
+					OrWhere(model.FieldUserApiKeysLastUsedAt, "<", time.Now().Add(-time.Minute))
+			}),
+	)
+
+	return err
+}
@@ -0,0 +1,326 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+const (
+	// MessageRoleUser message role: user
+	MessageRoleUser = "user"
+	// MessageRoleAssistant message role: assistant
+	MessageRoleAssistant = "assistant"
+)
+
+const (
+	// MessageStatusSucceed message status: succeed
+	MessageStatusSucceed = "succeed"
+	// MessageStatusFailed message status: failed
+	MessageStatusFailed = "failed"
+	// MessageStatusPending message status: the answer is being generated
+	MessageStatusPending = "pending"
+	// MessageStatusStopped message status: the answer is stopped by the user
+	MessageStatusStopped = "stopped"
+)
+
+// ConversationRepo 会话历史仓库
+type ConversationRepo struct {
+	db   *sql.DB
+	conf *config.Config
+}
+
+// NewConversationRepo create a new ConversationRepo
+func NewConversationRepo(db *sql.DB, conf *config.Config) *ConversationRepo {
+	return &ConversationRepo{db: db, conf: conf}
+}
+
+// GetConversation 获取用户的会话
+func (repo *ConversationRepo) GetConversation(ctx context.Context, userID int64, conversationID int64) (*model.Conversations, error) {
+	conv, err := model.NewConversationsModel(repo.db).First(
+		ctx,
+		query.Builder().
+			Where(model.FieldConversationsId, conversationID).
+			Where(model.FieldConversationsUserId, userID),
+	)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := conv.ToConversations()
+	return &ret, nil
+}
+
+// Question 用户提问
+type Question struct {
+	// ConversationID 会话 ID，为 0 时创建新的会话
+	ConversationID int64
+	RobotID        string
+	Message        string
+	// MultipartContents 多模态消息内容，JSON 格式
+	MultipartContents string
+	// EditOf 被编辑的问题 ID，不为 0 时新的问题和被编辑的问题互为兄弟节点，从被编辑的问题处创建新的分支
+	EditOf int64
+}
+
+// SaveQuestion 保存用户提问，返回会话 ID 和问题 ID，问题追加到会话当前分支的末尾
+func (repo *ConversationRepo) SaveQuestion(ctx context.Context, userID int64, question Question) (conversationID int64, questionID int64, err error) {
+	err = eloquent.Transaction(repo.db, func(tx query.Database) error {
+		var parentID int64
+
+		conversationID = question.ConversationID
+		if conversationID > 0 {
+			conv, err := model.NewConversationsModel(tx).First(
+				ctx,
+				query.Builder().
+					Where(model.FieldConversationsId, conversationID).
+					Where(model.FieldConversationsUserId, userID),
+			)
+			if err != nil {
+				if errors.Is(err, query.ErrNoResult) {
+					return ErrNotFound
+				}
+
+				return err
+			}
+
+			if question.EditOf > 0 {
+				tree, err := loadMessageTree(ctx, tx, userID, conv.ToConversations())
+				if err != nil {
+					return err
+				}
+
+				edited, ok := tree.Message(question.EditOf)
+				if !ok || edited.Role != MessageRoleUser {
+					return ErrNotFound
+				}
+
+				parentID = tree.Parent(question.EditOf)
+			} else {
+				parentID, err = currentMessageID(ctx, tx, userID, conv.ToConversations())
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			conversationID, err = model.NewConversationsModel(tx).Save(ctx, model.ConversationsN{
+				UserId:  null.IntFrom(userID),
+				RobotId: null.StringFrom(question.RobotID),
+				Title:   null.StringFrom(DefaultConversationTitle(question.Message)),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		msg := model.ChatMessagesN{
+			UserId:         null.IntFrom(userID),
+			ConversationId: null.IntFrom(conversationID),
+			RobotId:        null.StringFrom(question.RobotID),
+			Role:           null.StringFrom(MessageRoleUser),
+			Message:        null.StringFrom(question.Message),
+			ParentId:       null.IntFrom(parentID),
+			Status:         null.StringFrom(MessageStatusSucceed),
+		}
+		if question.MultipartContents != "" {
+			msg.MultipartContents = null.StringFrom(question.MultipartContents)
+		}
+
+		questionID, err = model.NewChatMessagesModel(tx).Save(ctx, msg)
+		if err != nil {
+			return err
+		}
+
+		// 更新会话的最后活跃时间以及当前分支
+		_, err = model.NewConversationsModel(tx).UpdateFields(
+			ctx,
+			query.KV{
+				model.FieldConversationsRobotId:          question.RobotID,
+				model.FieldConversationsCurrentMessageId: questionID,
+				model.FieldConversationsLastMessageAt:    time.Now(),
+			},
+			query.Builder().
+				Where(model.FieldConversationsId, conversationID).
+				Where(model.FieldConversationsUserId, userID),
+		)
+		return err
+	})
+
+	return
+}
+
+// Answer 模型回复
+type Answer struct {
+	// ID the answer created by CreatePendingAnswer, 0 means a new answer is created
+	ID         int64
+	QuestionID int64
+	RobotID    string
+	Model      string
+	Channel    string
+	Message    string
+
+	PromptTokens     int64
+	CompletionTokens int64
+	FirstLetterDelay int64
+	ConsumeInMilli   int64
+	QuotaConsumed    int64
+
+	// Error 不为空时，回复以及对应的提问都被标记为失败
+	Error string
+	// Stopped 用户主动停止生成，回复保存已经生成的内容
+	Stopped bool
+}
+
+// CreatePendingAnswer 创建生成中的回复，生成完成后通过 SaveAnswer 更新，回复 ID 用于客户端断开后恢复
+//
+// 问题已经有回复时（重新生成），新的回复和已有的回复互为兄弟节点，并切换到新的回复所在的分支
+func (repo *ConversationRepo) CreatePendingAnswer(ctx context.Context, userID int64, conversationID int64, questionID int64, robotID string) (answerID int64, err error) {
+	err = eloquent.Transaction(repo.db, func(tx query.Database) error {
+		answerID, err = model.NewChatMessagesModel(tx).Save(ctx, model.ChatMessagesN{
+			UserId:         null.IntFrom(userID),
+			ConversationId: null.IntFrom(conversationID),
+			RobotId:        null.StringFrom(robotID),
+			Role:           null.StringFrom(MessageRoleAssistant),
+			Pid:            null.IntFrom(questionID),
+			ParentId:       null.IntFrom(questionID),
+			Status:         null.StringFrom(MessageStatusPending),
+		})
+		if err != nil {
+			return err
+		}
+
+		return repo.switchBranch(ctx, tx, userID, conversationID, answerID)
+	})
+
+	return
+}
+
+// SaveAnswer 保存模型回复，返回回复 ID
+func (repo *ConversationRepo) SaveAnswer(ctx context.Context, userID int64, conversationID int64, answer Answer) (answerID int64, err error) {
+	err = eloquent.Transaction(repo.db, func(tx query.Database) error {
+		status := MessageStatusSucceed
+		if answer.Error != "" {
+			status = MessageStatusFailed
+		} else if answer.Stopped {
+			status = MessageStatusStopped
+		}
+
+		msg := model.ChatMessagesN{
+			UserId:           null.IntFrom(userID),
+			ConversationId:   null.IntFrom(conversationID),
+			RobotId:          null.StringFrom(answer.RobotID),
+			Role:             null.StringFrom(MessageRoleAssistant),
+			Message:          null.StringFrom(answer.Message),
+			Pid:              null.IntFrom(answer.QuestionID),
+			ParentId:         null.IntFrom(answer.QuestionID),
+			Model:            null.StringFrom(answer.Model),
+			Channel:          null.StringFrom(answer.Channel),
+			PromptTokens:     null.IntFrom(answer.PromptTokens),
+			CompletionTokens: null.IntFrom(answer.CompletionTokens),
+			FirstLetterDelay: null.IntFrom(answer.FirstLetterDelay),
+			ConsumeInMilli:   null.IntFrom(answer.ConsumeInMilli),
+			QuotaConsumed:    null.IntFrom(answer.QuotaConsumed),
+			Status:           null.StringFrom(status),
+			Error:            null.StringFrom(answer.Error),
+		}
+
+		if answer.ID > 0 {
+			answerID = answer.ID
+			if _, err := model.NewChatMessagesModel(tx).Update(
+				ctx,
+				query.Builder().
+					Where(model.FieldChatMessagesId, answer.ID).
+					Where(model.FieldChatMessagesUserId, userID),
+				msg,
+			); err != nil {
+				return err
+			}
+		} else {
+			answerID, err = model.NewChatMessagesModel(tx).Save(ctx, msg)
+			if err != nil {
+				return err
+			}
+		}
+
+		if status == MessageStatusFailed && answer.QuestionID > 0 {
+			if _, err := model.NewChatMessagesModel(tx).UpdateFields(
+				ctx,
+				query.KV{model.FieldChatMessagesStatus: MessageStatusFailed},
+				query.Builder().
+					Where(model.FieldChatMessagesId, answer.QuestionID).
+					Where(model.FieldChatMessagesUserId, userID),
+			); err != nil {
+				return err
+			}
+		}
+
+		// 更新会话的最后活跃时间，新增的回复作为当前分支的最后一条消息
+		kv := query.KV{
+			model.FieldConversationsRobotId:       answer.RobotID,
+			model.FieldConversationsLastMessageAt: time.Now(),
+		}
+		if answer.ID <= 0 {
+			kv[model.FieldConversationsCurrentMessageId] = answerID
+		}
+
+		_, err = model.NewConversationsModel(tx).UpdateFields(
+			ctx,
+			kv,
+			query.Builder().
+				Where(model.FieldConversationsId, conversationID).
+				Where(model.FieldConversationsUserId, userID),
+		)
+		return err
+	})
+
+	return
+}
+
+// DiscardAnswer 删除只包含工具调用的中间回复，并将会话的当前分支切换回提问
+//
+// 工具由客户端执行，执行结果提交后继续生成的回复才作为提问的回复保存
+func (repo *ConversationRepo) DiscardAnswer(ctx context.Context, userID int64, conversationID int64, questionID int64, answerID int64) error {
+	return eloquent.Transaction(repo.db, func(tx query.Database) error {
+		if _, err := model.NewChatMessagesModel(tx).Delete(
+			ctx,
+			query.Builder().
+				Where(model.FieldChatMessagesId, answerID).
+				Where(model.FieldChatMessagesUserId, userID).
+				Where(model.FieldChatMessagesRole, MessageRoleAssistant),
+		); err != nil {
+			return err
+		}
+
+		return repo.switchBranch(ctx, tx, userID, conversationID, questionID)
+	})
+}
+
+// GetMessage 获取用户的一条聊天消息
+func (repo *ConversationRepo) GetMessage(ctx context.Context, userID int64, messageID int64) (*model.ChatMessages, error) {
+	msg, err := model.NewChatMessagesModel(repo.db).First(
+		ctx,
+		query.Builder().
+			Where(model.FieldChatMessagesId, messageID).
+			Where(model.FieldChatMessagesUserId, userID),
+	)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := msg.ToChatMessages()
+	return &ret, nil
+}
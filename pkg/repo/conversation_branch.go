@@ -0,0 +1,245 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent"
+	"github.com/mylxsw/eloquent/query"
+)
+
+// MessageTree 会话的消息树
+//
+// 每条消息的父节点为所在分支中的上一条消息，同一个父节点下的消息互为兄弟节点：
+// 重新生成的回复是原回复的兄弟节点，编辑后的问题是原问题的兄弟节点。
+// 会话记录当前分支的最后一条消息，从它回溯到根节点即为当前分支的完整对话
+type MessageTree struct {
+	ConversationID int64
+	// Current the last message of the active branch
+	Current int64
+
+	messages map[int64]model.ChatMessages
+	parents  map[int64]int64
+	// children parent id => child ids ordered by id, 0 means the root of the conversation
+	children map[int64][]int64
+}
+
+// Message 获取树中的一条消息
+func (tree *MessageTree) Message(id int64) (model.ChatMessages, bool) {
+	msg, ok := tree.messages[id]
+	return msg, ok
+}
+
+// Parent 获取消息的父节点，0 表示会话的第一条消息
+func (tree *MessageTree) Parent(id int64) int64 {
+	return tree.parents[id]
+}
+
+// Siblings 获取和消息位于同一个父节点下的所有消息（包含自身），按照创建顺序排列
+func (tree *MessageTree) Siblings(id int64) []int64 {
+	if _, ok := tree.messages[id]; !ok {
+		return nil
+	}
+
+	return tree.children[tree.parents[id]]
+}
+
+// Leaf 沿着最新的子节点查找消息所在分支的最后一条消息
+func (tree *MessageTree) Leaf(id int64) int64 {
+	for {
+		children := tree.children[id]
+		if len(children) == 0 {
+			return id
+		}
+
+		id = children[len(children)-1]
+	}
+}
+
+// Path 获取从会话的第一条消息到指定消息的完整对话
+func (tree *MessageTree) Path(id int64) []model.ChatMessages {
+	path := make([]model.ChatMessages, 0)
+	for id > 0 {
+		msg, ok := tree.messages[id]
+		if !ok {
+			break
+		}
+
+		path = append(path, msg)
+		id = tree.parents[id]
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// ActivePath 获取当前分支的完整对话
+func (tree *MessageTree) ActivePath() []model.ChatMessages {
+	return tree.Path(tree.Current)
+}
+
+// CurrentQuestion 获取当前分支中最后一条提问，当前分支没有提问时返回 0
+func (tree *MessageTree) CurrentQuestion() int64 {
+	for id := tree.Current; id > 0; id = tree.parents[id] {
+		msg, ok := tree.messages[id]
+		if !ok {
+			break
+		}
+
+		if msg.Role == MessageRoleUser {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// MessageTree 获取会话的消息树
+func (repo *ConversationRepo) MessageTree(ctx context.Context, userID int64, conversationID int64) (*MessageTree, error) {
+	conv, err := repo.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadMessageTree(ctx, repo.db, userID, *conv)
+}
+
+// CurrentQuestion 获取会话当前分支中最后一条提问的 ID
+func (repo *ConversationRepo) CurrentQuestion(ctx context.Context, userID int64, conversationID int64) (int64, error) {
+	tree, err := repo.MessageTree(ctx, userID, conversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	questionID := tree.CurrentQuestion()
+	if questionID == 0 {
+		return 0, ErrNotFound
+	}
+
+	return questionID, nil
+}
+
+// SwitchBranch 切换到消息所在的分支，消息有后续对话时，沿着最新的子节点切换到分支的最后一条消息
+func (repo *ConversationRepo) SwitchBranch(ctx context.Context, userID int64, messageID int64) (tree *MessageTree, err error) {
+	msg, err := repo.GetMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = eloquent.Transaction(repo.db, func(tx query.Database) error {
+		conv, err := model.NewConversationsModel(tx).First(
+			ctx,
+			query.Builder().
+				Where(model.FieldConversationsId, msg.ConversationId).
+				Where(model.FieldConversationsUserId, userID),
+		)
+		if err != nil {
+			if errors.Is(err, query.ErrNoResult) {
+				return ErrNotFound
+			}
+
+			return err
+		}
+
+		tree, err = loadMessageTree(ctx, tx, userID, conv.ToConversations())
+		if err != nil {
+			return err
+		}
+
+		tree.Current = tree.Leaf(messageID)
+		return repo.switchBranch(ctx, tx, userID, msg.ConversationId, tree.Current)
+	})
+
+	return
+}
+
+// switchBranch 设置会话当前分支的最后一条消息
+func (repo *ConversationRepo) switchBranch(ctx context.Context, tx query.Database, userID int64, conversationID int64, messageID int64) error {
+	_, err := model.NewConversationsModel(tx).UpdateFields(
+		ctx,
+		query.KV{model.FieldConversationsCurrentMessageId: messageID},
+		query.Builder().
+			Where(model.FieldConversationsId, conversationID).
+			Where(model.FieldConversationsUserId, userID),
+	)
+	return err
+}
+
+// loadMessageTree 加载会话的所有消息并构建消息树
+//
+// 旧数据没有记录父节点，会话是线性的，父节点为按照 ID 顺序的上一条消息；
+// 会话没有记录当前分支时，使用最后一条消息
+func loadMessageTree(ctx context.Context, db query.Database, userID int64, conv model.Conversations) (*MessageTree, error) {
+	messages, err := model.NewChatMessagesModel(db).Get(
+		ctx,
+		query.Builder().
+			Where(model.FieldChatMessagesConversationId, conv.Id).
+			Where(model.FieldChatMessagesUserId, userID).
+			OrderBy(model.FieldChatMessagesId, "ASC"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMessageTree(conv, messages), nil
+}
+
+// buildMessageTree 使用按照 ID 排序的消息构建消息树
+func buildMessageTree(conv model.Conversations, messages []model.ChatMessagesN) *MessageTree {
+	tree := &MessageTree{
+		ConversationID: conv.Id,
+		messages:       make(map[int64]model.ChatMessages, len(messages)),
+		parents:        make(map[int64]int64, len(messages)),
+		children:       make(map[int64][]int64),
+	}
+
+	var last int64
+	for _, msg := range messages {
+		id := msg.Id.ValueOrZero()
+
+		parent := last
+		if msg.ParentId.Valid {
+			parent = msg.ParentId.Int64
+		}
+
+		tree.messages[id] = msg.ToChatMessages()
+		tree.parents[id] = parent
+		tree.children[parent] = append(tree.children[parent], id)
+
+		last = id
+	}
+
+	tree.Current = conv.CurrentMessageId
+	if _, ok := tree.messages[tree.Current]; !ok {
+		tree.Current = last
+	}
+
+	return tree
+}
+
+// currentMessageID 获取会话当前分支的最后一条消息 ID，会话没有记录当前分支时（旧数据），使用最后一条消息
+func currentMessageID(ctx context.Context, db query.Database, userID int64, conv model.Conversations) (int64, error) {
+	if conv.CurrentMessageId > 0 {
+		return conv.CurrentMessageId, nil
+	}
+
+	last, err := model.NewChatMessagesModel(db).First(
+		ctx,
+		query.Builder().
+			Where(model.FieldChatMessagesConversationId, conv.Id).
+			Where(model.FieldChatMessagesUserId, userID).
+			OrderBy(model.FieldChatMessagesId, "DESC"),
+	)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return last.Id.ValueOrZero(), nil
+}
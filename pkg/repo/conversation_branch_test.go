@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"gopkg.in/guregu/null.v3"
+	"reflect"
+	"testing"
+)
+
+func chatMessage(id int64, parentID int64) model.ChatMessagesN {
+	msg := model.ChatMessagesN{Id: null.IntFrom(id)}
+	if parentID >= 0 {
+		msg.ParentId = null.IntFrom(parentID)
+	}
+
+	return msg
+}
+
+func pathIDs(path []model.ChatMessages) []int64 {
+	ids := make([]int64, 0, len(path))
+	for _, msg := range path {
+		ids = append(ids, msg.Id)
+	}
+
+	return ids
+}
+
+func TestMessageTree_Branches(t *testing.T) {
+	// 1 q1 ─┬─ 2 a1
+	//       └─ 3 a1 (regenerated) ── 4 q2 ── 7 a2
+	// 5 q1 (edited) ── 6 a1
+	messages := []model.ChatMessagesN{
+		chatMessage(1, 0),
+		chatMessage(2, 1),
+		chatMessage(3, 1),
+		chatMessage(4, 3),
+		chatMessage(5, 0),
+		chatMessage(6, 5),
+		chatMessage(7, 4),
+	}
+
+	tree := buildMessageTree(model.Conversations{Id: 100, CurrentMessageId: 7}, messages)
+
+	if tree.ConversationID != 100 || tree.Current != 7 {
+		t.Fatalf("unexpected tree: conversation %d, current %d", tree.ConversationID, tree.Current)
+	}
+
+	if got := pathIDs(tree.ActivePath()); !reflect.DeepEqual(got, []int64{1, 3, 4, 7}) {
+		t.Errorf("unexpected active path: %v", got)
+	}
+
+	if got := tree.Siblings(2); !reflect.DeepEqual(got, []int64{2, 3}) {
+		t.Errorf("unexpected siblings of regenerated answer: %v", got)
+	}
+
+	if got := tree.Siblings(5); !reflect.DeepEqual(got, []int64{1, 5}) {
+		t.Errorf("unexpected siblings of edited question: %v", got)
+	}
+
+	if got := tree.Siblings(99); got != nil {
+		t.Errorf("siblings of unknown message should be nil, got %v", got)
+	}
+
+	if got := tree.Parent(4); got != 3 {
+		t.Errorf("unexpected parent: %d", got)
+	}
+
+	// 切换分支时沿着最新的子节点找到分支的最后一条消息
+	testCases := map[int64]int64{1: 7, 2: 2, 3: 7, 5: 6, 6: 6}
+	for id, want := range testCases {
+		if got := tree.Leaf(id); got != want {
+			t.Errorf("leaf of %d: expect %d, got %d", id, want, got)
+		}
+	}
+
+	if got := pathIDs(tree.Path(6)); !reflect.DeepEqual(got, []int64{5, 6}) {
+		t.Errorf("unexpected path: %v", got)
+	}
+}
+
+func TestMessageTree_LegacyLinearConversation(t *testing.T) {
+	// 旧数据没有记录父节点以及当前分支
+	messages := []model.ChatMessagesN{
+		chatMessage(10, -1),
+		chatMessage(11, -1),
+		chatMessage(12, -1),
+	}
+
+	tree := buildMessageTree(model.Conversations{Id: 1}, messages)
+	if tree.Current != 12 {
+		t.Errorf("current should be the last message, got %d", tree.Current)
+	}
+
+	if got := pathIDs(tree.ActivePath()); !reflect.DeepEqual(got, []int64{10, 11, 12}) {
+		t.Errorf("unexpected active path: %v", got)
+	}
+
+	// 当前分支指向的消息已经被删除时，使用最后一条消息
+	tree = buildMessageTree(model.Conversations{Id: 1, CurrentMessageId: 99}, messages)
+	if tree.Current != 12 {
+		t.Errorf("current should fallback to the last message, got %d", tree.Current)
+	}
+}
+
+func TestMessageTree_CurrentQuestion(t *testing.T) {
+	message := func(id, parentID int64, role string) model.ChatMessagesN {
+		msg := chatMessage(id, parentID)
+		msg.Role = null.StringFrom(role)
+		return msg
+	}
+
+	messages := []model.ChatMessagesN{
+		message(1, 0, MessageRoleUser),
+		message(2, 1, MessageRoleAssistant),
+		message(3, 2, MessageRoleUser),
+		message(4, 3, MessageRoleAssistant),
+	}
+
+	testCases := map[int64]int64{4: 3, 3: 3, 2: 1, 1: 1}
+	for current, want := range testCases {
+		tree := buildMessageTree(model.Conversations{Id: 1, CurrentMessageId: current}, messages)
+		if got := tree.CurrentQuestion(); got != want {
+			t.Errorf("current %d: expect question %d, got %d", current, want, got)
+		}
+	}
+
+	tree := buildMessageTree(model.Conversations{Id: 1}, nil)
+	if got := tree.CurrentQuestion(); got != 0 {
+		t.Errorf("empty conversation should have no question, got %d", got)
+	}
+}
@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"context"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
+	"time"
+)
+
+// Conversation 会话
+type Conversation struct {
+	ID            int64     `json:"id"`
+	RobotID       string    `json:"robot_id,omitempty"`
+	Title         string    `json:"title"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func buildConversationFromModel(m model.ConversationsN) Conversation {
+	conv := Conversation{
+		ID:            m.Id.ValueOrZero(),
+		RobotID:       m.RobotId.ValueOrZero(),
+		Title:         m.Title.ValueOrZero(),
+		LastMessageAt: m.LastMessageAt.ValueOrZero(),
+		CreatedAt:     m.CreatedAt.ValueOrZero(),
+	}
+
+	if conv.LastMessageAt.IsZero() {
+		conv.LastMessageAt = m.UpdatedAt.ValueOrZero()
+	}
+
+	return conv
+}
+
+// Conversations 分页获取用户的会话列表，按照最后一条消息的时间倒序排列
+func (repo *ConversationRepo) Conversations(ctx context.Context, userID int64, page, perPage int64) ([]Conversation, query.PaginateMeta, error) {
+	convs, meta, err := model.NewConversationsModel(repo.db).Paginate(
+		ctx,
+		page,
+		perPage,
+		query.Builder().
+			Where(model.FieldConversationsUserId, userID).
+			OrderBy(model.FieldConversationsLastMessageAt, "DESC").
+			OrderBy(model.FieldConversationsId, "DESC"),
+	)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	return array.Map(convs, func(item model.ConversationsN, _ int) Conversation {
+		return buildConversationFromModel(item)
+	}), meta, nil
+}
+
+// RenameConversation 修改会话标题
+func (repo *ConversationRepo) RenameConversation(ctx context.Context, userID int64, conversationID int64, title string) error {
+	affected, err := model.NewConversationsModel(repo.db).UpdateFields(
+		ctx,
+		query.KV{model.FieldConversationsTitle: title},
+		query.Builder().
+			Where(model.FieldConversationsId, conversationID).
+			Where(model.FieldConversationsUserId, userID),
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DefaultConversationTitle 新会话的默认标题，为第一个问题的前 70 个字符
+func DefaultConversationTitle(question string) string {
+	return misc.SubString(question, 70)
+}
+
+// ReplaceConversationTitle 将会话标题从 oldTitle 替换为 title，标题已经被修改（例如用户重命名）时不替换
+func (repo *ConversationRepo) ReplaceConversationTitle(ctx context.Context, userID int64, conversationID int64, oldTitle, title string) (bool, error) {
+	affected, err := model.NewConversationsModel(repo.db).UpdateFields(
+		ctx,
+		query.KV{model.FieldConversationsTitle: title},
+		query.Builder().
+			Where(model.FieldConversationsId, conversationID).
+			Where(model.FieldConversationsUserId, userID).
+			Where(model.FieldConversationsTitle, oldTitle),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// DeleteConversation 删除会话以及会话中的所有消息
+func (repo *ConversationRepo) DeleteConversation(ctx context.Context, userID int64, conversationID int64) error {
+	return eloquent.Transaction(repo.db, func(tx query.Database) error {
+		affected, err := model.NewConversationsModel(tx).Delete(
+			ctx,
+			query.Builder().
+				Where(model.FieldConversationsId, conversationID).
+				Where(model.FieldConversationsUserId, userID),
+		)
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return ErrNotFound
+		}
+
+		_, err = model.NewChatMessagesModel(tx).Delete(
+			ctx,
+			query.Builder().
+				Where(model.FieldChatMessagesConversationId, conversationID).
+				Where(model.FieldChatMessagesUserId, userID),
+		)
+		return err
+	})
+}
+
+// DeleteUserConversations 删除用户的所有会话以及消息，用于注销账号
+func (repo *ConversationRepo) DeleteUserConversations(ctx context.Context, userID int64) error {
+	return eloquent.Transaction(repo.db, func(tx query.Database) error {
+		if _, err := model.NewChatMessagesModel(tx).Delete(ctx, query.Builder().Where(model.FieldChatMessagesUserId, userID)); err != nil {
+			return err
+		}
+
+		_, err := model.NewConversationsModel(tx).Delete(ctx, query.Builder().Where(model.FieldConversationsUserId, userID))
+		return err
+	})
+}
+
+// MessageSearchResult 聊天记录检索结果
+type MessageSearchResult struct {
+	MessageID         int64     `json:"message_id"`
+	ConversationID    int64     `json:"conversation_id"`
+	ConversationTitle string    `json:"conversation_title"`
+	Role              string    `json:"role"`
+	Message           string    `json:"message"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// SearchMessages 全文检索用户的聊天记录，按照时间倒序排列
+func (repo *ConversationRepo) SearchMessages(ctx context.Context, userID int64, keyword string, page, perPage int64) ([]MessageSearchResult, query.PaginateMeta, error) {
+	messages, meta, err := model.NewChatMessagesModel(repo.db).Paginate(
+		ctx,
+		page,
+		perPage,
+		query.Builder().
+			Where(model.FieldChatMessagesUserId, userID).
+			WhereRaw("MATCH(`message`) AGAINST (?)", keyword).
+			OrderBy(model.FieldChatMessagesId, "DESC"),
+	)
+	if err != nil || len(messages) == 0 {
+		return nil, meta, err
+	}
+
+	convIDs := array.Uniq(array.Map(messages, func(item model.ChatMessagesN, _ int) int64 {
+		return item.ConversationId.ValueOrZero()
+	}))
+
+	convs, err := model.NewConversationsModel(repo.db).Get(
+		ctx,
+		query.Builder().
+			Where(model.FieldConversationsUserId, userID).
+			WhereIn(model.FieldConversationsId, array.Map(convIDs, func(item int64, _ int) any { return item })...),
+	)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	titles := make(map[int64]string, len(convs))
+	for _, conv := range convs {
+		titles[conv.Id.ValueOrZero()] = conv.Title.ValueOrZero()
+	}
+
+	return array.Map(messages, func(item model.ChatMessagesN, _ int) MessageSearchResult {
+		return MessageSearchResult{
+			MessageID:         item.Id.ValueOrZero(),
+			ConversationID:    item.ConversationId.ValueOrZero(),
+			ConversationTitle: titles[item.ConversationId.ValueOrZero()],
+			Role:              item.Role.ValueOrZero(),
+			Message:           item.Message.ValueOrZero(),
+			CreatedAt:         item.CreatedAt.ValueOrZero(),
+		}
+	}), meta, nil
+}
+
+// Conversation 获取用户的会话
+func (repo *ConversationRepo) Conversation(ctx context.Context, userID int64, conversationID int64) (*Conversation, error) {
+	conv, err := repo.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := buildConversationFromModel(conv.ToConversationsN())
+	return &ret, nil
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/mylxsw/aidea-chat-server/config"
 	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
 	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
 	"gopkg.in/guregu/null.v3"
+	"time"
 )
 
 const (
@@ -85,3 +87,23 @@ func (repo *EventRepo) UpdateEvent(ctx context.Context, id int64, status string)
 
 	return err
 }
+
+// GetWaitingEvents 查询创建时间早于 before 且仍然在等待处理的事件
+func (repo *EventRepo) GetWaitingEvents(ctx context.Context, eventType string, before time.Time, limit int64) ([]model.Events, error) {
+	events, err := model.NewEventsModel(repo.db).Get(
+		ctx,
+		query.Builder().
+			Where(model.FieldEventsStatus, EventStatusWaiting).
+			Where(model.FieldEventsEventType, eventType).
+			Where(model.FieldEventsCreatedAt, "<", before).
+			OrderBy(model.FieldEventsId, "ASC").
+			Limit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(events, func(item model.EventsN, _ int) model.Events {
+		return item.ToEvents()
+	}), nil
+}
@@ -0,0 +1,320 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+// KnowledgeRepo 知识库仓库
+type KnowledgeRepo struct {
+	db   *sql.DB
+	conf *config.Config
+}
+
+// NewKnowledgeRepo create a new KnowledgeRepo
+func NewKnowledgeRepo(db *sql.DB, conf *config.Config) *KnowledgeRepo {
+	return &KnowledgeRepo{db: db, conf: conf}
+}
+
+// KnowledgeBase 知识库
+type KnowledgeBase struct {
+	KBID        string    `json:"kb_id"`
+	UserID      int64     `json:"-"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// KnowledgeDocument 知识库文档
+type KnowledgeDocument struct {
+	ID            int64     `json:"id"`
+	KBID          string    `json:"kb_id"`
+	UserID        int64     `json:"-"`
+	Title         string    `json:"title"`
+	ContentType   string    `json:"content_type"`
+	ContentLength int64     `json:"content_length"`
+	ChunkCount    int64     `json:"chunk_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// KnowledgeChunk 文档分块以及对应的向量
+type KnowledgeChunk struct {
+	ID         int64     `json:"id"`
+	KBID       string    `json:"kb_id"`
+	DocumentID int64     `json:"document_id"`
+	Seq        int64     `json:"seq"`
+	Content    string    `json:"content"`
+	Embedding  []float32 `json:"-"`
+}
+
+func buildKnowledgeBaseFromModel(m model.KnowledgeBasesN) KnowledgeBase {
+	return KnowledgeBase{
+		KBID:        m.KbId.ValueOrZero(),
+		UserID:      m.UserId.ValueOrZero(),
+		Name:        m.Name.ValueOrZero(),
+		Description: m.Description.ValueOrZero(),
+		CreatedAt:   m.CreatedAt.ValueOrZero(),
+	}
+}
+
+func buildKnowledgeDocumentFromModel(m model.KnowledgeDocumentsN) KnowledgeDocument {
+	return KnowledgeDocument{
+		ID:            m.Id.ValueOrZero(),
+		KBID:          m.KbId.ValueOrZero(),
+		UserID:        m.UserId.ValueOrZero(),
+		Title:         m.Title.ValueOrZero(),
+		ContentType:   m.ContentType.ValueOrZero(),
+		ContentLength: m.ContentLength.ValueOrZero(),
+		ChunkCount:    m.ChunkCount.ValueOrZero(),
+		CreatedAt:     m.CreatedAt.ValueOrZero(),
+	}
+}
+
+// CreateKnowledgeBase 创建知识库
+func (repo *KnowledgeRepo) CreateKnowledgeBase(ctx context.Context, userID int64, name, description string) (*KnowledgeBase, error) {
+	kb := KnowledgeBase{
+		KBID:        misc.UUID(),
+		UserID:      userID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+
+	if _, err := model.NewKnowledgeBasesModel(repo.db).Save(ctx, model.KnowledgeBasesN{
+		KbId:        null.StringFrom(kb.KBID),
+		UserId:      null.IntFrom(userID),
+		Name:        null.StringFrom(name),
+		Description: null.StringFrom(description),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &kb, nil
+}
+
+// GetKnowledgeBases 获取用户的所有知识库
+func (repo *KnowledgeRepo) GetKnowledgeBases(ctx context.Context, userID int64) ([]KnowledgeBase, error) {
+	kbs, err := model.NewKnowledgeBasesModel(repo.db).Get(
+		ctx,
+		query.Builder().
+			Where(model.FieldKnowledgeBasesUserId, userID).
+			OrderBy(model.FieldKnowledgeBasesId, "DESC"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(kbs, func(item model.KnowledgeBasesN, _ int) KnowledgeBase {
+		return buildKnowledgeBaseFromModel(item)
+	}), nil
+}
+
+// GetKnowledgeBase 获取用户的知识库
+func (repo *KnowledgeRepo) GetKnowledgeBase(ctx context.Context, userID int64, kbID string) (*KnowledgeBase, error) {
+	kb, err := model.NewKnowledgeBasesModel(repo.db).First(
+		ctx,
+		query.Builder().
+			Where(model.FieldKnowledgeBasesKbId, kbID).
+			Where(model.FieldKnowledgeBasesUserId, userID),
+	)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := buildKnowledgeBaseFromModel(*kb)
+	return &ret, nil
+}
+
+// KnowledgeBasesOwnedBy 检查知识库是否都属于指定用户
+func (repo *KnowledgeRepo) KnowledgeBasesOwnedBy(ctx context.Context, userID int64, kbIDs []string) (bool, error) {
+	if len(kbIDs) == 0 {
+		return true, nil
+	}
+
+	count, err := model.NewKnowledgeBasesModel(repo.db).Count(
+		ctx,
+		query.Builder().
+			WhereIn(model.FieldKnowledgeBasesKbId, array.Map(kbIDs, func(item string, _ int) any { return item })...).
+			Where(model.FieldKnowledgeBasesUserId, userID),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return count == int64(len(kbIDs)), nil
+}
+
+// DeleteKnowledgeBase 删除知识库以及其中的所有文档和分块
+func (repo *KnowledgeRepo) DeleteKnowledgeBase(ctx context.Context, userID int64, kbID string) error {
+	return eloquent.Transaction(repo.db, func(tx query.Database) error {
+		deleted, err := model.NewKnowledgeBasesModel(tx).Delete(
+			ctx,
+			query.Builder().
+				Where(model.FieldKnowledgeBasesKbId, kbID).
+				Where(model.FieldKnowledgeBasesUserId, userID),
+		)
+		if err != nil {
+			return err
+		}
+
+		if deleted == 0 {
+			return ErrNotFound
+		}
+
+		if _, err := model.NewKnowledgeDocumentsModel(tx).Delete(ctx, query.Builder().Where(model.FieldKnowledgeDocumentsKbId, kbID)); err != nil {
+			return err
+		}
+
+		_, err = model.NewKnowledgeChunksModel(tx).Delete(ctx, query.Builder().Where(model.FieldKnowledgeChunksKbId, kbID))
+		return err
+	})
+}
+
+// CreateDocument 创建知识库文档
+func (repo *KnowledgeRepo) CreateDocument(ctx context.Context, doc KnowledgeDocument) (*KnowledgeDocument, error) {
+	id, err := model.NewKnowledgeDocumentsModel(repo.db).Save(ctx, model.KnowledgeDocumentsN{
+		KbId:          null.StringFrom(doc.KBID),
+		UserId:        null.IntFrom(doc.UserID),
+		Title:         null.StringFrom(doc.Title),
+		ContentType:   null.StringFrom(doc.ContentType),
+		ContentLength: null.IntFrom(doc.ContentLength),
+		ChunkCount:    null.IntFrom(doc.ChunkCount),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc.ID = id
+	doc.CreatedAt = time.Now()
+
+	return &doc, nil
+}
+
+// GetDocuments 获取知识库中的所有文档
+func (repo *KnowledgeRepo) GetDocuments(ctx context.Context, userID int64, kbID string) ([]KnowledgeDocument, error) {
+	docs, err := model.NewKnowledgeDocumentsModel(repo.db).Get(
+		ctx,
+		query.Builder().
+			Where(model.FieldKnowledgeDocumentsKbId, kbID).
+			Where(model.FieldKnowledgeDocumentsUserId, userID).
+			OrderBy(model.FieldKnowledgeDocumentsId, "DESC"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(docs, func(item model.KnowledgeDocumentsN, _ int) KnowledgeDocument {
+		return buildKnowledgeDocumentFromModel(item)
+	}), nil
+}
+
+// GetDocumentsByIDs 根据 ID 批量获取文档
+func (repo *KnowledgeRepo) GetDocumentsByIDs(ctx context.Context, ids []int64) ([]KnowledgeDocument, error) {
+	if len(ids) == 0 {
+		return []KnowledgeDocument{}, nil
+	}
+
+	docs, err := model.NewKnowledgeDocumentsModel(repo.db).Get(
+		ctx,
+		query.Builder().WhereIn(model.FieldKnowledgeDocumentsId, array.Map(ids, func(item int64, _ int) any { return item })...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(docs, func(item model.KnowledgeDocumentsN, _ int) KnowledgeDocument {
+		return buildKnowledgeDocumentFromModel(item)
+	}), nil
+}
+
+// DeleteDocument 删除知识库文档以及对应的分块
+func (repo *KnowledgeRepo) DeleteDocument(ctx context.Context, userID int64, kbID string, id int64) error {
+	return eloquent.Transaction(repo.db, func(tx query.Database) error {
+		deleted, err := model.NewKnowledgeDocumentsModel(tx).Delete(
+			ctx,
+			query.Builder().
+				Where(model.FieldKnowledgeDocumentsId, id).
+				Where(model.FieldKnowledgeDocumentsKbId, kbID).
+				Where(model.FieldKnowledgeDocumentsUserId, userID),
+		)
+		if err != nil {
+			return err
+		}
+
+		if deleted == 0 {
+			return ErrNotFound
+		}
+
+		_, err = model.NewKnowledgeChunksModel(tx).Delete(ctx, query.Builder().Where(model.FieldKnowledgeChunksDocumentId, id))
+		return err
+	})
+}
+
+// SaveChunks 保存文档分块以及对应的向量
+func (repo *KnowledgeRepo) SaveChunks(ctx context.Context, chunks []KnowledgeChunk) error {
+	return eloquent.Transaction(repo.db, func(tx query.Database) error {
+		for _, chunk := range chunks {
+			embedding, err := json.Marshal(chunk.Embedding)
+			if err != nil {
+				return err
+			}
+
+			if _, err := model.NewKnowledgeChunksModel(tx).Save(ctx, model.KnowledgeChunksN{
+				KbId:       null.StringFrom(chunk.KBID),
+				DocumentId: null.IntFrom(chunk.DocumentID),
+				Seq:        null.IntFrom(chunk.Seq),
+				Content:    null.StringFrom(chunk.Content),
+				Embedding:  null.StringFrom(string(embedding)),
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetChunks 获取知识库中的分块，最多返回 limit 个
+func (repo *KnowledgeRepo) GetChunks(ctx context.Context, kbIDs []string, limit int64) ([]KnowledgeChunk, error) {
+	if len(kbIDs) == 0 {
+		return []KnowledgeChunk{}, nil
+	}
+
+	chunks, err := model.NewKnowledgeChunksModel(repo.db).Get(
+		ctx,
+		query.Builder().
+			WhereIn(model.FieldKnowledgeChunksKbId, array.Map(kbIDs, func(item string, _ int) any { return item })...).
+			OrderBy(model.FieldKnowledgeChunksId, "ASC").
+			Limit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(chunks, func(item model.KnowledgeChunksN, _ int) KnowledgeChunk {
+		chunk := KnowledgeChunk{
+			ID:         item.Id.ValueOrZero(),
+			KBID:       item.KbId.ValueOrZero(),
+			DocumentID: item.DocumentId.ValueOrZero(),
+			Seq:        item.Seq.ValueOrZero(),
+			Content:    item.Content.ValueOrZero(),
+		}
+
+		_ = json.Unmarshal([]byte(item.Embedding.ValueOrZero()), &chunk.Embedding)
+		return chunk
+	}), nil
+}
@@ -0,0 +1,747 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// ConversationsN is a Conversations object, all fields are nullable
+type ConversationsN struct {
+	original           *conversationsOriginal
+	conversationsModel *ConversationsModel
+
+	Id               null.Int    `json:"id"`
+	UserId           null.Int    `json:"user_id"`
+	RobotId          null.String `json:"robot_id"`
+	Title            null.String `json:"title"`
+	CurrentMessageId null.Int    `json:"current_message_id,omitempty"`
+	LastMessageAt    null.Time   `json:"last_message_at"`
+	CreatedAt        null.Time
+	UpdatedAt        null.Time
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *ConversationsN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for Conversations
+func (inst *ConversationsN) SetModel(conversationsModel *ConversationsModel) {
+	inst.conversationsModel = conversationsModel
+}
+
+// conversationsOriginal is an object which stores original Conversations from database
+type conversationsOriginal struct {
+	Id               null.Int
+	UserId           null.Int
+	RobotId          null.String
+	Title            null.String
+	CurrentMessageId null.Int
+	LastMessageAt    null.Time
+	CreatedAt        null.Time
+	UpdatedAt        null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *ConversationsN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &conversationsOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.RobotId != inst.original.RobotId {
+			return true
+		}
+		if inst.Title != inst.original.Title {
+			return true
+		}
+		if inst.CurrentMessageId != inst.original.CurrentMessageId {
+			return true
+		}
+		if inst.LastMessageAt != inst.original.LastMessageAt {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "robot_id":
+				if inst.RobotId != inst.original.RobotId {
+					return true
+				}
+			case "title":
+				if inst.Title != inst.original.Title {
+					return true
+				}
+			case "current_message_id":
+				if inst.CurrentMessageId != inst.original.CurrentMessageId {
+					return true
+				}
+			case "last_message_at":
+				if inst.LastMessageAt != inst.original.LastMessageAt {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *ConversationsN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &conversationsOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.RobotId != inst.original.RobotId {
+			kv["robot_id"] = inst.RobotId
+		}
+		if inst.Title != inst.original.Title {
+			kv["title"] = inst.Title
+		}
+		if inst.CurrentMessageId != inst.original.CurrentMessageId {
+			kv["current_message_id"] = inst.CurrentMessageId
+		}
+		if inst.LastMessageAt != inst.original.LastMessageAt {
+			kv["last_message_at"] = inst.LastMessageAt
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "robot_id":
+				if inst.RobotId != inst.original.RobotId {
+					kv["robot_id"] = inst.RobotId
+				}
+			case "title":
+				if inst.Title != inst.original.Title {
+					kv["title"] = inst.Title
+				}
+			case "current_message_id":
+				if inst.CurrentMessageId != inst.original.CurrentMessageId {
+					kv["current_message_id"] = inst.CurrentMessageId
+				}
+			case "last_message_at":
+				if inst.LastMessageAt != inst.original.LastMessageAt {
+					kv["last_message_at"] = inst.LastMessageAt
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *ConversationsN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.conversationsModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.conversationsModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a conversations
+func (inst *ConversationsN) Delete(ctx context.Context) error {
+	if inst.conversationsModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.conversationsModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *ConversationsN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type conversationsScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var conversationsGlobalScopes = make([]conversationsScope, 0)
+var conversationsLocalScopes = make([]conversationsScope, 0)
+
+// AddGlobalScopeForConversations assign a global scope to a model
+func AddGlobalScopeForConversations(name string, apply func(builder query.Condition)) {
+	conversationsGlobalScopes = append(conversationsGlobalScopes, conversationsScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForConversations assign a local scope to a model
+func AddLocalScopeForConversations(name string, apply func(builder query.Condition)) {
+	conversationsLocalScopes = append(conversationsLocalScopes, conversationsScope{name: name, apply: apply})
+}
+
+func (m *ConversationsModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range conversationsGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range conversationsLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *ConversationsModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *ConversationsModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type Conversations struct {
+	Id               int64     `json:"id"`
+	UserId           int64     `json:"user_id"`
+	RobotId          string    `json:"robot_id"`
+	Title            string    `json:"title"`
+	CurrentMessageId int64     `json:"current_message_id,omitempty"`
+	LastMessageAt    time.Time `json:"last_message_at"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+func (w Conversations) ToConversationsN(allows ...string) ConversationsN {
+	if len(allows) == 0 {
+		return ConversationsN{
+
+			Id:               null.IntFrom(int64(w.Id)),
+			UserId:           null.IntFrom(int64(w.UserId)),
+			RobotId:          null.StringFrom(w.RobotId),
+			Title:            null.StringFrom(w.Title),
+			CurrentMessageId: null.IntFrom(int64(w.CurrentMessageId)),
+			LastMessageAt:    null.TimeFrom(w.LastMessageAt),
+			CreatedAt:        null.TimeFrom(w.CreatedAt),
+			UpdatedAt:        null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := ConversationsN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "robot_id":
+			res.RobotId = null.StringFrom(w.RobotId)
+		case "title":
+			res.Title = null.StringFrom(w.Title)
+		case "current_message_id":
+			res.CurrentMessageId = null.IntFrom(int64(w.CurrentMessageId))
+		case "last_message_at":
+			res.LastMessageAt = null.TimeFrom(w.LastMessageAt)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w Conversations) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *ConversationsN) ToConversations() Conversations {
+	return Conversations{
+
+		Id:               w.Id.Int64,
+		UserId:           w.UserId.Int64,
+		RobotId:          w.RobotId.String,
+		Title:            w.Title.String,
+		CurrentMessageId: w.CurrentMessageId.Int64,
+		LastMessageAt:    w.LastMessageAt.Time,
+		CreatedAt:        w.CreatedAt.Time,
+		UpdatedAt:        w.UpdatedAt.Time,
+	}
+}
+
+// ConversationsModel is a model which encapsulates the operations of the object
+type ConversationsModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var conversationsTableName = "conversations"
+
+// ConversationsTable return table name for Conversations
+func ConversationsTable() string {
+	return conversationsTableName
+}
+
+const (
+	FieldConversationsId               = "id"
+	FieldConversationsUserId           = "user_id"
+	FieldConversationsRobotId          = "robot_id"
+	FieldConversationsTitle            = "title"
+	FieldConversationsCurrentMessageId = "current_message_id"
+	FieldConversationsLastMessageAt    = "last_message_at"
+	FieldConversationsCreatedAt        = "created_at"
+	FieldConversationsUpdatedAt        = "updated_at"
+)
+
+// ConversationsFields return all fields in Conversations model
+func ConversationsFields() []string {
+	return []string{
+		"id",
+		"user_id",
+		"robot_id",
+		"title",
+		"current_message_id",
+		"last_message_at",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetConversationsTable(tableName string) {
+	conversationsTableName = tableName
+}
+
+// NewConversationsModel create a ConversationsModel
+func NewConversationsModel(db query.Database) *ConversationsModel {
+	return &ConversationsModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           conversationsTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *ConversationsModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *ConversationsModel) clone() *ConversationsModel {
+	return &ConversationsModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *ConversationsModel) WithoutGlobalScopes(names ...string) *ConversationsModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *ConversationsModel) WithLocalScopes(names ...string) *ConversationsModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *ConversationsModel) Condition(builder query.SQLBuilder) *ConversationsModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *ConversationsModel) Find(ctx context.Context, id int64) (*ConversationsN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *ConversationsModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *ConversationsModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *ConversationsModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]ConversationsN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *ConversationsModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]ConversationsN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"user_id",
+			"robot_id",
+			"title",
+			"current_message_id",
+			"last_message_at",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "robot_id":
+			selectFields = append(selectFields, f)
+		case "title":
+			selectFields = append(selectFields, f)
+		case "current_message_id":
+			selectFields = append(selectFields, f)
+		case "last_message_at":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*ConversationsN, []interface{}) {
+		var conversationsVar ConversationsN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &conversationsVar.Id)
+			case "user_id":
+				scanFields = append(scanFields, &conversationsVar.UserId)
+			case "robot_id":
+				scanFields = append(scanFields, &conversationsVar.RobotId)
+			case "title":
+				scanFields = append(scanFields, &conversationsVar.Title)
+			case "current_message_id":
+				scanFields = append(scanFields, &conversationsVar.CurrentMessageId)
+			case "last_message_at":
+				scanFields = append(scanFields, &conversationsVar.LastMessageAt)
+			case "created_at":
+				scanFields = append(scanFields, &conversationsVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &conversationsVar.UpdatedAt)
+			}
+		}
+
+		return &conversationsVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	conversationss := make([]ConversationsN, 0)
+	for rows.Next() {
+		conversationsReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		conversationsReal.original = &conversationsOriginal{}
+		_ = query.Copy(conversationsReal, conversationsReal.original)
+
+		conversationsReal.SetModel(m)
+		conversationss = append(conversationss, *conversationsReal)
+	}
+
+	return conversationss, nil
+}
+
+// First return first result for given query
+func (m *ConversationsModel) First(ctx context.Context, builders ...query.SQLBuilder) (*ConversationsN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new conversations to database
+func (m *ConversationsModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all conversationss to database
+func (m *ConversationsModel) SaveAll(ctx context.Context, conversationss []ConversationsN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, conversations := range conversationss {
+		id, err := m.Save(ctx, conversations)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a conversations to database
+func (m *ConversationsModel) Save(ctx context.Context, conversations ConversationsN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, conversations.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new conversations or update it when it has a id > 0
+func (m *ConversationsModel) SaveOrUpdate(ctx context.Context, conversations ConversationsN, onlyFields ...string) (id int64, updated bool, err error) {
+	if conversations.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, conversations.Id.Int64, conversations, onlyFields...)
+		return conversations.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, conversations, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *ConversationsModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *ConversationsModel) Update(ctx context.Context, builder query.SQLBuilder, conversations ConversationsN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, conversations.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *ConversationsModel) UpdateById(ctx context.Context, id int64, conversations ConversationsN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, conversations.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *ConversationsModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *ConversationsModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
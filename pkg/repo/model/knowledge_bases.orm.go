@@ -0,0 +1,719 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// KnowledgeBasesN is a KnowledgeBases object, all fields are nullable
+type KnowledgeBasesN struct {
+	original            *knowledgeBasesOriginal
+	knowledgeBasesModel *KnowledgeBasesModel
+
+	Id          null.Int    `json:"id"`
+	KbId        null.String `json:"kb_id"`
+	UserId      null.Int    `json:"user_id"`
+	Name        null.String `json:"name"`
+	Description null.String `json:"description"`
+	CreatedAt   null.Time
+	UpdatedAt   null.Time
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *KnowledgeBasesN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for KnowledgeBases
+func (inst *KnowledgeBasesN) SetModel(knowledgeBasesModel *KnowledgeBasesModel) {
+	inst.knowledgeBasesModel = knowledgeBasesModel
+}
+
+// knowledgeBasesOriginal is an object which stores original KnowledgeBases from database
+type knowledgeBasesOriginal struct {
+	Id          null.Int
+	KbId        null.String
+	UserId      null.Int
+	Name        null.String
+	Description null.String
+	CreatedAt   null.Time
+	UpdatedAt   null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *KnowledgeBasesN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &knowledgeBasesOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.KbId != inst.original.KbId {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.Name != inst.original.Name {
+			return true
+		}
+		if inst.Description != inst.original.Description {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "kb_id":
+				if inst.KbId != inst.original.KbId {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					return true
+				}
+			case "description":
+				if inst.Description != inst.original.Description {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *KnowledgeBasesN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &knowledgeBasesOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.KbId != inst.original.KbId {
+			kv["kb_id"] = inst.KbId
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.Name != inst.original.Name {
+			kv["name"] = inst.Name
+		}
+		if inst.Description != inst.original.Description {
+			kv["description"] = inst.Description
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "kb_id":
+				if inst.KbId != inst.original.KbId {
+					kv["kb_id"] = inst.KbId
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					kv["name"] = inst.Name
+				}
+			case "description":
+				if inst.Description != inst.original.Description {
+					kv["description"] = inst.Description
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *KnowledgeBasesN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.knowledgeBasesModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.knowledgeBasesModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a knowledge_bases
+func (inst *KnowledgeBasesN) Delete(ctx context.Context) error {
+	if inst.knowledgeBasesModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.knowledgeBasesModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *KnowledgeBasesN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type knowledgeBasesScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var knowledgeBasesGlobalScopes = make([]knowledgeBasesScope, 0)
+var knowledgeBasesLocalScopes = make([]knowledgeBasesScope, 0)
+
+// AddGlobalScopeForKnowledgeBases assign a global scope to a model
+func AddGlobalScopeForKnowledgeBases(name string, apply func(builder query.Condition)) {
+	knowledgeBasesGlobalScopes = append(knowledgeBasesGlobalScopes, knowledgeBasesScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForKnowledgeBases assign a local scope to a model
+func AddLocalScopeForKnowledgeBases(name string, apply func(builder query.Condition)) {
+	knowledgeBasesLocalScopes = append(knowledgeBasesLocalScopes, knowledgeBasesScope{name: name, apply: apply})
+}
+
+func (m *KnowledgeBasesModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range knowledgeBasesGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range knowledgeBasesLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *KnowledgeBasesModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *KnowledgeBasesModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type KnowledgeBases struct {
+	Id          int64  `json:"id"`
+	KbId        string `json:"kb_id"`
+	UserId      int64  `json:"user_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (w KnowledgeBases) ToKnowledgeBasesN(allows ...string) KnowledgeBasesN {
+	if len(allows) == 0 {
+		return KnowledgeBasesN{
+
+			Id:          null.IntFrom(int64(w.Id)),
+			KbId:        null.StringFrom(w.KbId),
+			UserId:      null.IntFrom(int64(w.UserId)),
+			Name:        null.StringFrom(w.Name),
+			Description: null.StringFrom(w.Description),
+			CreatedAt:   null.TimeFrom(w.CreatedAt),
+			UpdatedAt:   null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := KnowledgeBasesN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "kb_id":
+			res.KbId = null.StringFrom(w.KbId)
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "name":
+			res.Name = null.StringFrom(w.Name)
+		case "description":
+			res.Description = null.StringFrom(w.Description)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w KnowledgeBases) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *KnowledgeBasesN) ToKnowledgeBases() KnowledgeBases {
+	return KnowledgeBases{
+
+		Id:          w.Id.Int64,
+		KbId:        w.KbId.String,
+		UserId:      w.UserId.Int64,
+		Name:        w.Name.String,
+		Description: w.Description.String,
+		CreatedAt:   w.CreatedAt.Time,
+		UpdatedAt:   w.UpdatedAt.Time,
+	}
+}
+
+// KnowledgeBasesModel is a model which encapsulates the operations of the object
+type KnowledgeBasesModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var knowledgeBasesTableName = "knowledge_bases"
+
+// KnowledgeBasesTable return table name for KnowledgeBases
+func KnowledgeBasesTable() string {
+	return knowledgeBasesTableName
+}
+
+const (
+	FieldKnowledgeBasesId          = "id"
+	FieldKnowledgeBasesKbId        = "kb_id"
+	FieldKnowledgeBasesUserId      = "user_id"
+	FieldKnowledgeBasesName        = "name"
+	FieldKnowledgeBasesDescription = "description"
+	FieldKnowledgeBasesCreatedAt   = "created_at"
+	FieldKnowledgeBasesUpdatedAt   = "updated_at"
+)
+
+// KnowledgeBasesFields return all fields in KnowledgeBases model
+func KnowledgeBasesFields() []string {
+	return []string{
+		"id",
+		"kb_id",
+		"user_id",
+		"name",
+		"description",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetKnowledgeBasesTable(tableName string) {
+	knowledgeBasesTableName = tableName
+}
+
+// NewKnowledgeBasesModel create a KnowledgeBasesModel
+func NewKnowledgeBasesModel(db query.Database) *KnowledgeBasesModel {
+	return &KnowledgeBasesModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           knowledgeBasesTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *KnowledgeBasesModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *KnowledgeBasesModel) clone() *KnowledgeBasesModel {
+	return &KnowledgeBasesModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *KnowledgeBasesModel) WithoutGlobalScopes(names ...string) *KnowledgeBasesModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *KnowledgeBasesModel) WithLocalScopes(names ...string) *KnowledgeBasesModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *KnowledgeBasesModel) Condition(builder query.SQLBuilder) *KnowledgeBasesModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *KnowledgeBasesModel) Find(ctx context.Context, id int64) (*KnowledgeBasesN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *KnowledgeBasesModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *KnowledgeBasesModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *KnowledgeBasesModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]KnowledgeBasesN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *KnowledgeBasesModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]KnowledgeBasesN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"kb_id",
+			"user_id",
+			"name",
+			"description",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "kb_id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "name":
+			selectFields = append(selectFields, f)
+		case "description":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*KnowledgeBasesN, []interface{}) {
+		var knowledgeBasesVar KnowledgeBasesN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &knowledgeBasesVar.Id)
+			case "kb_id":
+				scanFields = append(scanFields, &knowledgeBasesVar.KbId)
+			case "user_id":
+				scanFields = append(scanFields, &knowledgeBasesVar.UserId)
+			case "name":
+				scanFields = append(scanFields, &knowledgeBasesVar.Name)
+			case "description":
+				scanFields = append(scanFields, &knowledgeBasesVar.Description)
+			case "created_at":
+				scanFields = append(scanFields, &knowledgeBasesVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &knowledgeBasesVar.UpdatedAt)
+			}
+		}
+
+		return &knowledgeBasesVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	knowledgeBasess := make([]KnowledgeBasesN, 0)
+	for rows.Next() {
+		knowledgeBasesReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		knowledgeBasesReal.original = &knowledgeBasesOriginal{}
+		_ = query.Copy(knowledgeBasesReal, knowledgeBasesReal.original)
+
+		knowledgeBasesReal.SetModel(m)
+		knowledgeBasess = append(knowledgeBasess, *knowledgeBasesReal)
+	}
+
+	return knowledgeBasess, nil
+}
+
+// First return first result for given query
+func (m *KnowledgeBasesModel) First(ctx context.Context, builders ...query.SQLBuilder) (*KnowledgeBasesN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new knowledge_bases to database
+func (m *KnowledgeBasesModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all knowledge_basess to database
+func (m *KnowledgeBasesModel) SaveAll(ctx context.Context, knowledgeBasess []KnowledgeBasesN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, knowledgeBases := range knowledgeBasess {
+		id, err := m.Save(ctx, knowledgeBases)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a knowledge_bases to database
+func (m *KnowledgeBasesModel) Save(ctx context.Context, knowledgeBases KnowledgeBasesN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, knowledgeBases.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new knowledge_bases or update it when it has a id > 0
+func (m *KnowledgeBasesModel) SaveOrUpdate(ctx context.Context, knowledgeBases KnowledgeBasesN, onlyFields ...string) (id int64, updated bool, err error) {
+	if knowledgeBases.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, knowledgeBases.Id.Int64, knowledgeBases, onlyFields...)
+		return knowledgeBases.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, knowledgeBases, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *KnowledgeBasesModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *KnowledgeBasesModel) Update(ctx context.Context, builder query.SQLBuilder, knowledgeBases KnowledgeBasesN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, knowledgeBases.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *KnowledgeBasesModel) UpdateById(ctx context.Context, id int64, knowledgeBases KnowledgeBasesN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, knowledgeBases.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *KnowledgeBasesModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *KnowledgeBasesModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
@@ -0,0 +1,747 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// KnowledgeChunksN is a KnowledgeChunks object, all fields are nullable
+type KnowledgeChunksN struct {
+	original             *knowledgeChunksOriginal
+	knowledgeChunksModel *KnowledgeChunksModel
+
+	Id         null.Int    `json:"id"`
+	KbId       null.String `json:"kb_id"`
+	DocumentId null.Int    `json:"document_id"`
+	Seq        null.Int    `json:"seq"`
+	Content    null.String `json:"content"`
+	Embedding  null.String `json:"embedding"`
+	CreatedAt  null.Time
+	UpdatedAt  null.Time
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *KnowledgeChunksN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for KnowledgeChunks
+func (inst *KnowledgeChunksN) SetModel(knowledgeChunksModel *KnowledgeChunksModel) {
+	inst.knowledgeChunksModel = knowledgeChunksModel
+}
+
+// knowledgeChunksOriginal is an object which stores original KnowledgeChunks from database
+type knowledgeChunksOriginal struct {
+	Id         null.Int
+	KbId       null.String
+	DocumentId null.Int
+	Seq        null.Int
+	Content    null.String
+	Embedding  null.String
+	CreatedAt  null.Time
+	UpdatedAt  null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *KnowledgeChunksN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &knowledgeChunksOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.KbId != inst.original.KbId {
+			return true
+		}
+		if inst.DocumentId != inst.original.DocumentId {
+			return true
+		}
+		if inst.Seq != inst.original.Seq {
+			return true
+		}
+		if inst.Content != inst.original.Content {
+			return true
+		}
+		if inst.Embedding != inst.original.Embedding {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "kb_id":
+				if inst.KbId != inst.original.KbId {
+					return true
+				}
+			case "document_id":
+				if inst.DocumentId != inst.original.DocumentId {
+					return true
+				}
+			case "seq":
+				if inst.Seq != inst.original.Seq {
+					return true
+				}
+			case "content":
+				if inst.Content != inst.original.Content {
+					return true
+				}
+			case "embedding":
+				if inst.Embedding != inst.original.Embedding {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *KnowledgeChunksN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &knowledgeChunksOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.KbId != inst.original.KbId {
+			kv["kb_id"] = inst.KbId
+		}
+		if inst.DocumentId != inst.original.DocumentId {
+			kv["document_id"] = inst.DocumentId
+		}
+		if inst.Seq != inst.original.Seq {
+			kv["seq"] = inst.Seq
+		}
+		if inst.Content != inst.original.Content {
+			kv["content"] = inst.Content
+		}
+		if inst.Embedding != inst.original.Embedding {
+			kv["embedding"] = inst.Embedding
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "kb_id":
+				if inst.KbId != inst.original.KbId {
+					kv["kb_id"] = inst.KbId
+				}
+			case "document_id":
+				if inst.DocumentId != inst.original.DocumentId {
+					kv["document_id"] = inst.DocumentId
+				}
+			case "seq":
+				if inst.Seq != inst.original.Seq {
+					kv["seq"] = inst.Seq
+				}
+			case "content":
+				if inst.Content != inst.original.Content {
+					kv["content"] = inst.Content
+				}
+			case "embedding":
+				if inst.Embedding != inst.original.Embedding {
+					kv["embedding"] = inst.Embedding
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *KnowledgeChunksN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.knowledgeChunksModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.knowledgeChunksModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a knowledge_chunks
+func (inst *KnowledgeChunksN) Delete(ctx context.Context) error {
+	if inst.knowledgeChunksModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.knowledgeChunksModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *KnowledgeChunksN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type knowledgeChunksScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var knowledgeChunksGlobalScopes = make([]knowledgeChunksScope, 0)
+var knowledgeChunksLocalScopes = make([]knowledgeChunksScope, 0)
+
+// AddGlobalScopeForKnowledgeChunks assign a global scope to a model
+func AddGlobalScopeForKnowledgeChunks(name string, apply func(builder query.Condition)) {
+	knowledgeChunksGlobalScopes = append(knowledgeChunksGlobalScopes, knowledgeChunksScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForKnowledgeChunks assign a local scope to a model
+func AddLocalScopeForKnowledgeChunks(name string, apply func(builder query.Condition)) {
+	knowledgeChunksLocalScopes = append(knowledgeChunksLocalScopes, knowledgeChunksScope{name: name, apply: apply})
+}
+
+func (m *KnowledgeChunksModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range knowledgeChunksGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range knowledgeChunksLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *KnowledgeChunksModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *KnowledgeChunksModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type KnowledgeChunks struct {
+	Id         int64  `json:"id"`
+	KbId       string `json:"kb_id"`
+	DocumentId int64  `json:"document_id"`
+	Seq        int64  `json:"seq"`
+	Content    string `json:"content"`
+	Embedding  string `json:"embedding"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (w KnowledgeChunks) ToKnowledgeChunksN(allows ...string) KnowledgeChunksN {
+	if len(allows) == 0 {
+		return KnowledgeChunksN{
+
+			Id:         null.IntFrom(int64(w.Id)),
+			KbId:       null.StringFrom(w.KbId),
+			DocumentId: null.IntFrom(int64(w.DocumentId)),
+			Seq:        null.IntFrom(int64(w.Seq)),
+			Content:    null.StringFrom(w.Content),
+			Embedding:  null.StringFrom(w.Embedding),
+			CreatedAt:  null.TimeFrom(w.CreatedAt),
+			UpdatedAt:  null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := KnowledgeChunksN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "kb_id":
+			res.KbId = null.StringFrom(w.KbId)
+		case "document_id":
+			res.DocumentId = null.IntFrom(int64(w.DocumentId))
+		case "seq":
+			res.Seq = null.IntFrom(int64(w.Seq))
+		case "content":
+			res.Content = null.StringFrom(w.Content)
+		case "embedding":
+			res.Embedding = null.StringFrom(w.Embedding)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w KnowledgeChunks) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *KnowledgeChunksN) ToKnowledgeChunks() KnowledgeChunks {
+	return KnowledgeChunks{
+
+		Id:         w.Id.Int64,
+		KbId:       w.KbId.String,
+		DocumentId: w.DocumentId.Int64,
+		Seq:        w.Seq.Int64,
+		Content:    w.Content.String,
+		Embedding:  w.Embedding.String,
+		CreatedAt:  w.CreatedAt.Time,
+		UpdatedAt:  w.UpdatedAt.Time,
+	}
+}
+
+// KnowledgeChunksModel is a model which encapsulates the operations of the object
+type KnowledgeChunksModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var knowledgeChunksTableName = "knowledge_chunks"
+
+// KnowledgeChunksTable return table name for KnowledgeChunks
+func KnowledgeChunksTable() string {
+	return knowledgeChunksTableName
+}
+
+const (
+	FieldKnowledgeChunksId         = "id"
+	FieldKnowledgeChunksKbId       = "kb_id"
+	FieldKnowledgeChunksDocumentId = "document_id"
+	FieldKnowledgeChunksSeq        = "seq"
+	FieldKnowledgeChunksContent    = "content"
+	FieldKnowledgeChunksEmbedding  = "embedding"
+	FieldKnowledgeChunksCreatedAt  = "created_at"
+	FieldKnowledgeChunksUpdatedAt  = "updated_at"
+)
+
+// KnowledgeChunksFields return all fields in KnowledgeChunks model
+func KnowledgeChunksFields() []string {
+	return []string{
+		"id",
+		"kb_id",
+		"document_id",
+		"seq",
+		"content",
+		"embedding",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetKnowledgeChunksTable(tableName string) {
+	knowledgeChunksTableName = tableName
+}
+
+// NewKnowledgeChunksModel create a KnowledgeChunksModel
+func NewKnowledgeChunksModel(db query.Database) *KnowledgeChunksModel {
+	return &KnowledgeChunksModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           knowledgeChunksTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *KnowledgeChunksModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *KnowledgeChunksModel) clone() *KnowledgeChunksModel {
+	return &KnowledgeChunksModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *KnowledgeChunksModel) WithoutGlobalScopes(names ...string) *KnowledgeChunksModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *KnowledgeChunksModel) WithLocalScopes(names ...string) *KnowledgeChunksModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *KnowledgeChunksModel) Condition(builder query.SQLBuilder) *KnowledgeChunksModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *KnowledgeChunksModel) Find(ctx context.Context, id int64) (*KnowledgeChunksN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *KnowledgeChunksModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *KnowledgeChunksModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *KnowledgeChunksModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]KnowledgeChunksN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *KnowledgeChunksModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]KnowledgeChunksN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"kb_id",
+			"document_id",
+			"seq",
+			"content",
+			"embedding",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "kb_id":
+			selectFields = append(selectFields, f)
+		case "document_id":
+			selectFields = append(selectFields, f)
+		case "seq":
+			selectFields = append(selectFields, f)
+		case "content":
+			selectFields = append(selectFields, f)
+		case "embedding":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*KnowledgeChunksN, []interface{}) {
+		var knowledgeChunksVar KnowledgeChunksN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &knowledgeChunksVar.Id)
+			case "kb_id":
+				scanFields = append(scanFields, &knowledgeChunksVar.KbId)
+			case "document_id":
+				scanFields = append(scanFields, &knowledgeChunksVar.DocumentId)
+			case "seq":
+				scanFields = append(scanFields, &knowledgeChunksVar.Seq)
+			case "content":
+				scanFields = append(scanFields, &knowledgeChunksVar.Content)
+			case "embedding":
+				scanFields = append(scanFields, &knowledgeChunksVar.Embedding)
+			case "created_at":
+				scanFields = append(scanFields, &knowledgeChunksVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &knowledgeChunksVar.UpdatedAt)
+			}
+		}
+
+		return &knowledgeChunksVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	knowledgeChunkss := make([]KnowledgeChunksN, 0)
+	for rows.Next() {
+		knowledgeChunksReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		knowledgeChunksReal.original = &knowledgeChunksOriginal{}
+		_ = query.Copy(knowledgeChunksReal, knowledgeChunksReal.original)
+
+		knowledgeChunksReal.SetModel(m)
+		knowledgeChunkss = append(knowledgeChunkss, *knowledgeChunksReal)
+	}
+
+	return knowledgeChunkss, nil
+}
+
+// First return first result for given query
+func (m *KnowledgeChunksModel) First(ctx context.Context, builders ...query.SQLBuilder) (*KnowledgeChunksN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new knowledge_chunks to database
+func (m *KnowledgeChunksModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all knowledge_chunkss to database
+func (m *KnowledgeChunksModel) SaveAll(ctx context.Context, knowledgeChunkss []KnowledgeChunksN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, knowledgeChunks := range knowledgeChunkss {
+		id, err := m.Save(ctx, knowledgeChunks)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a knowledge_chunks to database
+func (m *KnowledgeChunksModel) Save(ctx context.Context, knowledgeChunks KnowledgeChunksN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, knowledgeChunks.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new knowledge_chunks or update it when it has a id > 0
+func (m *KnowledgeChunksModel) SaveOrUpdate(ctx context.Context, knowledgeChunks KnowledgeChunksN, onlyFields ...string) (id int64, updated bool, err error) {
+	if knowledgeChunks.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, knowledgeChunks.Id.Int64, knowledgeChunks, onlyFields...)
+		return knowledgeChunks.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, knowledgeChunks, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *KnowledgeChunksModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *KnowledgeChunksModel) Update(ctx context.Context, builder query.SQLBuilder, knowledgeChunks KnowledgeChunksN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, knowledgeChunks.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *KnowledgeChunksModel) UpdateById(ctx context.Context, id int64, knowledgeChunks KnowledgeChunksN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, knowledgeChunks.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *KnowledgeChunksModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *KnowledgeChunksModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
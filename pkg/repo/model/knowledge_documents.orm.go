@@ -0,0 +1,775 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// KnowledgeDocumentsN is a KnowledgeDocuments object, all fields are nullable
+type KnowledgeDocumentsN struct {
+	original                *knowledgeDocumentsOriginal
+	knowledgeDocumentsModel *KnowledgeDocumentsModel
+
+	Id            null.Int    `json:"id"`
+	KbId          null.String `json:"kb_id"`
+	UserId        null.Int    `json:"user_id"`
+	Title         null.String `json:"title"`
+	ContentType   null.String `json:"content_type"`
+	ContentLength null.Int    `json:"content_length"`
+	ChunkCount    null.Int    `json:"chunk_count"`
+	CreatedAt     null.Time
+	UpdatedAt     null.Time
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *KnowledgeDocumentsN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for KnowledgeDocuments
+func (inst *KnowledgeDocumentsN) SetModel(knowledgeDocumentsModel *KnowledgeDocumentsModel) {
+	inst.knowledgeDocumentsModel = knowledgeDocumentsModel
+}
+
+// knowledgeDocumentsOriginal is an object which stores original KnowledgeDocuments from database
+type knowledgeDocumentsOriginal struct {
+	Id            null.Int
+	KbId          null.String
+	UserId        null.Int
+	Title         null.String
+	ContentType   null.String
+	ContentLength null.Int
+	ChunkCount    null.Int
+	CreatedAt     null.Time
+	UpdatedAt     null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *KnowledgeDocumentsN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &knowledgeDocumentsOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.KbId != inst.original.KbId {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.Title != inst.original.Title {
+			return true
+		}
+		if inst.ContentType != inst.original.ContentType {
+			return true
+		}
+		if inst.ContentLength != inst.original.ContentLength {
+			return true
+		}
+		if inst.ChunkCount != inst.original.ChunkCount {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "kb_id":
+				if inst.KbId != inst.original.KbId {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "title":
+				if inst.Title != inst.original.Title {
+					return true
+				}
+			case "content_type":
+				if inst.ContentType != inst.original.ContentType {
+					return true
+				}
+			case "content_length":
+				if inst.ContentLength != inst.original.ContentLength {
+					return true
+				}
+			case "chunk_count":
+				if inst.ChunkCount != inst.original.ChunkCount {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *KnowledgeDocumentsN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &knowledgeDocumentsOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.KbId != inst.original.KbId {
+			kv["kb_id"] = inst.KbId
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.Title != inst.original.Title {
+			kv["title"] = inst.Title
+		}
+		if inst.ContentType != inst.original.ContentType {
+			kv["content_type"] = inst.ContentType
+		}
+		if inst.ContentLength != inst.original.ContentLength {
+			kv["content_length"] = inst.ContentLength
+		}
+		if inst.ChunkCount != inst.original.ChunkCount {
+			kv["chunk_count"] = inst.ChunkCount
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "kb_id":
+				if inst.KbId != inst.original.KbId {
+					kv["kb_id"] = inst.KbId
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "title":
+				if inst.Title != inst.original.Title {
+					kv["title"] = inst.Title
+				}
+			case "content_type":
+				if inst.ContentType != inst.original.ContentType {
+					kv["content_type"] = inst.ContentType
+				}
+			case "content_length":
+				if inst.ContentLength != inst.original.ContentLength {
+					kv["content_length"] = inst.ContentLength
+				}
+			case "chunk_count":
+				if inst.ChunkCount != inst.original.ChunkCount {
+					kv["chunk_count"] = inst.ChunkCount
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *KnowledgeDocumentsN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.knowledgeDocumentsModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.knowledgeDocumentsModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a knowledge_documents
+func (inst *KnowledgeDocumentsN) Delete(ctx context.Context) error {
+	if inst.knowledgeDocumentsModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.knowledgeDocumentsModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *KnowledgeDocumentsN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type knowledgeDocumentsScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var knowledgeDocumentsGlobalScopes = make([]knowledgeDocumentsScope, 0)
+var knowledgeDocumentsLocalScopes = make([]knowledgeDocumentsScope, 0)
+
+// AddGlobalScopeForKnowledgeDocuments assign a global scope to a model
+func AddGlobalScopeForKnowledgeDocuments(name string, apply func(builder query.Condition)) {
+	knowledgeDocumentsGlobalScopes = append(knowledgeDocumentsGlobalScopes, knowledgeDocumentsScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForKnowledgeDocuments assign a local scope to a model
+func AddLocalScopeForKnowledgeDocuments(name string, apply func(builder query.Condition)) {
+	knowledgeDocumentsLocalScopes = append(knowledgeDocumentsLocalScopes, knowledgeDocumentsScope{name: name, apply: apply})
+}
+
+func (m *KnowledgeDocumentsModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range knowledgeDocumentsGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range knowledgeDocumentsLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *KnowledgeDocumentsModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *KnowledgeDocumentsModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type KnowledgeDocuments struct {
+	Id            int64  `json:"id"`
+	KbId          string `json:"kb_id"`
+	UserId        int64  `json:"user_id"`
+	Title         string `json:"title"`
+	ContentType   string `json:"content_type"`
+	ContentLength int64  `json:"content_length"`
+	ChunkCount    int64  `json:"chunk_count"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (w KnowledgeDocuments) ToKnowledgeDocumentsN(allows ...string) KnowledgeDocumentsN {
+	if len(allows) == 0 {
+		return KnowledgeDocumentsN{
+
+			Id:            null.IntFrom(int64(w.Id)),
+			KbId:          null.StringFrom(w.KbId),
+			UserId:        null.IntFrom(int64(w.UserId)),
+			Title:         null.StringFrom(w.Title),
+			ContentType:   null.StringFrom(w.ContentType),
+			ContentLength: null.IntFrom(int64(w.ContentLength)),
+			ChunkCount:    null.IntFrom(int64(w.ChunkCount)),
+			CreatedAt:     null.TimeFrom(w.CreatedAt),
+			UpdatedAt:     null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := KnowledgeDocumentsN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "kb_id":
+			res.KbId = null.StringFrom(w.KbId)
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "title":
+			res.Title = null.StringFrom(w.Title)
+		case "content_type":
+			res.ContentType = null.StringFrom(w.ContentType)
+		case "content_length":
+			res.ContentLength = null.IntFrom(int64(w.ContentLength))
+		case "chunk_count":
+			res.ChunkCount = null.IntFrom(int64(w.ChunkCount))
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w KnowledgeDocuments) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *KnowledgeDocumentsN) ToKnowledgeDocuments() KnowledgeDocuments {
+	return KnowledgeDocuments{
+
+		Id:            w.Id.Int64,
+		KbId:          w.KbId.String,
+		UserId:        w.UserId.Int64,
+		Title:         w.Title.String,
+		ContentType:   w.ContentType.String,
+		ContentLength: w.ContentLength.Int64,
+		ChunkCount:    w.ChunkCount.Int64,
+		CreatedAt:     w.CreatedAt.Time,
+		UpdatedAt:     w.UpdatedAt.Time,
+	}
+}
+
+// KnowledgeDocumentsModel is a model which encapsulates the operations of the object
+type KnowledgeDocumentsModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var knowledgeDocumentsTableName = "knowledge_documents"
+
+// KnowledgeDocumentsTable return table name for KnowledgeDocuments
+func KnowledgeDocumentsTable() string {
+	return knowledgeDocumentsTableName
+}
+
+const (
+	FieldKnowledgeDocumentsId            = "id"
+	FieldKnowledgeDocumentsKbId          = "kb_id"
+	FieldKnowledgeDocumentsUserId        = "user_id"
+	FieldKnowledgeDocumentsTitle         = "title"
+	FieldKnowledgeDocumentsContentType   = "content_type"
+	FieldKnowledgeDocumentsContentLength = "content_length"
+	FieldKnowledgeDocumentsChunkCount    = "chunk_count"
+	FieldKnowledgeDocumentsCreatedAt     = "created_at"
+	FieldKnowledgeDocumentsUpdatedAt     = "updated_at"
+)
+
+// KnowledgeDocumentsFields return all fields in KnowledgeDocuments model
+func KnowledgeDocumentsFields() []string {
+	return []string{
+		"id",
+		"kb_id",
+		"user_id",
+		"title",
+		"content_type",
+		"content_length",
+		"chunk_count",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetKnowledgeDocumentsTable(tableName string) {
+	knowledgeDocumentsTableName = tableName
+}
+
+// NewKnowledgeDocumentsModel create a KnowledgeDocumentsModel
+func NewKnowledgeDocumentsModel(db query.Database) *KnowledgeDocumentsModel {
+	return &KnowledgeDocumentsModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           knowledgeDocumentsTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *KnowledgeDocumentsModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *KnowledgeDocumentsModel) clone() *KnowledgeDocumentsModel {
+	return &KnowledgeDocumentsModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *KnowledgeDocumentsModel) WithoutGlobalScopes(names ...string) *KnowledgeDocumentsModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *KnowledgeDocumentsModel) WithLocalScopes(names ...string) *KnowledgeDocumentsModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *KnowledgeDocumentsModel) Condition(builder query.SQLBuilder) *KnowledgeDocumentsModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *KnowledgeDocumentsModel) Find(ctx context.Context, id int64) (*KnowledgeDocumentsN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *KnowledgeDocumentsModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *KnowledgeDocumentsModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *KnowledgeDocumentsModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]KnowledgeDocumentsN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *KnowledgeDocumentsModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]KnowledgeDocumentsN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"kb_id",
+			"user_id",
+			"title",
+			"content_type",
+			"content_length",
+			"chunk_count",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "kb_id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "title":
+			selectFields = append(selectFields, f)
+		case "content_type":
+			selectFields = append(selectFields, f)
+		case "content_length":
+			selectFields = append(selectFields, f)
+		case "chunk_count":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*KnowledgeDocumentsN, []interface{}) {
+		var knowledgeDocumentsVar KnowledgeDocumentsN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.Id)
+			case "kb_id":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.KbId)
+			case "user_id":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.UserId)
+			case "title":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.Title)
+			case "content_type":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.ContentType)
+			case "content_length":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.ContentLength)
+			case "chunk_count":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.ChunkCount)
+			case "created_at":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &knowledgeDocumentsVar.UpdatedAt)
+			}
+		}
+
+		return &knowledgeDocumentsVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	knowledgeDocumentss := make([]KnowledgeDocumentsN, 0)
+	for rows.Next() {
+		knowledgeDocumentsReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		knowledgeDocumentsReal.original = &knowledgeDocumentsOriginal{}
+		_ = query.Copy(knowledgeDocumentsReal, knowledgeDocumentsReal.original)
+
+		knowledgeDocumentsReal.SetModel(m)
+		knowledgeDocumentss = append(knowledgeDocumentss, *knowledgeDocumentsReal)
+	}
+
+	return knowledgeDocumentss, nil
+}
+
+// First return first result for given query
+func (m *KnowledgeDocumentsModel) First(ctx context.Context, builders ...query.SQLBuilder) (*KnowledgeDocumentsN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new knowledge_documents to database
+func (m *KnowledgeDocumentsModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all knowledge_documentss to database
+func (m *KnowledgeDocumentsModel) SaveAll(ctx context.Context, knowledgeDocumentss []KnowledgeDocumentsN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, knowledgeDocuments := range knowledgeDocumentss {
+		id, err := m.Save(ctx, knowledgeDocuments)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a knowledge_documents to database
+func (m *KnowledgeDocumentsModel) Save(ctx context.Context, knowledgeDocuments KnowledgeDocumentsN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, knowledgeDocuments.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new knowledge_documents or update it when it has a id > 0
+func (m *KnowledgeDocumentsModel) SaveOrUpdate(ctx context.Context, knowledgeDocuments KnowledgeDocumentsN, onlyFields ...string) (id int64, updated bool, err error) {
+	if knowledgeDocuments.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, knowledgeDocuments.Id.Int64, knowledgeDocuments, onlyFields...)
+		return knowledgeDocuments.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, knowledgeDocuments, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *KnowledgeDocumentsModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *KnowledgeDocumentsModel) Update(ctx context.Context, builder query.SQLBuilder, knowledgeDocuments KnowledgeDocumentsN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, knowledgeDocuments.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *KnowledgeDocumentsModel) UpdateById(ctx context.Context, id int64, knowledgeDocuments KnowledgeDocumentsN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, knowledgeDocuments.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *KnowledgeDocumentsModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *KnowledgeDocumentsModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
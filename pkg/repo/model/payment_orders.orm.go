@@ -0,0 +1,859 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// PaymentOrdersN is a PaymentOrders object, all fields are nullable
+type PaymentOrdersN struct {
+	original           *paymentOrdersOriginal
+	paymentOrdersModel *PaymentOrdersModel
+
+	Id        null.Int    `json:"id"`
+	OrderNo   null.String `json:"order_no"`
+	UserId    null.Int    `json:"user_id"`
+	ProductId null.String `json:"product_id"`
+	Quota     null.Int    `json:"quota"`
+	Price     null.Int    `json:"price"`
+	Source    null.String `json:"source"`
+	Status    null.String `json:"status"`
+	PaidAt    null.Time   `json:"paid_at"`
+	EventId   null.Int    `json:"event_id"`
+	CreatedAt null.Time
+	UpdatedAt null.Time
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *PaymentOrdersN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for PaymentOrders
+func (inst *PaymentOrdersN) SetModel(paymentOrdersModel *PaymentOrdersModel) {
+	inst.paymentOrdersModel = paymentOrdersModel
+}
+
+// paymentOrdersOriginal is an object which stores original PaymentOrders from database
+type paymentOrdersOriginal struct {
+	Id        null.Int
+	OrderNo   null.String
+	UserId    null.Int
+	ProductId null.String
+	Quota     null.Int
+	Price     null.Int
+	Source    null.String
+	Status    null.String
+	PaidAt    null.Time
+	EventId   null.Int
+	CreatedAt null.Time
+	UpdatedAt null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *PaymentOrdersN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &paymentOrdersOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.OrderNo != inst.original.OrderNo {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.ProductId != inst.original.ProductId {
+			return true
+		}
+		if inst.Quota != inst.original.Quota {
+			return true
+		}
+		if inst.Price != inst.original.Price {
+			return true
+		}
+		if inst.Source != inst.original.Source {
+			return true
+		}
+		if inst.Status != inst.original.Status {
+			return true
+		}
+		if inst.PaidAt != inst.original.PaidAt {
+			return true
+		}
+		if inst.EventId != inst.original.EventId {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "order_no":
+				if inst.OrderNo != inst.original.OrderNo {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "product_id":
+				if inst.ProductId != inst.original.ProductId {
+					return true
+				}
+			case "quota":
+				if inst.Quota != inst.original.Quota {
+					return true
+				}
+			case "price":
+				if inst.Price != inst.original.Price {
+					return true
+				}
+			case "source":
+				if inst.Source != inst.original.Source {
+					return true
+				}
+			case "status":
+				if inst.Status != inst.original.Status {
+					return true
+				}
+			case "paid_at":
+				if inst.PaidAt != inst.original.PaidAt {
+					return true
+				}
+			case "event_id":
+				if inst.EventId != inst.original.EventId {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *PaymentOrdersN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &paymentOrdersOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.OrderNo != inst.original.OrderNo {
+			kv["order_no"] = inst.OrderNo
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.ProductId != inst.original.ProductId {
+			kv["product_id"] = inst.ProductId
+		}
+		if inst.Quota != inst.original.Quota {
+			kv["quota"] = inst.Quota
+		}
+		if inst.Price != inst.original.Price {
+			kv["price"] = inst.Price
+		}
+		if inst.Source != inst.original.Source {
+			kv["source"] = inst.Source
+		}
+		if inst.Status != inst.original.Status {
+			kv["status"] = inst.Status
+		}
+		if inst.PaidAt != inst.original.PaidAt {
+			kv["paid_at"] = inst.PaidAt
+		}
+		if inst.EventId != inst.original.EventId {
+			kv["event_id"] = inst.EventId
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "order_no":
+				if inst.OrderNo != inst.original.OrderNo {
+					kv["order_no"] = inst.OrderNo
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "product_id":
+				if inst.ProductId != inst.original.ProductId {
+					kv["product_id"] = inst.ProductId
+				}
+			case "quota":
+				if inst.Quota != inst.original.Quota {
+					kv["quota"] = inst.Quota
+				}
+			case "price":
+				if inst.Price != inst.original.Price {
+					kv["price"] = inst.Price
+				}
+			case "source":
+				if inst.Source != inst.original.Source {
+					kv["source"] = inst.Source
+				}
+			case "status":
+				if inst.Status != inst.original.Status {
+					kv["status"] = inst.Status
+				}
+			case "paid_at":
+				if inst.PaidAt != inst.original.PaidAt {
+					kv["paid_at"] = inst.PaidAt
+				}
+			case "event_id":
+				if inst.EventId != inst.original.EventId {
+					kv["event_id"] = inst.EventId
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *PaymentOrdersN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.paymentOrdersModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.paymentOrdersModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a payment_orders
+func (inst *PaymentOrdersN) Delete(ctx context.Context) error {
+	if inst.paymentOrdersModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.paymentOrdersModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *PaymentOrdersN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type paymentOrdersScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var paymentOrdersGlobalScopes = make([]paymentOrdersScope, 0)
+var paymentOrdersLocalScopes = make([]paymentOrdersScope, 0)
+
+// AddGlobalScopeForPaymentOrders assign a global scope to a model
+func AddGlobalScopeForPaymentOrders(name string, apply func(builder query.Condition)) {
+	paymentOrdersGlobalScopes = append(paymentOrdersGlobalScopes, paymentOrdersScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForPaymentOrders assign a local scope to a model
+func AddLocalScopeForPaymentOrders(name string, apply func(builder query.Condition)) {
+	paymentOrdersLocalScopes = append(paymentOrdersLocalScopes, paymentOrdersScope{name: name, apply: apply})
+}
+
+func (m *PaymentOrdersModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range paymentOrdersGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range paymentOrdersLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *PaymentOrdersModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *PaymentOrdersModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type PaymentOrders struct {
+	Id        int64     `json:"id"`
+	OrderNo   string    `json:"order_no"`
+	UserId    int64     `json:"user_id"`
+	ProductId string    `json:"product_id"`
+	Quota     int64     `json:"quota"`
+	Price     int64     `json:"price"`
+	Source    string    `json:"source"`
+	Status    string    `json:"status"`
+	PaidAt    time.Time `json:"paid_at"`
+	EventId   int64     `json:"event_id"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (w PaymentOrders) ToPaymentOrdersN(allows ...string) PaymentOrdersN {
+	if len(allows) == 0 {
+		return PaymentOrdersN{
+
+			Id:        null.IntFrom(int64(w.Id)),
+			OrderNo:   null.StringFrom(w.OrderNo),
+			UserId:    null.IntFrom(int64(w.UserId)),
+			ProductId: null.StringFrom(w.ProductId),
+			Quota:     null.IntFrom(int64(w.Quota)),
+			Price:     null.IntFrom(int64(w.Price)),
+			Source:    null.StringFrom(w.Source),
+			Status:    null.StringFrom(w.Status),
+			PaidAt:    null.TimeFrom(w.PaidAt),
+			EventId:   null.IntFrom(int64(w.EventId)),
+			CreatedAt: null.TimeFrom(w.CreatedAt),
+			UpdatedAt: null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := PaymentOrdersN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "order_no":
+			res.OrderNo = null.StringFrom(w.OrderNo)
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "product_id":
+			res.ProductId = null.StringFrom(w.ProductId)
+		case "quota":
+			res.Quota = null.IntFrom(int64(w.Quota))
+		case "price":
+			res.Price = null.IntFrom(int64(w.Price))
+		case "source":
+			res.Source = null.StringFrom(w.Source)
+		case "status":
+			res.Status = null.StringFrom(w.Status)
+		case "paid_at":
+			res.PaidAt = null.TimeFrom(w.PaidAt)
+		case "event_id":
+			res.EventId = null.IntFrom(int64(w.EventId))
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w PaymentOrders) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *PaymentOrdersN) ToPaymentOrders() PaymentOrders {
+	return PaymentOrders{
+
+		Id:        w.Id.Int64,
+		OrderNo:   w.OrderNo.String,
+		UserId:    w.UserId.Int64,
+		ProductId: w.ProductId.String,
+		Quota:     w.Quota.Int64,
+		Price:     w.Price.Int64,
+		Source:    w.Source.String,
+		Status:    w.Status.String,
+		PaidAt:    w.PaidAt.Time,
+		EventId:   w.EventId.Int64,
+		CreatedAt: w.CreatedAt.Time,
+		UpdatedAt: w.UpdatedAt.Time,
+	}
+}
+
+// PaymentOrdersModel is a model which encapsulates the operations of the object
+type PaymentOrdersModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var paymentOrdersTableName = "payment_orders"
+
+// PaymentOrdersTable return table name for PaymentOrders
+func PaymentOrdersTable() string {
+	return paymentOrdersTableName
+}
+
+const (
+	FieldPaymentOrdersId        = "id"
+	FieldPaymentOrdersOrderNo   = "order_no"
+	FieldPaymentOrdersUserId    = "user_id"
+	FieldPaymentOrdersProductId = "product_id"
+	FieldPaymentOrdersQuota     = "quota"
+	FieldPaymentOrdersPrice     = "price"
+	FieldPaymentOrdersSource    = "source"
+	FieldPaymentOrdersStatus    = "status"
+	FieldPaymentOrdersPaidAt    = "paid_at"
+	FieldPaymentOrdersEventId   = "event_id"
+	FieldPaymentOrdersCreatedAt = "created_at"
+	FieldPaymentOrdersUpdatedAt = "updated_at"
+)
+
+// PaymentOrdersFields return all fields in PaymentOrders model
+func PaymentOrdersFields() []string {
+	return []string{
+		"id",
+		"order_no",
+		"user_id",
+		"product_id",
+		"quota",
+		"price",
+		"source",
+		"status",
+		"paid_at",
+		"event_id",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetPaymentOrdersTable(tableName string) {
+	paymentOrdersTableName = tableName
+}
+
+// NewPaymentOrdersModel create a PaymentOrdersModel
+func NewPaymentOrdersModel(db query.Database) *PaymentOrdersModel {
+	return &PaymentOrdersModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           paymentOrdersTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *PaymentOrdersModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *PaymentOrdersModel) clone() *PaymentOrdersModel {
+	return &PaymentOrdersModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *PaymentOrdersModel) WithoutGlobalScopes(names ...string) *PaymentOrdersModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *PaymentOrdersModel) WithLocalScopes(names ...string) *PaymentOrdersModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *PaymentOrdersModel) Condition(builder query.SQLBuilder) *PaymentOrdersModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *PaymentOrdersModel) Find(ctx context.Context, id int64) (*PaymentOrdersN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *PaymentOrdersModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *PaymentOrdersModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *PaymentOrdersModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]PaymentOrdersN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *PaymentOrdersModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]PaymentOrdersN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"order_no",
+			"user_id",
+			"product_id",
+			"quota",
+			"price",
+			"source",
+			"status",
+			"paid_at",
+			"event_id",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "order_no":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "product_id":
+			selectFields = append(selectFields, f)
+		case "quota":
+			selectFields = append(selectFields, f)
+		case "price":
+			selectFields = append(selectFields, f)
+		case "source":
+			selectFields = append(selectFields, f)
+		case "status":
+			selectFields = append(selectFields, f)
+		case "paid_at":
+			selectFields = append(selectFields, f)
+		case "event_id":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*PaymentOrdersN, []interface{}) {
+		var paymentOrdersVar PaymentOrdersN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &paymentOrdersVar.Id)
+			case "order_no":
+				scanFields = append(scanFields, &paymentOrdersVar.OrderNo)
+			case "user_id":
+				scanFields = append(scanFields, &paymentOrdersVar.UserId)
+			case "product_id":
+				scanFields = append(scanFields, &paymentOrdersVar.ProductId)
+			case "quota":
+				scanFields = append(scanFields, &paymentOrdersVar.Quota)
+			case "price":
+				scanFields = append(scanFields, &paymentOrdersVar.Price)
+			case "source":
+				scanFields = append(scanFields, &paymentOrdersVar.Source)
+			case "status":
+				scanFields = append(scanFields, &paymentOrdersVar.Status)
+			case "paid_at":
+				scanFields = append(scanFields, &paymentOrdersVar.PaidAt)
+			case "event_id":
+				scanFields = append(scanFields, &paymentOrdersVar.EventId)
+			case "created_at":
+				scanFields = append(scanFields, &paymentOrdersVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &paymentOrdersVar.UpdatedAt)
+			}
+		}
+
+		return &paymentOrdersVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	paymentOrderss := make([]PaymentOrdersN, 0)
+	for rows.Next() {
+		paymentOrdersReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		paymentOrdersReal.original = &paymentOrdersOriginal{}
+		_ = query.Copy(paymentOrdersReal, paymentOrdersReal.original)
+
+		paymentOrdersReal.SetModel(m)
+		paymentOrderss = append(paymentOrderss, *paymentOrdersReal)
+	}
+
+	return paymentOrderss, nil
+}
+
+// First return first result for given query
+func (m *PaymentOrdersModel) First(ctx context.Context, builders ...query.SQLBuilder) (*PaymentOrdersN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new payment_orders to database
+func (m *PaymentOrdersModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all payment_orderss to database
+func (m *PaymentOrdersModel) SaveAll(ctx context.Context, paymentOrderss []PaymentOrdersN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, paymentOrders := range paymentOrderss {
+		id, err := m.Save(ctx, paymentOrders)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a payment_orders to database
+func (m *PaymentOrdersModel) Save(ctx context.Context, paymentOrders PaymentOrdersN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, paymentOrders.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new payment_orders or update it when it has a id > 0
+func (m *PaymentOrdersModel) SaveOrUpdate(ctx context.Context, paymentOrders PaymentOrdersN, onlyFields ...string) (id int64, updated bool, err error) {
+	if paymentOrders.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, paymentOrders.Id.Int64, paymentOrders, onlyFields...)
+		return paymentOrders.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, paymentOrders, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *PaymentOrdersModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *PaymentOrdersModel) Update(ctx context.Context, builder query.SQLBuilder, paymentOrders PaymentOrdersN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, paymentOrders.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *PaymentOrdersModel) UpdateById(ctx context.Context, id int64, paymentOrders PaymentOrdersN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, paymentOrders.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *PaymentOrdersModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *PaymentOrdersModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
@@ -0,0 +1,887 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// PaymentRecordsN is a PaymentRecords object, all fields are nullable
+type PaymentRecordsN struct {
+	original            *paymentRecordsOriginal
+	paymentRecordsModel *PaymentRecordsModel
+
+	Id                    null.Int    `json:"id"`
+	OrderNo               null.String `json:"order_no"`
+	UserId                null.Int    `json:"user_id"`
+	Source                null.String `json:"source"`
+	TransactionId         null.String `json:"transaction_id"`
+	OriginalTransactionId null.String `json:"original_transaction_id"`
+	ProductId             null.String `json:"product_id"`
+	Quantity              null.Int    `json:"quantity"`
+	Environment           null.String `json:"environment"`
+	PurchasedAt           null.Time   `json:"purchased_at"`
+	Payload               null.String `json:"payload"`
+	CreatedAt             null.Time
+	UpdatedAt             null.Time
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *PaymentRecordsN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for PaymentRecords
+func (inst *PaymentRecordsN) SetModel(paymentRecordsModel *PaymentRecordsModel) {
+	inst.paymentRecordsModel = paymentRecordsModel
+}
+
+// paymentRecordsOriginal is an object which stores original PaymentRecords from database
+type paymentRecordsOriginal struct {
+	Id                    null.Int
+	OrderNo               null.String
+	UserId                null.Int
+	Source                null.String
+	TransactionId         null.String
+	OriginalTransactionId null.String
+	ProductId             null.String
+	Quantity              null.Int
+	Environment           null.String
+	PurchasedAt           null.Time
+	Payload               null.String
+	CreatedAt             null.Time
+	UpdatedAt             null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *PaymentRecordsN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &paymentRecordsOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.OrderNo != inst.original.OrderNo {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.Source != inst.original.Source {
+			return true
+		}
+		if inst.TransactionId != inst.original.TransactionId {
+			return true
+		}
+		if inst.OriginalTransactionId != inst.original.OriginalTransactionId {
+			return true
+		}
+		if inst.ProductId != inst.original.ProductId {
+			return true
+		}
+		if inst.Quantity != inst.original.Quantity {
+			return true
+		}
+		if inst.Environment != inst.original.Environment {
+			return true
+		}
+		if inst.PurchasedAt != inst.original.PurchasedAt {
+			return true
+		}
+		if inst.Payload != inst.original.Payload {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "order_no":
+				if inst.OrderNo != inst.original.OrderNo {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "source":
+				if inst.Source != inst.original.Source {
+					return true
+				}
+			case "transaction_id":
+				if inst.TransactionId != inst.original.TransactionId {
+					return true
+				}
+			case "original_transaction_id":
+				if inst.OriginalTransactionId != inst.original.OriginalTransactionId {
+					return true
+				}
+			case "product_id":
+				if inst.ProductId != inst.original.ProductId {
+					return true
+				}
+			case "quantity":
+				if inst.Quantity != inst.original.Quantity {
+					return true
+				}
+			case "environment":
+				if inst.Environment != inst.original.Environment {
+					return true
+				}
+			case "purchased_at":
+				if inst.PurchasedAt != inst.original.PurchasedAt {
+					return true
+				}
+			case "payload":
+				if inst.Payload != inst.original.Payload {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *PaymentRecordsN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &paymentRecordsOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.OrderNo != inst.original.OrderNo {
+			kv["order_no"] = inst.OrderNo
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.Source != inst.original.Source {
+			kv["source"] = inst.Source
+		}
+		if inst.TransactionId != inst.original.TransactionId {
+			kv["transaction_id"] = inst.TransactionId
+		}
+		if inst.OriginalTransactionId != inst.original.OriginalTransactionId {
+			kv["original_transaction_id"] = inst.OriginalTransactionId
+		}
+		if inst.ProductId != inst.original.ProductId {
+			kv["product_id"] = inst.ProductId
+		}
+		if inst.Quantity != inst.original.Quantity {
+			kv["quantity"] = inst.Quantity
+		}
+		if inst.Environment != inst.original.Environment {
+			kv["environment"] = inst.Environment
+		}
+		if inst.PurchasedAt != inst.original.PurchasedAt {
+			kv["purchased_at"] = inst.PurchasedAt
+		}
+		if inst.Payload != inst.original.Payload {
+			kv["payload"] = inst.Payload
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "order_no":
+				if inst.OrderNo != inst.original.OrderNo {
+					kv["order_no"] = inst.OrderNo
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "source":
+				if inst.Source != inst.original.Source {
+					kv["source"] = inst.Source
+				}
+			case "transaction_id":
+				if inst.TransactionId != inst.original.TransactionId {
+					kv["transaction_id"] = inst.TransactionId
+				}
+			case "original_transaction_id":
+				if inst.OriginalTransactionId != inst.original.OriginalTransactionId {
+					kv["original_transaction_id"] = inst.OriginalTransactionId
+				}
+			case "product_id":
+				if inst.ProductId != inst.original.ProductId {
+					kv["product_id"] = inst.ProductId
+				}
+			case "quantity":
+				if inst.Quantity != inst.original.Quantity {
+					kv["quantity"] = inst.Quantity
+				}
+			case "environment":
+				if inst.Environment != inst.original.Environment {
+					kv["environment"] = inst.Environment
+				}
+			case "purchased_at":
+				if inst.PurchasedAt != inst.original.PurchasedAt {
+					kv["purchased_at"] = inst.PurchasedAt
+				}
+			case "payload":
+				if inst.Payload != inst.original.Payload {
+					kv["payload"] = inst.Payload
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *PaymentRecordsN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.paymentRecordsModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.paymentRecordsModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a payment_records
+func (inst *PaymentRecordsN) Delete(ctx context.Context) error {
+	if inst.paymentRecordsModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.paymentRecordsModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *PaymentRecordsN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type paymentRecordsScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var paymentRecordsGlobalScopes = make([]paymentRecordsScope, 0)
+var paymentRecordsLocalScopes = make([]paymentRecordsScope, 0)
+
+// AddGlobalScopeForPaymentRecords assign a global scope to a model
+func AddGlobalScopeForPaymentRecords(name string, apply func(builder query.Condition)) {
+	paymentRecordsGlobalScopes = append(paymentRecordsGlobalScopes, paymentRecordsScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForPaymentRecords assign a local scope to a model
+func AddLocalScopeForPaymentRecords(name string, apply func(builder query.Condition)) {
+	paymentRecordsLocalScopes = append(paymentRecordsLocalScopes, paymentRecordsScope{name: name, apply: apply})
+}
+
+func (m *PaymentRecordsModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range paymentRecordsGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range paymentRecordsLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *PaymentRecordsModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *PaymentRecordsModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type PaymentRecords struct {
+	Id                    int64     `json:"id"`
+	OrderNo               string    `json:"order_no"`
+	UserId                int64     `json:"user_id"`
+	Source                string    `json:"source"`
+	TransactionId         string    `json:"transaction_id"`
+	OriginalTransactionId string    `json:"original_transaction_id"`
+	ProductId             string    `json:"product_id"`
+	Quantity              int64     `json:"quantity"`
+	Environment           string    `json:"environment"`
+	PurchasedAt           time.Time `json:"purchased_at"`
+	Payload               string    `json:"payload"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+func (w PaymentRecords) ToPaymentRecordsN(allows ...string) PaymentRecordsN {
+	if len(allows) == 0 {
+		return PaymentRecordsN{
+
+			Id:                    null.IntFrom(int64(w.Id)),
+			OrderNo:               null.StringFrom(w.OrderNo),
+			UserId:                null.IntFrom(int64(w.UserId)),
+			Source:                null.StringFrom(w.Source),
+			TransactionId:         null.StringFrom(w.TransactionId),
+			OriginalTransactionId: null.StringFrom(w.OriginalTransactionId),
+			ProductId:             null.StringFrom(w.ProductId),
+			Quantity:              null.IntFrom(int64(w.Quantity)),
+			Environment:           null.StringFrom(w.Environment),
+			PurchasedAt:           null.TimeFrom(w.PurchasedAt),
+			Payload:               null.StringFrom(w.Payload),
+			CreatedAt:             null.TimeFrom(w.CreatedAt),
+			UpdatedAt:             null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := PaymentRecordsN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "order_no":
+			res.OrderNo = null.StringFrom(w.OrderNo)
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "source":
+			res.Source = null.StringFrom(w.Source)
+		case "transaction_id":
+			res.TransactionId = null.StringFrom(w.TransactionId)
+		case "original_transaction_id":
+			res.OriginalTransactionId = null.StringFrom(w.OriginalTransactionId)
+		case "product_id":
+			res.ProductId = null.StringFrom(w.ProductId)
+		case "quantity":
+			res.Quantity = null.IntFrom(int64(w.Quantity))
+		case "environment":
+			res.Environment = null.StringFrom(w.Environment)
+		case "purchased_at":
+			res.PurchasedAt = null.TimeFrom(w.PurchasedAt)
+		case "payload":
+			res.Payload = null.StringFrom(w.Payload)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w PaymentRecords) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *PaymentRecordsN) ToPaymentRecords() PaymentRecords {
+	return PaymentRecords{
+
+		Id:                    w.Id.Int64,
+		OrderNo:               w.OrderNo.String,
+		UserId:                w.UserId.Int64,
+		Source:                w.Source.String,
+		TransactionId:         w.TransactionId.String,
+		OriginalTransactionId: w.OriginalTransactionId.String,
+		ProductId:             w.ProductId.String,
+		Quantity:              w.Quantity.Int64,
+		Environment:           w.Environment.String,
+		PurchasedAt:           w.PurchasedAt.Time,
+		Payload:               w.Payload.String,
+		CreatedAt:             w.CreatedAt.Time,
+		UpdatedAt:             w.UpdatedAt.Time,
+	}
+}
+
+// PaymentRecordsModel is a model which encapsulates the operations of the object
+type PaymentRecordsModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var paymentRecordsTableName = "payment_records"
+
+// PaymentRecordsTable return table name for PaymentRecords
+func PaymentRecordsTable() string {
+	return paymentRecordsTableName
+}
+
+const (
+	FieldPaymentRecordsId                    = "id"
+	FieldPaymentRecordsOrderNo               = "order_no"
+	FieldPaymentRecordsUserId                = "user_id"
+	FieldPaymentRecordsSource                = "source"
+	FieldPaymentRecordsTransactionId         = "transaction_id"
+	FieldPaymentRecordsOriginalTransactionId = "original_transaction_id"
+	FieldPaymentRecordsProductId             = "product_id"
+	FieldPaymentRecordsQuantity              = "quantity"
+	FieldPaymentRecordsEnvironment           = "environment"
+	FieldPaymentRecordsPurchasedAt           = "purchased_at"
+	FieldPaymentRecordsPayload               = "payload"
+	FieldPaymentRecordsCreatedAt             = "created_at"
+	FieldPaymentRecordsUpdatedAt             = "updated_at"
+)
+
+// PaymentRecordsFields return all fields in PaymentRecords model
+func PaymentRecordsFields() []string {
+	return []string{
+		"id",
+		"order_no",
+		"user_id",
+		"source",
+		"transaction_id",
+		"original_transaction_id",
+		"product_id",
+		"quantity",
+		"environment",
+		"purchased_at",
+		"payload",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetPaymentRecordsTable(tableName string) {
+	paymentRecordsTableName = tableName
+}
+
+// NewPaymentRecordsModel create a PaymentRecordsModel
+func NewPaymentRecordsModel(db query.Database) *PaymentRecordsModel {
+	return &PaymentRecordsModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           paymentRecordsTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *PaymentRecordsModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *PaymentRecordsModel) clone() *PaymentRecordsModel {
+	return &PaymentRecordsModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *PaymentRecordsModel) WithoutGlobalScopes(names ...string) *PaymentRecordsModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *PaymentRecordsModel) WithLocalScopes(names ...string) *PaymentRecordsModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *PaymentRecordsModel) Condition(builder query.SQLBuilder) *PaymentRecordsModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *PaymentRecordsModel) Find(ctx context.Context, id int64) (*PaymentRecordsN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *PaymentRecordsModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *PaymentRecordsModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *PaymentRecordsModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]PaymentRecordsN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *PaymentRecordsModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]PaymentRecordsN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"order_no",
+			"user_id",
+			"source",
+			"transaction_id",
+			"original_transaction_id",
+			"product_id",
+			"quantity",
+			"environment",
+			"purchased_at",
+			"payload",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "order_no":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "source":
+			selectFields = append(selectFields, f)
+		case "transaction_id":
+			selectFields = append(selectFields, f)
+		case "original_transaction_id":
+			selectFields = append(selectFields, f)
+		case "product_id":
+			selectFields = append(selectFields, f)
+		case "quantity":
+			selectFields = append(selectFields, f)
+		case "environment":
+			selectFields = append(selectFields, f)
+		case "purchased_at":
+			selectFields = append(selectFields, f)
+		case "payload":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*PaymentRecordsN, []interface{}) {
+		var paymentRecordsVar PaymentRecordsN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &paymentRecordsVar.Id)
+			case "order_no":
+				scanFields = append(scanFields, &paymentRecordsVar.OrderNo)
+			case "user_id":
+				scanFields = append(scanFields, &paymentRecordsVar.UserId)
+			case "source":
+				scanFields = append(scanFields, &paymentRecordsVar.Source)
+			case "transaction_id":
+				scanFields = append(scanFields, &paymentRecordsVar.TransactionId)
+			case "original_transaction_id":
+				scanFields = append(scanFields, &paymentRecordsVar.OriginalTransactionId)
+			case "product_id":
+				scanFields = append(scanFields, &paymentRecordsVar.ProductId)
+			case "quantity":
+				scanFields = append(scanFields, &paymentRecordsVar.Quantity)
+			case "environment":
+				scanFields = append(scanFields, &paymentRecordsVar.Environment)
+			case "purchased_at":
+				scanFields = append(scanFields, &paymentRecordsVar.PurchasedAt)
+			case "payload":
+				scanFields = append(scanFields, &paymentRecordsVar.Payload)
+			case "created_at":
+				scanFields = append(scanFields, &paymentRecordsVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &paymentRecordsVar.UpdatedAt)
+			}
+		}
+
+		return &paymentRecordsVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	paymentRecordss := make([]PaymentRecordsN, 0)
+	for rows.Next() {
+		paymentRecordsReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		paymentRecordsReal.original = &paymentRecordsOriginal{}
+		_ = query.Copy(paymentRecordsReal, paymentRecordsReal.original)
+
+		paymentRecordsReal.SetModel(m)
+		paymentRecordss = append(paymentRecordss, *paymentRecordsReal)
+	}
+
+	return paymentRecordss, nil
+}
+
+// First return first result for given query
+func (m *PaymentRecordsModel) First(ctx context.Context, builders ...query.SQLBuilder) (*PaymentRecordsN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new payment_records to database
+func (m *PaymentRecordsModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all payment_recordss to database
+func (m *PaymentRecordsModel) SaveAll(ctx context.Context, paymentRecordss []PaymentRecordsN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, paymentRecords := range paymentRecordss {
+		id, err := m.Save(ctx, paymentRecords)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a payment_records to database
+func (m *PaymentRecordsModel) Save(ctx context.Context, paymentRecords PaymentRecordsN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, paymentRecords.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new payment_records or update it when it has a id > 0
+func (m *PaymentRecordsModel) SaveOrUpdate(ctx context.Context, paymentRecords PaymentRecordsN, onlyFields ...string) (id int64, updated bool, err error) {
+	if paymentRecords.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, paymentRecords.Id.Int64, paymentRecords, onlyFields...)
+		return paymentRecords.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, paymentRecords, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *PaymentRecordsModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *PaymentRecordsModel) Update(ctx context.Context, builder query.SQLBuilder, paymentRecords PaymentRecordsN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, paymentRecords.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *PaymentRecordsModel) UpdateById(ctx context.Context, id int64, paymentRecords PaymentRecordsN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, paymentRecords.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *PaymentRecordsModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *PaymentRecordsModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
@@ -0,0 +1,943 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// RobotsN is a Robots object, all fields are nullable
+type RobotsN struct {
+	original    *robotsOriginal
+	robotsModel *RobotsModel
+
+	Id             null.Int    `json:"id"`
+	RobotId        null.String `json:"robot_id"`
+	UserId         null.Int    `json:"user_id"`
+	Name           null.String `json:"name"`
+	Type           null.Int    `json:"type"`
+	Description    null.String `json:"description"`
+	Privilege      null.Int    `json:"privilege"`
+	Model          null.String `json:"model"`
+	Prompt         null.String `json:"prompt"`
+	WelcomeMessage null.String `json:"welcome_message"`
+	ServerUrl      null.String `json:"server_url"`
+	ServerToken    null.String `json:"-"`
+	RobotMeta      null.String `json:"robot_meta"`
+	CreatedAt      null.Time
+	UpdatedAt      null.Time
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *RobotsN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for Robots
+func (inst *RobotsN) SetModel(robotsModel *RobotsModel) {
+	inst.robotsModel = robotsModel
+}
+
+// robotsOriginal is an object which stores original Robots from database
+type robotsOriginal struct {
+	Id             null.Int
+	RobotId        null.String
+	UserId         null.Int
+	Name           null.String
+	Type           null.Int
+	Description    null.String
+	Privilege      null.Int
+	Model          null.String
+	Prompt         null.String
+	WelcomeMessage null.String
+	ServerUrl      null.String
+	ServerToken    null.String
+	RobotMeta      null.String
+	CreatedAt      null.Time
+	UpdatedAt      null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *RobotsN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &robotsOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.RobotId != inst.original.RobotId {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.Name != inst.original.Name {
+			return true
+		}
+		if inst.Type != inst.original.Type {
+			return true
+		}
+		if inst.Description != inst.original.Description {
+			return true
+		}
+		if inst.Privilege != inst.original.Privilege {
+			return true
+		}
+		if inst.Model != inst.original.Model {
+			return true
+		}
+		if inst.Prompt != inst.original.Prompt {
+			return true
+		}
+		if inst.WelcomeMessage != inst.original.WelcomeMessage {
+			return true
+		}
+		if inst.ServerUrl != inst.original.ServerUrl {
+			return true
+		}
+		if inst.ServerToken != inst.original.ServerToken {
+			return true
+		}
+		if inst.RobotMeta != inst.original.RobotMeta {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "robot_id":
+				if inst.RobotId != inst.original.RobotId {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					return true
+				}
+			case "type":
+				if inst.Type != inst.original.Type {
+					return true
+				}
+			case "description":
+				if inst.Description != inst.original.Description {
+					return true
+				}
+			case "privilege":
+				if inst.Privilege != inst.original.Privilege {
+					return true
+				}
+			case "model":
+				if inst.Model != inst.original.Model {
+					return true
+				}
+			case "prompt":
+				if inst.Prompt != inst.original.Prompt {
+					return true
+				}
+			case "welcome_message":
+				if inst.WelcomeMessage != inst.original.WelcomeMessage {
+					return true
+				}
+			case "server_url":
+				if inst.ServerUrl != inst.original.ServerUrl {
+					return true
+				}
+			case "server_token":
+				if inst.ServerToken != inst.original.ServerToken {
+					return true
+				}
+			case "robot_meta":
+				if inst.RobotMeta != inst.original.RobotMeta {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *RobotsN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &robotsOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.RobotId != inst.original.RobotId {
+			kv["robot_id"] = inst.RobotId
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.Name != inst.original.Name {
+			kv["name"] = inst.Name
+		}
+		if inst.Type != inst.original.Type {
+			kv["type"] = inst.Type
+		}
+		if inst.Description != inst.original.Description {
+			kv["description"] = inst.Description
+		}
+		if inst.Privilege != inst.original.Privilege {
+			kv["privilege"] = inst.Privilege
+		}
+		if inst.Model != inst.original.Model {
+			kv["model"] = inst.Model
+		}
+		if inst.Prompt != inst.original.Prompt {
+			kv["prompt"] = inst.Prompt
+		}
+		if inst.WelcomeMessage != inst.original.WelcomeMessage {
+			kv["welcome_message"] = inst.WelcomeMessage
+		}
+		if inst.ServerUrl != inst.original.ServerUrl {
+			kv["server_url"] = inst.ServerUrl
+		}
+		if inst.ServerToken != inst.original.ServerToken {
+			kv["server_token"] = inst.ServerToken
+		}
+		if inst.RobotMeta != inst.original.RobotMeta {
+			kv["robot_meta"] = inst.RobotMeta
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "robot_id":
+				if inst.RobotId != inst.original.RobotId {
+					kv["robot_id"] = inst.RobotId
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					kv["name"] = inst.Name
+				}
+			case "type":
+				if inst.Type != inst.original.Type {
+					kv["type"] = inst.Type
+				}
+			case "description":
+				if inst.Description != inst.original.Description {
+					kv["description"] = inst.Description
+				}
+			case "privilege":
+				if inst.Privilege != inst.original.Privilege {
+					kv["privilege"] = inst.Privilege
+				}
+			case "model":
+				if inst.Model != inst.original.Model {
+					kv["model"] = inst.Model
+				}
+			case "prompt":
+				if inst.Prompt != inst.original.Prompt {
+					kv["prompt"] = inst.Prompt
+				}
+			case "welcome_message":
+				if inst.WelcomeMessage != inst.original.WelcomeMessage {
+					kv["welcome_message"] = inst.WelcomeMessage
+				}
+			case "server_url":
+				if inst.ServerUrl != inst.original.ServerUrl {
+					kv["server_url"] = inst.ServerUrl
+				}
+			case "server_token":
+				if inst.ServerToken != inst.original.ServerToken {
+					kv["server_token"] = inst.ServerToken
+				}
+			case "robot_meta":
+				if inst.RobotMeta != inst.original.RobotMeta {
+					kv["robot_meta"] = inst.RobotMeta
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *RobotsN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.robotsModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.robotsModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a robots
+func (inst *RobotsN) Delete(ctx context.Context) error {
+	if inst.robotsModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.robotsModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *RobotsN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type robotsScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var robotsGlobalScopes = make([]robotsScope, 0)
+var robotsLocalScopes = make([]robotsScope, 0)
+
+// AddGlobalScopeForRobots assign a global scope to a model
+func AddGlobalScopeForRobots(name string, apply func(builder query.Condition)) {
+	robotsGlobalScopes = append(robotsGlobalScopes, robotsScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForRobots assign a local scope to a model
+func AddLocalScopeForRobots(name string, apply func(builder query.Condition)) {
+	robotsLocalScopes = append(robotsLocalScopes, robotsScope{name: name, apply: apply})
+}
+
+func (m *RobotsModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range robotsGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range robotsLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *RobotsModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *RobotsModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type Robots struct {
+	Id             int64  `json:"id"`
+	RobotId        string `json:"robot_id"`
+	UserId         int64  `json:"user_id"`
+	Name           string `json:"name"`
+	Type           int64  `json:"type"`
+	Description    string `json:"description"`
+	Privilege      int64  `json:"privilege"`
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	WelcomeMessage string `json:"welcome_message"`
+	ServerUrl      string `json:"server_url"`
+	ServerToken    string `json:"-"`
+	RobotMeta      string `json:"robot_meta"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (w Robots) ToRobotsN(allows ...string) RobotsN {
+	if len(allows) == 0 {
+		return RobotsN{
+
+			Id:             null.IntFrom(int64(w.Id)),
+			RobotId:        null.StringFrom(w.RobotId),
+			UserId:         null.IntFrom(int64(w.UserId)),
+			Name:           null.StringFrom(w.Name),
+			Type:           null.IntFrom(int64(w.Type)),
+			Description:    null.StringFrom(w.Description),
+			Privilege:      null.IntFrom(int64(w.Privilege)),
+			Model:          null.StringFrom(w.Model),
+			Prompt:         null.StringFrom(w.Prompt),
+			WelcomeMessage: null.StringFrom(w.WelcomeMessage),
+			ServerUrl:      null.StringFrom(w.ServerUrl),
+			ServerToken:    null.StringFrom(w.ServerToken),
+			RobotMeta:      null.StringFrom(w.RobotMeta),
+			CreatedAt:      null.TimeFrom(w.CreatedAt),
+			UpdatedAt:      null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := RobotsN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "robot_id":
+			res.RobotId = null.StringFrom(w.RobotId)
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "name":
+			res.Name = null.StringFrom(w.Name)
+		case "type":
+			res.Type = null.IntFrom(int64(w.Type))
+		case "description":
+			res.Description = null.StringFrom(w.Description)
+		case "privilege":
+			res.Privilege = null.IntFrom(int64(w.Privilege))
+		case "model":
+			res.Model = null.StringFrom(w.Model)
+		case "prompt":
+			res.Prompt = null.StringFrom(w.Prompt)
+		case "welcome_message":
+			res.WelcomeMessage = null.StringFrom(w.WelcomeMessage)
+		case "server_url":
+			res.ServerUrl = null.StringFrom(w.ServerUrl)
+		case "server_token":
+			res.ServerToken = null.StringFrom(w.ServerToken)
+		case "robot_meta":
+			res.RobotMeta = null.StringFrom(w.RobotMeta)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w Robots) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *RobotsN) ToRobots() Robots {
+	return Robots{
+
+		Id:             w.Id.Int64,
+		RobotId:        w.RobotId.String,
+		UserId:         w.UserId.Int64,
+		Name:           w.Name.String,
+		Type:           w.Type.Int64,
+		Description:    w.Description.String,
+		Privilege:      w.Privilege.Int64,
+		Model:          w.Model.String,
+		Prompt:         w.Prompt.String,
+		WelcomeMessage: w.WelcomeMessage.String,
+		ServerUrl:      w.ServerUrl.String,
+		ServerToken:    w.ServerToken.String,
+		RobotMeta:      w.RobotMeta.String,
+		CreatedAt:      w.CreatedAt.Time,
+		UpdatedAt:      w.UpdatedAt.Time,
+	}
+}
+
+// RobotsModel is a model which encapsulates the operations of the object
+type RobotsModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var robotsTableName = "robots"
+
+// RobotsTable return table name for Robots
+func RobotsTable() string {
+	return robotsTableName
+}
+
+const (
+	FieldRobotsId             = "id"
+	FieldRobotsRobotId        = "robot_id"
+	FieldRobotsUserId         = "user_id"
+	FieldRobotsName           = "name"
+	FieldRobotsType           = "type"
+	FieldRobotsDescription    = "description"
+	FieldRobotsPrivilege      = "privilege"
+	FieldRobotsModel          = "model"
+	FieldRobotsPrompt         = "prompt"
+	FieldRobotsWelcomeMessage = "welcome_message"
+	FieldRobotsServerUrl      = "server_url"
+	FieldRobotsServerToken    = "server_token"
+	FieldRobotsRobotMeta      = "robot_meta"
+	FieldRobotsCreatedAt      = "created_at"
+	FieldRobotsUpdatedAt      = "updated_at"
+)
+
+// RobotsFields return all fields in Robots model
+func RobotsFields() []string {
+	return []string{
+		"id",
+		"robot_id",
+		"user_id",
+		"name",
+		"type",
+		"description",
+		"privilege",
+		"model",
+		"prompt",
+		"welcome_message",
+		"server_url",
+		"server_token",
+		"robot_meta",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetRobotsTable(tableName string) {
+	robotsTableName = tableName
+}
+
+// NewRobotsModel create a RobotsModel
+func NewRobotsModel(db query.Database) *RobotsModel {
+	return &RobotsModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           robotsTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *RobotsModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *RobotsModel) clone() *RobotsModel {
+	return &RobotsModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *RobotsModel) WithoutGlobalScopes(names ...string) *RobotsModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *RobotsModel) WithLocalScopes(names ...string) *RobotsModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *RobotsModel) Condition(builder query.SQLBuilder) *RobotsModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *RobotsModel) Find(ctx context.Context, id int64) (*RobotsN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *RobotsModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *RobotsModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *RobotsModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]RobotsN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *RobotsModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]RobotsN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"robot_id",
+			"user_id",
+			"name",
+			"type",
+			"description",
+			"privilege",
+			"model",
+			"prompt",
+			"welcome_message",
+			"server_url",
+			"server_token",
+			"robot_meta",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "robot_id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "name":
+			selectFields = append(selectFields, f)
+		case "type":
+			selectFields = append(selectFields, f)
+		case "description":
+			selectFields = append(selectFields, f)
+		case "privilege":
+			selectFields = append(selectFields, f)
+		case "model":
+			selectFields = append(selectFields, f)
+		case "prompt":
+			selectFields = append(selectFields, f)
+		case "welcome_message":
+			selectFields = append(selectFields, f)
+		case "server_url":
+			selectFields = append(selectFields, f)
+		case "server_token":
+			selectFields = append(selectFields, f)
+		case "robot_meta":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*RobotsN, []interface{}) {
+		var robotsVar RobotsN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &robotsVar.Id)
+			case "robot_id":
+				scanFields = append(scanFields, &robotsVar.RobotId)
+			case "user_id":
+				scanFields = append(scanFields, &robotsVar.UserId)
+			case "name":
+				scanFields = append(scanFields, &robotsVar.Name)
+			case "type":
+				scanFields = append(scanFields, &robotsVar.Type)
+			case "description":
+				scanFields = append(scanFields, &robotsVar.Description)
+			case "privilege":
+				scanFields = append(scanFields, &robotsVar.Privilege)
+			case "model":
+				scanFields = append(scanFields, &robotsVar.Model)
+			case "prompt":
+				scanFields = append(scanFields, &robotsVar.Prompt)
+			case "welcome_message":
+				scanFields = append(scanFields, &robotsVar.WelcomeMessage)
+			case "server_url":
+				scanFields = append(scanFields, &robotsVar.ServerUrl)
+			case "server_token":
+				scanFields = append(scanFields, &robotsVar.ServerToken)
+			case "robot_meta":
+				scanFields = append(scanFields, &robotsVar.RobotMeta)
+			case "created_at":
+				scanFields = append(scanFields, &robotsVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &robotsVar.UpdatedAt)
+			}
+		}
+
+		return &robotsVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	robotss := make([]RobotsN, 0)
+	for rows.Next() {
+		robotsReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		robotsReal.original = &robotsOriginal{}
+		_ = query.Copy(robotsReal, robotsReal.original)
+
+		robotsReal.SetModel(m)
+		robotss = append(robotss, *robotsReal)
+	}
+
+	return robotss, nil
+}
+
+// First return first result for given query
+func (m *RobotsModel) First(ctx context.Context, builders ...query.SQLBuilder) (*RobotsN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new robots to database
+func (m *RobotsModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all robotss to database
+func (m *RobotsModel) SaveAll(ctx context.Context, robotss []RobotsN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, robots := range robotss {
+		id, err := m.Save(ctx, robots)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a robots to database
+func (m *RobotsModel) Save(ctx context.Context, robots RobotsN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, robots.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new robots or update it when it has a id > 0
+func (m *RobotsModel) SaveOrUpdate(ctx context.Context, robots RobotsN, onlyFields ...string) (id int64, updated bool, err error) {
+	if robots.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, robots.Id.Int64, robots, onlyFields...)
+		return robots.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, robots, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *RobotsModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *RobotsModel) Update(ctx context.Context, builder query.SQLBuilder, robots RobotsN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, robots.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *RobotsModel) UpdateById(ctx context.Context, id int64, robots RobotsN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, robots.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *RobotsModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *RobotsModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
@@ -0,0 +1,803 @@
+package model
+
+// !!! DO NOT EDIT THIS FILE
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iancoleman/strcase"
+	"github.com/mylxsw/eloquent/query"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+func init() {
+
+}
+
+// UserApiKeysN is a UserApiKeys object, all fields are nullable
+type UserApiKeysN struct {
+	original         *userApiKeysOriginal
+	userApiKeysModel *UserApiKeysModel
+
+	Id         null.Int    `json:"id"`
+	UserId     null.Int    `json:"user_id"`
+	Name       null.String `json:"name"`
+	KeyHash    null.String `json:"-"`
+	KeyPreview null.String `json:"key_preview"`
+	Scopes     null.String `json:"scopes"`
+	LastUsedAt null.Time   `json:"last_used_at"`
+	ExpiredAt  null.Time   `json:"expired_at"`
+	CreatedAt  null.Time
+	UpdatedAt  null.Time
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (inst *UserApiKeysN) As(dst interface{}) error {
+	return query.Copy(inst, dst)
+}
+
+// SetModel set model for UserApiKeys
+func (inst *UserApiKeysN) SetModel(userApiKeysModel *UserApiKeysModel) {
+	inst.userApiKeysModel = userApiKeysModel
+}
+
+// userApiKeysOriginal is an object which stores original UserApiKeys from database
+type userApiKeysOriginal struct {
+	Id         null.Int
+	UserId     null.Int
+	Name       null.String
+	KeyHash    null.String
+	KeyPreview null.String
+	Scopes     null.String
+	LastUsedAt null.Time
+	ExpiredAt  null.Time
+	CreatedAt  null.Time
+	UpdatedAt  null.Time
+}
+
+// Staled identify whether the object has been modified
+func (inst *UserApiKeysN) Staled(onlyFields ...string) bool {
+	if inst.original == nil {
+		inst.original = &userApiKeysOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			return true
+		}
+		if inst.UserId != inst.original.UserId {
+			return true
+		}
+		if inst.Name != inst.original.Name {
+			return true
+		}
+		if inst.KeyHash != inst.original.KeyHash {
+			return true
+		}
+		if inst.KeyPreview != inst.original.KeyPreview {
+			return true
+		}
+		if inst.Scopes != inst.original.Scopes {
+			return true
+		}
+		if inst.LastUsedAt != inst.original.LastUsedAt {
+			return true
+		}
+		if inst.ExpiredAt != inst.original.ExpiredAt {
+			return true
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			return true
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			return true
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					return true
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					return true
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					return true
+				}
+			case "key_hash":
+				if inst.KeyHash != inst.original.KeyHash {
+					return true
+				}
+			case "key_preview":
+				if inst.KeyPreview != inst.original.KeyPreview {
+					return true
+				}
+			case "scopes":
+				if inst.Scopes != inst.original.Scopes {
+					return true
+				}
+			case "last_used_at":
+				if inst.LastUsedAt != inst.original.LastUsedAt {
+					return true
+				}
+			case "expired_at":
+				if inst.ExpiredAt != inst.original.ExpiredAt {
+					return true
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					return true
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					return true
+				}
+			default:
+			}
+		}
+	}
+
+	return false
+}
+
+// StaledKV return all fields has been modified
+func (inst *UserApiKeysN) StaledKV(onlyFields ...string) query.KV {
+	kv := make(query.KV, 0)
+
+	if inst.original == nil {
+		inst.original = &userApiKeysOriginal{}
+	}
+
+	if len(onlyFields) == 0 {
+
+		if inst.Id != inst.original.Id {
+			kv["id"] = inst.Id
+		}
+		if inst.UserId != inst.original.UserId {
+			kv["user_id"] = inst.UserId
+		}
+		if inst.Name != inst.original.Name {
+			kv["name"] = inst.Name
+		}
+		if inst.KeyHash != inst.original.KeyHash {
+			kv["key_hash"] = inst.KeyHash
+		}
+		if inst.KeyPreview != inst.original.KeyPreview {
+			kv["key_preview"] = inst.KeyPreview
+		}
+		if inst.Scopes != inst.original.Scopes {
+			kv["scopes"] = inst.Scopes
+		}
+		if inst.LastUsedAt != inst.original.LastUsedAt {
+			kv["last_used_at"] = inst.LastUsedAt
+		}
+		if inst.ExpiredAt != inst.original.ExpiredAt {
+			kv["expired_at"] = inst.ExpiredAt
+		}
+		if inst.CreatedAt != inst.original.CreatedAt {
+			kv["created_at"] = inst.CreatedAt
+		}
+		if inst.UpdatedAt != inst.original.UpdatedAt {
+			kv["updated_at"] = inst.UpdatedAt
+		}
+	} else {
+		for _, f := range onlyFields {
+			switch strcase.ToSnake(f) {
+
+			case "id":
+				if inst.Id != inst.original.Id {
+					kv["id"] = inst.Id
+				}
+			case "user_id":
+				if inst.UserId != inst.original.UserId {
+					kv["user_id"] = inst.UserId
+				}
+			case "name":
+				if inst.Name != inst.original.Name {
+					kv["name"] = inst.Name
+				}
+			case "key_hash":
+				if inst.KeyHash != inst.original.KeyHash {
+					kv["key_hash"] = inst.KeyHash
+				}
+			case "key_preview":
+				if inst.KeyPreview != inst.original.KeyPreview {
+					kv["key_preview"] = inst.KeyPreview
+				}
+			case "scopes":
+				if inst.Scopes != inst.original.Scopes {
+					kv["scopes"] = inst.Scopes
+				}
+			case "last_used_at":
+				if inst.LastUsedAt != inst.original.LastUsedAt {
+					kv["last_used_at"] = inst.LastUsedAt
+				}
+			case "expired_at":
+				if inst.ExpiredAt != inst.original.ExpiredAt {
+					kv["expired_at"] = inst.ExpiredAt
+				}
+			case "created_at":
+				if inst.CreatedAt != inst.original.CreatedAt {
+					kv["created_at"] = inst.CreatedAt
+				}
+			case "updated_at":
+				if inst.UpdatedAt != inst.original.UpdatedAt {
+					kv["updated_at"] = inst.UpdatedAt
+				}
+			default:
+			}
+		}
+	}
+
+	return kv
+}
+
+// Save create a new model or update it
+func (inst *UserApiKeysN) Save(ctx context.Context, onlyFields ...string) error {
+	if inst.userApiKeysModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	id, _, err := inst.userApiKeysModel.SaveOrUpdate(ctx, *inst, onlyFields...)
+	if err != nil {
+		return err
+	}
+
+	inst.Id = null.IntFrom(id)
+	return nil
+}
+
+// Delete remove a user_api_keys
+func (inst *UserApiKeysN) Delete(ctx context.Context) error {
+	if inst.userApiKeysModel == nil {
+		return query.ErrModelNotSet
+	}
+
+	_, err := inst.userApiKeysModel.DeleteById(ctx, inst.Id.Int64)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// String convert instance to json string
+func (inst *UserApiKeysN) String() string {
+	rs, _ := json.Marshal(inst)
+	return string(rs)
+}
+
+type userApiKeysScope struct {
+	name  string
+	apply func(builder query.Condition)
+}
+
+var userApiKeysGlobalScopes = make([]userApiKeysScope, 0)
+var userApiKeysLocalScopes = make([]userApiKeysScope, 0)
+
+// AddGlobalScopeForUserApiKeys assign a global scope to a model
+func AddGlobalScopeForUserApiKeys(name string, apply func(builder query.Condition)) {
+	userApiKeysGlobalScopes = append(userApiKeysGlobalScopes, userApiKeysScope{name: name, apply: apply})
+}
+
+// AddLocalScopeForUserApiKeys assign a local scope to a model
+func AddLocalScopeForUserApiKeys(name string, apply func(builder query.Condition)) {
+	userApiKeysLocalScopes = append(userApiKeysLocalScopes, userApiKeysScope{name: name, apply: apply})
+}
+
+func (m *UserApiKeysModel) applyScope() query.Condition {
+	scopeCond := query.ConditionBuilder()
+	for _, g := range userApiKeysGlobalScopes {
+		if m.globalScopeEnabled(g.name) {
+			g.apply(scopeCond)
+		}
+	}
+
+	for _, s := range userApiKeysLocalScopes {
+		if m.localScopeEnabled(s.name) {
+			s.apply(scopeCond)
+		}
+	}
+
+	return scopeCond
+}
+
+func (m *UserApiKeysModel) localScopeEnabled(name string) bool {
+	for _, n := range m.includeLocalScopes {
+		if name == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *UserApiKeysModel) globalScopeEnabled(name string) bool {
+	for _, n := range m.excludeGlobalScopes {
+		if name == n {
+			return false
+		}
+	}
+
+	return true
+}
+
+type UserApiKeys struct {
+	Id         int64     `json:"id"`
+	UserId     int64     `json:"user_id"`
+	Name       string    `json:"name"`
+	KeyHash    string    `json:"-"`
+	KeyPreview string    `json:"key_preview"`
+	Scopes     string    `json:"scopes"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (w UserApiKeys) ToUserApiKeysN(allows ...string) UserApiKeysN {
+	if len(allows) == 0 {
+		return UserApiKeysN{
+
+			Id:         null.IntFrom(int64(w.Id)),
+			UserId:     null.IntFrom(int64(w.UserId)),
+			Name:       null.StringFrom(w.Name),
+			KeyHash:    null.StringFrom(w.KeyHash),
+			KeyPreview: null.StringFrom(w.KeyPreview),
+			Scopes:     null.StringFrom(w.Scopes),
+			LastUsedAt: null.TimeFrom(w.LastUsedAt),
+			ExpiredAt:  null.TimeFrom(w.ExpiredAt),
+			CreatedAt:  null.TimeFrom(w.CreatedAt),
+			UpdatedAt:  null.TimeFrom(w.UpdatedAt),
+		}
+	}
+
+	res := UserApiKeysN{}
+	for _, al := range allows {
+		switch strcase.ToSnake(al) {
+
+		case "id":
+			res.Id = null.IntFrom(int64(w.Id))
+		case "user_id":
+			res.UserId = null.IntFrom(int64(w.UserId))
+		case "name":
+			res.Name = null.StringFrom(w.Name)
+		case "key_hash":
+			res.KeyHash = null.StringFrom(w.KeyHash)
+		case "key_preview":
+			res.KeyPreview = null.StringFrom(w.KeyPreview)
+		case "scopes":
+			res.Scopes = null.StringFrom(w.Scopes)
+		case "last_used_at":
+			res.LastUsedAt = null.TimeFrom(w.LastUsedAt)
+		case "expired_at":
+			res.ExpiredAt = null.TimeFrom(w.ExpiredAt)
+		case "created_at":
+			res.CreatedAt = null.TimeFrom(w.CreatedAt)
+		case "updated_at":
+			res.UpdatedAt = null.TimeFrom(w.UpdatedAt)
+		default:
+		}
+	}
+
+	return res
+}
+
+// As convert object to other type
+// dst must be a pointer to struct
+func (w UserApiKeys) As(dst interface{}) error {
+	return query.Copy(w, dst)
+}
+
+func (w *UserApiKeysN) ToUserApiKeys() UserApiKeys {
+	return UserApiKeys{
+
+		Id:         w.Id.Int64,
+		UserId:     w.UserId.Int64,
+		Name:       w.Name.String,
+		KeyHash:    w.KeyHash.String,
+		KeyPreview: w.KeyPreview.String,
+		Scopes:     w.Scopes.String,
+		LastUsedAt: w.LastUsedAt.Time,
+		ExpiredAt:  w.ExpiredAt.Time,
+		CreatedAt:  w.CreatedAt.Time,
+		UpdatedAt:  w.UpdatedAt.Time,
+	}
+}
+
+// UserApiKeysModel is a model which encapsulates the operations of the object
+type UserApiKeysModel struct {
+	db        *query.DatabaseWrap
+	tableName string
+
+	excludeGlobalScopes []string
+	includeLocalScopes  []string
+
+	query query.SQLBuilder
+}
+
+var userApiKeysTableName = "user_api_keys"
+
+// UserApiKeysTable return table name for UserApiKeys
+func UserApiKeysTable() string {
+	return userApiKeysTableName
+}
+
+const (
+	FieldUserApiKeysId         = "id"
+	FieldUserApiKeysUserId     = "user_id"
+	FieldUserApiKeysName       = "name"
+	FieldUserApiKeysKeyHash    = "key_hash"
+	FieldUserApiKeysKeyPreview = "key_preview"
+	FieldUserApiKeysScopes     = "scopes"
+	FieldUserApiKeysLastUsedAt = "last_used_at"
+	FieldUserApiKeysExpiredAt  = "expired_at"
+	FieldUserApiKeysCreatedAt  = "created_at"
+	FieldUserApiKeysUpdatedAt  = "updated_at"
+)
+
+// UserApiKeysFields return all fields in UserApiKeys model
+func UserApiKeysFields() []string {
+	return []string{
+		"id",
+		"user_id",
+		"name",
+		"key_hash",
+		"key_preview",
+		"scopes",
+		"last_used_at",
+		"expired_at",
+		"created_at",
+		"updated_at",
+	}
+}
+
+func SetUserApiKeysTable(tableName string) {
+	userApiKeysTableName = tableName
+}
+
+// NewUserApiKeysModel create a UserApiKeysModel
+func NewUserApiKeysModel(db query.Database) *UserApiKeysModel {
+	return &UserApiKeysModel{
+		db:                  query.NewDatabaseWrap(db),
+		tableName:           userApiKeysTableName,
+		excludeGlobalScopes: make([]string, 0),
+		includeLocalScopes:  make([]string, 0),
+		query:               query.Builder(),
+	}
+}
+
+// GetDB return database instance
+func (m *UserApiKeysModel) GetDB() query.Database {
+	return m.db.GetDB()
+}
+
+func (m *UserApiKeysModel) clone() *UserApiKeysModel {
+	return &UserApiKeysModel{
+		db:                  m.db,
+		tableName:           m.tableName,
+		excludeGlobalScopes: append([]string{}, m.excludeGlobalScopes...),
+		includeLocalScopes:  append([]string{}, m.includeLocalScopes...),
+		query:               m.query,
+	}
+}
+
+// WithoutGlobalScopes remove a global scope for given query
+func (m *UserApiKeysModel) WithoutGlobalScopes(names ...string) *UserApiKeysModel {
+	mc := m.clone()
+	mc.excludeGlobalScopes = append(mc.excludeGlobalScopes, names...)
+
+	return mc
+}
+
+// WithLocalScopes add a local scope for given query
+func (m *UserApiKeysModel) WithLocalScopes(names ...string) *UserApiKeysModel {
+	mc := m.clone()
+	mc.includeLocalScopes = append(mc.includeLocalScopes, names...)
+
+	return mc
+}
+
+// Condition add query builder to model
+func (m *UserApiKeysModel) Condition(builder query.SQLBuilder) *UserApiKeysModel {
+	mm := m.clone()
+	mm.query = mm.query.Merge(builder)
+
+	return mm
+}
+
+// Find retrieve a model by its primary key
+func (m *UserApiKeysModel) Find(ctx context.Context, id int64) (*UserApiKeysN, error) {
+	return m.First(ctx, m.query.Where("id", "=", id))
+}
+
+// Exists return whether the records exists for a given query
+func (m *UserApiKeysModel) Exists(ctx context.Context, builders ...query.SQLBuilder) (bool, error) {
+	count, err := m.Count(ctx, builders...)
+	return count > 0, err
+}
+
+// Count return model count for a given query
+func (m *UserApiKeysModel) Count(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+	sqlStr, params := m.query.
+		Merge(builders...).
+		Table(m.tableName).
+		AppendCondition(m.applyScope()).
+		ResolveCount()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	rows.Next()
+	var res int64
+	if err := rows.Scan(&res); err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+func (m *UserApiKeysModel) Paginate(ctx context.Context, page int64, perPage int64, builders ...query.SQLBuilder) ([]UserApiKeysN, query.PaginateMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	if perPage <= 0 {
+		perPage = 15
+	}
+
+	meta := query.PaginateMeta{
+		PerPage: perPage,
+		Page:    page,
+	}
+
+	count, err := m.Count(ctx, builders...)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	meta.Total = count
+	meta.LastPage = count / perPage
+	if count%perPage != 0 {
+		meta.LastPage += 1
+	}
+
+	res, err := m.Get(ctx, append([]query.SQLBuilder{query.Builder().Limit(perPage).Offset((page - 1) * perPage)}, builders...)...)
+	if err != nil {
+		return res, meta, err
+	}
+
+	return res, meta, nil
+}
+
+// Get retrieve all results for given query
+func (m *UserApiKeysModel) Get(ctx context.Context, builders ...query.SQLBuilder) ([]UserApiKeysN, error) {
+	b := m.query.Merge(builders...).Table(m.tableName).AppendCondition(m.applyScope())
+	if len(b.GetFields()) == 0 {
+		b = b.Select(
+			"id",
+			"user_id",
+			"name",
+			"key_hash",
+			"key_preview",
+			"scopes",
+			"last_used_at",
+			"expired_at",
+			"created_at",
+			"updated_at",
+		)
+	}
+
+	fields := b.GetFields()
+	selectFields := make([]query.Expr, 0)
+
+	for _, f := range fields {
+		switch strcase.ToSnake(f.Value) {
+
+		case "id":
+			selectFields = append(selectFields, f)
+		case "user_id":
+			selectFields = append(selectFields, f)
+		case "name":
+			selectFields = append(selectFields, f)
+		case "key_hash":
+			selectFields = append(selectFields, f)
+		case "key_preview":
+			selectFields = append(selectFields, f)
+		case "scopes":
+			selectFields = append(selectFields, f)
+		case "last_used_at":
+			selectFields = append(selectFields, f)
+		case "expired_at":
+			selectFields = append(selectFields, f)
+		case "created_at":
+			selectFields = append(selectFields, f)
+		case "updated_at":
+			selectFields = append(selectFields, f)
+		}
+	}
+
+	var createScanVar = func(fields []query.Expr) (*UserApiKeysN, []interface{}) {
+		var userApiKeysVar UserApiKeysN
+		scanFields := make([]interface{}, 0)
+
+		for _, f := range fields {
+			switch strcase.ToSnake(f.Value) {
+
+			case "id":
+				scanFields = append(scanFields, &userApiKeysVar.Id)
+			case "user_id":
+				scanFields = append(scanFields, &userApiKeysVar.UserId)
+			case "name":
+				scanFields = append(scanFields, &userApiKeysVar.Name)
+			case "key_hash":
+				scanFields = append(scanFields, &userApiKeysVar.KeyHash)
+			case "key_preview":
+				scanFields = append(scanFields, &userApiKeysVar.KeyPreview)
+			case "scopes":
+				scanFields = append(scanFields, &userApiKeysVar.Scopes)
+			case "last_used_at":
+				scanFields = append(scanFields, &userApiKeysVar.LastUsedAt)
+			case "expired_at":
+				scanFields = append(scanFields, &userApiKeysVar.ExpiredAt)
+			case "created_at":
+				scanFields = append(scanFields, &userApiKeysVar.CreatedAt)
+			case "updated_at":
+				scanFields = append(scanFields, &userApiKeysVar.UpdatedAt)
+			}
+		}
+
+		return &userApiKeysVar, scanFields
+	}
+
+	sqlStr, params := b.Fields(selectFields...).ResolveQuery()
+
+	rows, err := m.db.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	userApiKeyss := make([]UserApiKeysN, 0)
+	for rows.Next() {
+		userApiKeysReal, scanFields := createScanVar(fields)
+		if err := rows.Scan(scanFields...); err != nil {
+			return nil, err
+		}
+
+		userApiKeysReal.original = &userApiKeysOriginal{}
+		_ = query.Copy(userApiKeysReal, userApiKeysReal.original)
+
+		userApiKeysReal.SetModel(m)
+		userApiKeyss = append(userApiKeyss, *userApiKeysReal)
+	}
+
+	return userApiKeyss, nil
+}
+
+// First return first result for given query
+func (m *UserApiKeysModel) First(ctx context.Context, builders ...query.SQLBuilder) (*UserApiKeysN, error) {
+	res, err := m.Get(ctx, append(builders, query.Builder().Limit(1))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, query.ErrNoResult
+	}
+
+	return &res[0], nil
+}
+
+// Create save a new user_api_keys to database
+func (m *UserApiKeysModel) Create(ctx context.Context, kv query.KV) (int64, error) {
+
+	if _, ok := kv["created_at"]; !ok {
+		kv["created_at"] = time.Now()
+	}
+
+	if _, ok := kv["updated_at"]; !ok {
+		kv["updated_at"] = time.Now()
+	}
+
+	sqlStr, params := m.query.Table(m.tableName).ResolveInsert(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SaveAll save all user_api_keyss to database
+func (m *UserApiKeysModel) SaveAll(ctx context.Context, userApiKeyss []UserApiKeysN) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, userApiKeys := range userApiKeyss {
+		id, err := m.Save(ctx, userApiKeys)
+		if err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Save save a user_api_keys to database
+func (m *UserApiKeysModel) Save(ctx context.Context, userApiKeys UserApiKeysN, onlyFields ...string) (int64, error) {
+	return m.Create(ctx, userApiKeys.StaledKV(onlyFields...))
+}
+
+// SaveOrUpdate save a new user_api_keys or update it when it has a id > 0
+func (m *UserApiKeysModel) SaveOrUpdate(ctx context.Context, userApiKeys UserApiKeysN, onlyFields ...string) (id int64, updated bool, err error) {
+	if userApiKeys.Id.Int64 > 0 {
+		_, _err := m.UpdateById(ctx, userApiKeys.Id.Int64, userApiKeys, onlyFields...)
+		return userApiKeys.Id.Int64, true, _err
+	}
+
+	_id, _err := m.Save(ctx, userApiKeys, onlyFields...)
+	return _id, false, _err
+}
+
+// UpdateFields update kv for a given query
+func (m *UserApiKeysModel) UpdateFields(ctx context.Context, kv query.KV, builders ...query.SQLBuilder) (int64, error) {
+	if len(kv) == 0 {
+		return 0, nil
+	}
+
+	kv["updated_at"] = time.Now()
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).
+		Table(m.tableName).
+		ResolveUpdate(kv)
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update update a model for given query
+func (m *UserApiKeysModel) Update(ctx context.Context, builder query.SQLBuilder, userApiKeys UserApiKeysN, onlyFields ...string) (int64, error) {
+	return m.UpdateFields(ctx, userApiKeys.StaledKV(onlyFields...), builder)
+}
+
+// UpdateById update a model by id
+func (m *UserApiKeysModel) UpdateById(ctx context.Context, id int64, userApiKeys UserApiKeysN, onlyFields ...string) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).UpdateFields(ctx, userApiKeys.StaledKV(onlyFields...))
+}
+
+// Delete remove a model
+func (m *UserApiKeysModel) Delete(ctx context.Context, builders ...query.SQLBuilder) (int64, error) {
+
+	sqlStr, params := m.query.Merge(builders...).AppendCondition(m.applyScope()).Table(m.tableName).ResolveDelete()
+
+	res, err := m.db.ExecContext(ctx, sqlStr, params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+
+}
+
+// DeleteById remove a model by id
+func (m *UserApiKeysModel) DeleteById(ctx context.Context, id int64) (int64, error) {
+	return m.Condition(query.Builder().Where("id", "=", id)).Delete(ctx)
+}
@@ -0,0 +1,379 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/hashicorp/go-uuid"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
+	"github.com/mylxsw/go-utils/must"
+	"gopkg.in/guregu/null.v3"
+	"time"
+)
+
+const (
+	// PaymentSourceApple Apple 应用内购买
+	PaymentSourceApple = "apple"
+)
+
+const (
+	// PaymentOrderStatusWaiting 订单已创建，等待支付
+	PaymentOrderStatusWaiting = "waiting"
+	// PaymentOrderStatusSucceed 订单已支付
+	PaymentOrderStatusSucceed = "succeed"
+)
+
+var (
+	// ErrPaymentDuplicated 支付渠道的交易已经处理过
+	ErrPaymentDuplicated = errors.New("the payment transaction has already been processed")
+	// ErrPaymentOrderNotMatched 交易绑定的订单不是当前用户待支付的订单
+	ErrPaymentOrderNotMatched = errors.New("the payment transaction does not match any waiting order of the user")
+)
+
+// PaymentRepo 支付仓库
+type PaymentRepo struct {
+	db   *sql.DB
+	conf *config.Config
+}
+
+// NewPaymentRepo create a new PaymentRepo
+func NewPaymentRepo(db *sql.DB, conf *config.Config) *PaymentRepo {
+	return &PaymentRepo{db: db, conf: conf}
+}
+
+// PaymentOrder 订单
+type PaymentOrder struct {
+	OrderNo   string     `json:"order_no"`
+	UserID    int64      `json:"-"`
+	ProductID string     `json:"product_id"`
+	Quota     int64      `json:"quota"`
+	Price     int64      `json:"price"`
+	Source    string     `json:"source"`
+	Status    string     `json:"status"`
+	PaidAt    *time.Time `json:"paid_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	// EventID the payment_completed event created when the order is paid
+	EventID int64 `json:"-"`
+}
+
+func buildPaymentOrderFromModel(m model.PaymentOrdersN) PaymentOrder {
+	order := PaymentOrder{
+		OrderNo:   m.OrderNo.ValueOrZero(),
+		UserID:    m.UserId.ValueOrZero(),
+		ProductID: m.ProductId.ValueOrZero(),
+		Quota:     m.Quota.ValueOrZero(),
+		Price:     m.Price.ValueOrZero(),
+		Source:    m.Source.ValueOrZero(),
+		Status:    m.Status.ValueOrZero(),
+		CreatedAt: m.CreatedAt.ValueOrZero(),
+		EventID:   m.EventId.ValueOrZero(),
+	}
+
+	if m.PaidAt.Valid {
+		paidAt := m.PaidAt.Time
+		order.PaidAt = &paidAt
+	}
+
+	return order
+}
+
+// PaymentTransaction 支付渠道校验通过的交易
+type PaymentTransaction struct {
+	Source                string
+	TransactionID         string
+	OriginalTransactionID string
+	ProductID             string
+	Quantity              int64
+	Environment           string
+	PurchasedAt           time.Time
+	// AccountToken the order no bound to the transaction when the purchase is made (appAccountToken for apple)
+	AccountToken string
+	// Payload the transaction information returned by the payment channel
+	Payload string
+}
+
+// PaymentRecord 支付记录
+type PaymentRecord struct {
+	OrderNo       string    `json:"order_no"`
+	UserID        int64     `json:"-"`
+	Source        string    `json:"source"`
+	TransactionID string    `json:"transaction_id"`
+	ProductID     string    `json:"product_id"`
+	Quantity      int64     `json:"quantity"`
+	Environment   string    `json:"environment"`
+	PurchasedAt   time.Time `json:"purchased_at"`
+}
+
+// CreateOrder 创建待支付的订单，订单号为 UUID，可以作为 Apple 应用内购买的 appAccountToken
+func (repo *PaymentRepo) CreateOrder(ctx context.Context, userID int64, product config.Product, source string) (*PaymentOrder, error) {
+	orderNo, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	order := model.PaymentOrdersN{
+		OrderNo:   null.StringFrom(orderNo),
+		UserId:    null.IntFrom(userID),
+		ProductId: null.StringFrom(product.ID),
+		Quota:     null.IntFrom(product.Quota),
+		Price:     null.IntFrom(product.Price),
+		Source:    null.StringFrom(source),
+		Status:    null.StringFrom(PaymentOrderStatusWaiting),
+	}
+
+	if _, err := model.NewPaymentOrdersModel(repo.db).Save(ctx, order); err != nil {
+		return nil, err
+	}
+
+	ret := buildPaymentOrderFromModel(order)
+	ret.CreatedAt = time.Now()
+	return &ret, nil
+}
+
+// GetOrder 查询用户的订单
+func (repo *PaymentRepo) GetOrder(ctx context.Context, userID int64, orderNo string) (*PaymentOrder, error) {
+	order, err := model.NewPaymentOrdersModel(repo.db).First(
+		ctx,
+		query.Builder().
+			Where(model.FieldPaymentOrdersOrderNo, orderNo).
+			Where(model.FieldPaymentOrdersUserId, userID),
+	)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := buildPaymentOrderFromModel(*order)
+	return &ret, nil
+}
+
+// Orders 分页查询用户已支付的订单
+func (repo *PaymentRepo) Orders(ctx context.Context, userID int64, page, perPage int64) ([]PaymentOrder, query.PaginateMeta, error) {
+	orders, meta, err := model.NewPaymentOrdersModel(repo.db).Paginate(
+		ctx,
+		page,
+		perPage,
+		query.Builder().
+			Where(model.FieldPaymentOrdersUserId, userID).
+			Where(model.FieldPaymentOrdersStatus, PaymentOrderStatusSucceed).
+			OrderBy(model.FieldPaymentOrdersId, "DESC"),
+	)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	return array.Map(orders, func(item model.PaymentOrdersN, _ int) PaymentOrder {
+		return buildPaymentOrderFromModel(item)
+	}), meta, nil
+}
+
+// GetPaymentByTransaction 根据支付渠道的交易 ID 查询支付记录
+func (repo *PaymentRepo) GetPaymentByTransaction(ctx context.Context, source, transactionID string) (*PaymentRecord, error) {
+	rec, err := model.NewPaymentRecordsModel(repo.db).First(
+		ctx,
+		query.Builder().
+			Where(model.FieldPaymentRecordsSource, source).
+			Where(model.FieldPaymentRecordsTransactionId, transactionID),
+	)
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &PaymentRecord{
+		OrderNo:       rec.OrderNo.ValueOrZero(),
+		UserID:        rec.UserId.ValueOrZero(),
+		Source:        rec.Source.ValueOrZero(),
+		TransactionID: rec.TransactionId.ValueOrZero(),
+		ProductID:     rec.ProductId.ValueOrZero(),
+		Quantity:      rec.Quantity.ValueOrZero(),
+		Environment:   rec.Environment.ValueOrZero(),
+		PurchasedAt:   rec.PurchasedAt.ValueOrZero(),
+	}, nil
+}
+
+// CompletePayment 记录校验通过的交易，将订单标记为已支付，并创建 payment_completed 事件
+//
+// 交易绑定了订单号（AccountToken）时，必须是当前用户待支付的同一产品订单；
+// 交易没有绑定订单号时（例如恢复购买），原始交易必须是当前用户之前校验过的交易，此时创建新的订单。
+// 不满足条件时返回 ErrPaymentOrderNotMatched，避免用户使用其它用户的交易 ID 充值。
+// 同一个交易只会处理一次，重复的交易返回 ErrPaymentDuplicated
+func (repo *PaymentRepo) CompletePayment(
+	ctx context.Context,
+	userID int64,
+	product config.Product,
+	trans PaymentTransaction,
+) (order *PaymentOrder, eventID int64, err error) {
+	if trans.Quantity <= 0 {
+		trans.Quantity = 1
+	}
+
+	err = eloquent.Transaction(repo.db, func(tx query.Database) error {
+		exists, err := model.NewPaymentRecordsModel(tx).Exists(
+			ctx,
+			query.Builder().
+				Where(model.FieldPaymentRecordsSource, trans.Source).
+				Where(model.FieldPaymentRecordsTransactionId, trans.TransactionID),
+		)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return ErrPaymentDuplicated
+		}
+
+		paidAt := time.Now()
+		current := model.PaymentOrdersN{
+			UserId:    null.IntFrom(userID),
+			ProductId: null.StringFrom(product.ID),
+			Quota:     null.IntFrom(product.Quota * trans.Quantity),
+			Price:     null.IntFrom(product.Price * trans.Quantity),
+			Source:    null.StringFrom(trans.Source),
+			Status:    null.StringFrom(PaymentOrderStatusSucceed),
+			PaidAt:    null.TimeFrom(paidAt),
+		}
+
+		var waiting *model.PaymentOrdersN
+		if trans.AccountToken != "" {
+			waiting, err = model.NewPaymentOrdersModel(tx).First(
+				ctx,
+				query.Builder().
+					Where(model.FieldPaymentOrdersOrderNo, trans.AccountToken).
+					Where(model.FieldPaymentOrdersUserId, userID).
+					Where(model.FieldPaymentOrdersSource, trans.Source).
+					Where(model.FieldPaymentOrdersProductId, product.ID).
+					Where(model.FieldPaymentOrdersStatus, PaymentOrderStatusWaiting),
+			)
+			if err != nil {
+				if errors.Is(err, query.ErrNoResult) {
+					return ErrPaymentOrderNotMatched
+				}
+
+				return err
+			}
+		} else {
+			restored, err := model.NewPaymentRecordsModel(tx).Exists(
+				ctx,
+				query.Builder().
+					Where(model.FieldPaymentRecordsSource, trans.Source).
+					Where(model.FieldPaymentRecordsOriginalTransactionId, trans.OriginalTransactionID).
+					Where(model.FieldPaymentRecordsUserId, userID),
+			)
+			if err != nil {
+				return err
+			}
+
+			if trans.OriginalTransactionID == "" || !restored {
+				return ErrPaymentOrderNotMatched
+			}
+		}
+
+		if waiting != nil {
+			current.OrderNo = waiting.OrderNo
+			current.CreatedAt = waiting.CreatedAt
+			if _, err := model.NewPaymentOrdersModel(tx).UpdateById(
+				ctx,
+				waiting.Id.ValueOrZero(),
+				current,
+				model.FieldPaymentOrdersQuota,
+				model.FieldPaymentOrdersPrice,
+				model.FieldPaymentOrdersStatus,
+				model.FieldPaymentOrdersPaidAt,
+			); err != nil {
+				return err
+			}
+		} else {
+			newOrderNo, err := uuid.GenerateUUID()
+			if err != nil {
+				return err
+			}
+
+			current.OrderNo = null.StringFrom(newOrderNo)
+			current.CreatedAt = null.TimeFrom(paidAt)
+			if _, err := model.NewPaymentOrdersModel(tx).Save(ctx, current); err != nil {
+				return err
+			}
+		}
+
+		if _, err := model.NewPaymentRecordsModel(tx).Save(ctx, model.PaymentRecordsN{
+			OrderNo:               current.OrderNo,
+			UserId:                null.IntFrom(userID),
+			Source:                null.StringFrom(trans.Source),
+			TransactionId:         null.StringFrom(trans.TransactionID),
+			OriginalTransactionId: null.StringFrom(trans.OriginalTransactionID),
+			ProductId:             null.StringFrom(trans.ProductID),
+			Quantity:              null.IntFrom(trans.Quantity),
+			Environment:           null.StringFrom(trans.Environment),
+			PurchasedAt:           null.TimeFrom(trans.PurchasedAt),
+			Payload:               null.StringFrom(trans.Payload),
+		}); err != nil {
+			// 并发提交同一个交易时，由唯一索引保证只处理一次
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+				return ErrPaymentDuplicated
+			}
+
+			return err
+		}
+
+		eventID, err = model.NewEventsModel(tx).Save(ctx, model.EventsN{
+			EventType: null.StringFrom(EventTypePaymentCompleted),
+			Payload: null.StringFrom(string(must.Must(json.Marshal(PaymentCompletedEvent{
+				UserID:    userID,
+				ProductID: product.ID,
+				PaymentID: current.OrderNo.ValueOrZero(),
+			})))),
+			Status: null.StringFrom(EventStatusWaiting),
+		})
+		if err != nil {
+			return err
+		}
+
+		current.EventId = null.IntFrom(eventID)
+		if _, err := model.NewPaymentOrdersModel(tx).UpdateFields(
+			ctx,
+			query.KV{model.FieldPaymentOrdersEventId: eventID},
+			query.Builder().Where(model.FieldPaymentOrdersOrderNo, current.OrderNo.ValueOrZero()),
+		); err != nil {
+			return err
+		}
+
+		ret := buildPaymentOrderFromModel(current)
+		order = &ret
+		return nil
+	})
+
+	return
+}
+
+// GetPaymentCompletedEvent 查询订单对应的 payment_completed 事件
+func (repo *PaymentRepo) GetPaymentCompletedEvent(ctx context.Context, order PaymentOrder) (*model.Events, error) {
+	if order.EventID <= 0 {
+		return nil, ErrNotFound
+	}
+
+	event, err := model.NewEventsModel(repo.db).First(ctx, query.Builder().Where(model.FieldEventsId, order.EventID))
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := event.ToEvents()
+	return &ret, nil
+}
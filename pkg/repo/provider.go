@@ -24,6 +24,10 @@ func (Provider) Register(binder infra.Binder) {
 	binder.MustSingleton(NewQuotaRepo)
 	binder.MustSingleton(NewQueueRepo)
 	binder.MustSingleton(NewRobotRepo)
+	binder.MustSingleton(NewConversationRepo)
+	binder.MustSingleton(NewAPIKeyRepo)
+	binder.MustSingleton(NewKnowledgeRepo)
+	binder.MustSingleton(NewPaymentRepo)
 
 	// MySQL 数据库连接
 	binder.MustSingleton(func(conf *config.Config) (*sql.DB, error) {
@@ -70,10 +74,14 @@ func (Provider) Boot(resolver infra.Resolver) {
 }
 
 type Repository struct {
-	Cache *CacheRepo `autowire:"@"`
-	User  *UserRepo  `autowire:"@"`
-	Event *EventRepo `autowire:"@"`
-	Quota *QuotaRepo `autowire:"@"`
-	Queue *QueueRepo `autowire:"@"`
-	Robot *RobotRepo `autowire:"@"`
+	Cache        *CacheRepo        `autowire:"@"`
+	User         *UserRepo         `autowire:"@"`
+	Event        *EventRepo        `autowire:"@"`
+	Quota        *QuotaRepo        `autowire:"@"`
+	Queue        *QueueRepo        `autowire:"@"`
+	Robot        *RobotRepo        `autowire:"@"`
+	Conversation *ConversationRepo `autowire:"@"`
+	APIKey       *APIKeyRepo       `autowire:"@"`
+	Knowledge    *KnowledgeRepo    `autowire:"@"`
+	Payment      *PaymentRepo      `autowire:"@"`
 }
@@ -46,6 +46,16 @@ func (repo *QuotaRepo) AddUserQuota(ctx context.Context, userID int64, quotaValu
 	))
 }
 
+// PaymentQuotaExists 用户是否已经获得了支付对应的配额
+func (repo *QuotaRepo) PaymentQuotaExists(ctx context.Context, userID int64, paymentID string) (bool, error) {
+	return model.NewQuotaModel(repo.db).Exists(
+		ctx,
+		query.Builder().
+			Where(model.FieldQuotaUserId, userID).
+			Where(model.FieldQuotaPaymentId, paymentID),
+	)
+}
+
 // TimeInDate 获取时间的日期部分
 func TimeInDate(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, 1)
@@ -3,14 +3,23 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"github.com/mylxsw/aidea-chat-server/pkg/repo/model"
+	"github.com/mylxsw/eloquent/query"
+	"github.com/mylxsw/go-utils/array"
+	"gopkg.in/guregu/null.v3"
 )
 
 type RobotRepo struct {
-	db *sql.DB
+	db   *sql.DB
+	conf *config.Config
 }
 
-func NewRobotRepo(db *sql.DB) *RobotRepo {
-	return &RobotRepo{db: db}
+func NewRobotRepo(db *sql.DB, conf *config.Config) *RobotRepo {
+	return &RobotRepo{db: db, conf: conf}
 }
 
 // RobotType robot type
@@ -41,11 +50,19 @@ type RobotMeta struct {
 	KnowledgeBases []string `json:"knowledge_bases,omitempty"`
 	// OriginReference whether to display knowledge base sources
 	OriginReference bool `json:"origin_reference,omitempty"`
+	// Tools the built-in tools that the robot can use, executed by the server during chat
+	Tools []string `json:"tools,omitempty"`
+	// Context how to reduce the context when it exceeds the model limit, the model configuration is used when not set
+	Context *config.ContextReduction `json:"context,omitempty"`
+	// DisableStreamUsage do not request stream_options.include_usage from the custom server, for servers that reject unknown parameters
+	DisableStreamUsage bool `json:"disable_stream_usage,omitempty"`
 }
 
 type Robot struct {
 	// RobotID robot id
 	RobotID string `json:"robot_id"`
+	// UserID the creator of the robot, 0 means built-in robot
+	UserID int64 `json:"-"`
 	// Name robot name
 	Name string `json:"name"`
 	// Type robot type
@@ -56,14 +73,14 @@ type Robot struct {
 	// Privilege robot privilege
 	Privilege RobotPrivilege `json:"privilege,omitempty"`
 
-	// Model robot model (if type is model driven)
+	// Model robot model, for custom server robots it is used for token counting and billing
 	Model string `json:"model,omitempty"`
 	// Prompt robot prompt (if type is model driven)
 	Prompt string `json:"prompt,omitempty"`
 	// WelcomeMessage robot welcome message (if type is model driven)
 	WelcomeMessage string `json:"welcome_message,omitempty"`
 
-	// ServerURL robot server url (if type is custom server)
+	// ServerURL robot server url (if type is custom server), OpenAI compatible base url, such as https://example.com/v1
 	ServerURL string `json:"server_url,omitempty"`
 	// ServerToken robot server jwt (if type is custom server)
 	ServerToken string `json:"server_token,omitempty"`
@@ -72,11 +89,187 @@ type Robot struct {
 	RobotMeta RobotMeta `json:"robot_meta,omitempty"`
 }
 
+// AccessibleBy whether the robot can be used by the specified user
+func (robot Robot) AccessibleBy(userID int64) bool {
+	return robot.Privilege == RobotPrivilegePublic || robot.UserID == userID
+}
+
+// IsBuiltin whether the robot is a built-in robot
+func (robot Robot) IsBuiltin() bool {
+	return robot.UserID == 0
+}
+
+func buildRobotFromModel(m model.Robots) Robot {
+	robot := Robot{
+		RobotID:        m.RobotId,
+		UserID:         m.UserId,
+		Name:           m.Name,
+		Type:           RobotType(m.Type),
+		Description:    m.Description,
+		Privilege:      RobotPrivilege(m.Privilege),
+		Model:          m.Model,
+		Prompt:         m.Prompt,
+		WelcomeMessage: m.WelcomeMessage,
+		ServerURL:      m.ServerUrl,
+		ServerToken:    m.ServerToken,
+	}
+
+	if m.RobotMeta != "" {
+		_ = json.Unmarshal([]byte(m.RobotMeta), &robot.RobotMeta)
+	}
+
+	return robot
+}
+
+// modelRobot 没有在数据库中定义的机器人，如果 ID 与模型 ID 一致，则作为内置机器人直接使用该模型
+func (repo *RobotRepo) modelRobot(robotID string) (*Robot, bool) {
+	for _, m := range repo.conf.Models {
+		if m.ID == robotID {
+			return &Robot{
+				RobotID:   m.ID,
+				Name:      m.Name,
+				Type:      RobotTypeModelDriven,
+				Privilege: RobotPrivilegePublic,
+				Model:     m.ID,
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
 // GetRobotByID get robot by id
 func (repo *RobotRepo) GetRobotByID(ctx context.Context, robotID string) (*Robot, error) {
-	return &Robot{
-		RobotID: robotID,
-		Type:    RobotTypeModelDriven,
-		Model:   robotID,
-	}, nil
+	robot, err := model.NewRobotsModel(repo.db).First(ctx, query.Builder().Where(model.FieldRobotsRobotId, robotID))
+	if err != nil {
+		if errors.Is(err, query.ErrNoResult) {
+			if r, ok := repo.modelRobot(robotID); ok {
+				return r, nil
+			}
+
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	ret := buildRobotFromModel(robot.ToRobots())
+	return &ret, nil
+}
+
+// GetUserRobot 获取用户可以使用的机器人，私有机器人只有创建者可以使用
+func (repo *RobotRepo) GetUserRobot(ctx context.Context, userID int64, robotID string) (*Robot, error) {
+	robot, err := repo.GetRobotByID(ctx, robotID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !robot.AccessibleBy(userID) {
+		return nil, ErrNotFound
+	}
+
+	return robot, nil
+}
+
+// GetUserRobots 获取用户可以使用的机器人列表，包括用户自己创建的机器人以及所有公开的机器人
+func (repo *RobotRepo) GetUserRobots(ctx context.Context, userID int64) ([]Robot, error) {
+	robots, err := model.NewRobotsModel(repo.db).Get(
+		ctx,
+		query.Builder().
+			WhereGroup(func(builder query.Condition) {
+				builder.Where(model.FieldRobotsUserId, userID).
+					OrWhere(model.FieldRobotsPrivilege, RobotPrivilegePublic)
+			}).
+			OrderBy(model.FieldRobotsId, "DESC"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return array.Map(robots, func(item model.RobotsN, _ int) Robot {
+		return buildRobotFromModel(item.ToRobots())
+	}), nil
+}
+
+func robotToModel(robot Robot) model.RobotsN {
+	meta, _ := json.Marshal(robot.RobotMeta)
+	return model.RobotsN{
+		Name:           null.StringFrom(robot.Name),
+		Type:           null.IntFrom(int64(robot.Type)),
+		Description:    null.StringFrom(robot.Description),
+		Privilege:      null.IntFrom(int64(robot.Privilege)),
+		Model:          null.StringFrom(robot.Model),
+		Prompt:         null.StringFrom(robot.Prompt),
+		WelcomeMessage: null.StringFrom(robot.WelcomeMessage),
+		ServerUrl:      null.StringFrom(robot.ServerURL),
+		ServerToken:    null.StringFrom(robot.ServerToken),
+		RobotMeta:      null.StringFrom(string(meta)),
+	}
+}
+
+// CreateRobot 创建机器人，返回创建后的机器人
+func (repo *RobotRepo) CreateRobot(ctx context.Context, userID int64, robot Robot) (*Robot, error) {
+	robot.RobotID = misc.UUID()
+	robot.UserID = userID
+
+	m := robotToModel(robot)
+	m.RobotId = null.StringFrom(robot.RobotID)
+	m.UserId = null.IntFrom(userID)
+
+	if _, err := model.NewRobotsModel(repo.db).Save(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return &robot, nil
+}
+
+// UpdateRobot 更新机器人信息，只有创建者可以更新
+func (repo *RobotRepo) UpdateRobot(ctx context.Context, userID int64, robotID string, robot Robot) error {
+	q := query.Builder().
+		Where(model.FieldRobotsRobotId, robotID).
+		Where(model.FieldRobotsUserId, userID)
+
+	exist, err := model.NewRobotsModel(repo.db).Exists(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		return ErrNotFound
+	}
+
+	meta, _ := json.Marshal(robot.RobotMeta)
+	_, err = model.NewRobotsModel(repo.db).UpdateFields(ctx, query.KV{
+		model.FieldRobotsName:           robot.Name,
+		model.FieldRobotsType:           int64(robot.Type),
+		model.FieldRobotsDescription:    robot.Description,
+		model.FieldRobotsPrivilege:      int64(robot.Privilege),
+		model.FieldRobotsModel:          robot.Model,
+		model.FieldRobotsPrompt:         robot.Prompt,
+		model.FieldRobotsWelcomeMessage: robot.WelcomeMessage,
+		model.FieldRobotsServerUrl:      robot.ServerURL,
+		model.FieldRobotsServerToken:    robot.ServerToken,
+		model.FieldRobotsRobotMeta:      string(meta),
+	}, q)
+
+	return err
+}
+
+// DeleteRobot 删除机器人，只有创建者可以删除
+func (repo *RobotRepo) DeleteRobot(ctx context.Context, userID int64, robotID string) error {
+	deleted, err := model.NewRobotsModel(repo.db).Delete(
+		ctx,
+		query.Builder().
+			Where(model.FieldRobotsRobotId, robotID).
+			Where(model.FieldRobotsUserId, userID),
+	)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	return nil
 }
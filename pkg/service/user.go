@@ -15,6 +15,9 @@ import (
 	"time"
 )
 
+// ErrQuotaNotEnough the user's available quota is less than the quota to be frozen
+var ErrQuotaNotEnough = errors.New("quota not enough")
+
 type UserService struct {
 	repo    *repo.Repository
 	rds     *redis.Client
@@ -91,14 +94,21 @@ func (srv *UserService) UserQuota(ctx context.Context, userID int64) (*UserQuota
 	}, nil
 }
 
-// FreezeUserQuota freeze user quotas
+// FreezeUserQuota freeze user quotas, returns ErrQuotaNotEnough when the available quota is not enough.
+// The frozen quota is increased first and then compared with the rest quota, so concurrent requests
+// can not pass the check at the same time and overdraw the balance.
 func (srv *UserService) FreezeUserQuota(ctx context.Context, userID int64, quota int64) error {
 	if quota <= 0 {
 		return nil
 	}
 
+	summary, err := srv.repo.Quota.GetUserQuota(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user quota failed: %w", err)
+	}
+
 	key := srv.userQuotaFrozenCacheKey(userID)
-	_, err := srv.rds.IncrBy(ctx, key, quota).Result()
+	frozen, err := srv.rds.IncrBy(ctx, key, quota).Result()
 	if err != nil {
 		return fmt.Errorf("freeze user quota failed: %w", err)
 	}
@@ -107,6 +117,14 @@ func (srv *UserService) FreezeUserQuota(ctx context.Context, userID int64, quota
 		log.F(log.M{"user_id": userID, "quota": quota}).Errorf("failed to set user frozen quota expiration time: %s", err)
 	}
 
+	if frozen > summary.Rest {
+		if err := srv.UnfreezeUserQuota(ctx, userID, quota); err != nil {
+			log.F(log.M{"user_id": userID, "quota": quota}).Errorf("failed to rollback user frozen quota: %s", err)
+		}
+
+		return ErrQuotaNotEnough
+	}
+
 	return nil
 }
 
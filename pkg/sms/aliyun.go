@@ -0,0 +1,136 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/go-uuid"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AliyunDriver 阿里云短信服务，使用 SendSms 接口（RPC 风格，HMAC-SHA1 签名）
+//
+// https://help.aliyun.com/document_detail/419273.html
+type AliyunDriver struct {
+	signName string
+	conf     config.SMSAliyun
+	client   *http.Client
+}
+
+// NewAliyunDriver 创建阿里云短信驱动
+func NewAliyunDriver(signName string, conf config.SMSAliyun, client *http.Client) *AliyunDriver {
+	return &AliyunDriver{signName: signName, conf: conf, client: client}
+}
+
+func (drv *AliyunDriver) Name() string {
+	return config.SMSDriverAliyun
+}
+
+type aliyunResponse struct {
+	RequestID string `json:"RequestId"`
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	BizID     string `json:"BizId"`
+}
+
+func (drv *AliyunDriver) Send(ctx context.Context, msg Message) error {
+	params := make(map[string]string, len(msg.Params))
+	for _, p := range msg.Params {
+		params[p.Name] = p.Value
+	}
+
+	templateParam, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+
+	values := url.Values{}
+	values.Set("Action", "SendSms")
+	values.Set("Version", "2017-05-25")
+	values.Set("Format", "JSON")
+	values.Set("RegionId", "cn-hangzhou")
+	values.Set("AccessKeyId", drv.conf.AccessKeyID)
+	values.Set("SignatureMethod", "HMAC-SHA1")
+	values.Set("SignatureVersion", "1.0")
+	values.Set("SignatureNonce", nonce)
+	values.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	values.Set("PhoneNumbers", aliyunPhoneNumber(msg.Receiver))
+	values.Set("SignName", drv.signName)
+	values.Set("TemplateCode", msg.TemplateID)
+	values.Set("TemplateParam", string(templateParam))
+	values.Set("Signature", aliyunSign(http.MethodGet, values, drv.conf.AccessKeySecret))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(drv.conf.Endpoint, "/")+"/?"+values.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := drv.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send aliyun sms request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var ret aliyunResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return fmt.Errorf("decode aliyun sms response failed (status %d): %w", resp.StatusCode, err)
+	}
+
+	if ret.Code != "OK" {
+		return fmt.Errorf("aliyun sms failed: [%s] %s (request id: %s)", ret.Code, ret.Message, ret.RequestID)
+	}
+
+	return nil
+}
+
+// aliyunSign 计算 RPC 风格接口的签名
+func aliyunSign(method string, values url.Values, secret string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, aliyunPercentEncode(k)+"="+aliyunPercentEncode(values.Get(k)))
+	}
+
+	stringToSign := method + "&" + aliyunPercentEncode("/") + "&" + aliyunPercentEncode(strings.Join(pairs, "&"))
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliyunPercentEncode 阿里云要求的 URL 编码，空格编码为 %20，* 编码为 %2A，~ 不编码
+func aliyunPercentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	s = strings.ReplaceAll(s, "%7E", "~")
+	return s
+}
+
+// aliyunPhoneNumber 国内号码不带国家码，国际号码为国家码 + 号码，不带 +
+func aliyunPhoneNumber(phone string) string {
+	if strings.HasPrefix(phone, "+86") {
+		return strings.TrimPrefix(phone, "+86")
+	}
+
+	return strings.TrimPrefix(phone, "+")
+}
@@ -0,0 +1,66 @@
+package sms
+
+import (
+	"context"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/asteria/log"
+	"sync"
+)
+
+// FakeDriver 不实际发送短信，只输出日志并在内存中记录，用于开发和测试
+type FakeDriver struct {
+	lock     sync.Mutex
+	messages []Message
+}
+
+// NewFakeDriver 创建 fake 短信驱动
+func NewFakeDriver() *FakeDriver {
+	return &FakeDriver{messages: make([]Message, 0)}
+}
+
+func (drv *FakeDriver) Name() string {
+	return config.SMSDriverFake
+}
+
+func (drv *FakeDriver) Send(ctx context.Context, msg Message) error {
+	drv.lock.Lock()
+	defer drv.lock.Unlock()
+
+	drv.messages = append(drv.messages, msg)
+	log.F(log.M{"template": msg.Template, "params": msg.Params}).Infof("[fake sms] send sms to %s", msg.Receiver)
+
+	return nil
+}
+
+// Messages 已经发送的所有短信
+func (drv *FakeDriver) Messages() []Message {
+	drv.lock.Lock()
+	defer drv.lock.Unlock()
+
+	messages := make([]Message, len(drv.messages))
+	copy(messages, drv.messages)
+
+	return messages
+}
+
+// Last 最后一条发送给 receiver 的短信
+func (drv *FakeDriver) Last(receiver string) (Message, bool) {
+	drv.lock.Lock()
+	defer drv.lock.Unlock()
+
+	for i := len(drv.messages) - 1; i >= 0; i-- {
+		if drv.messages[i].Receiver == receiver {
+			return drv.messages[i], true
+		}
+	}
+
+	return Message{}, false
+}
+
+// Reset 清空记录的短信
+func (drv *FakeDriver) Reset() {
+	drv.lock.Lock()
+	defer drv.lock.Unlock()
+
+	drv.messages = make([]Message, 0)
+}
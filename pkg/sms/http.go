@@ -0,0 +1,58 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"io"
+	"net/http"
+)
+
+// HTTPDriver 将短信以 JSON 格式 POST 到自定义的 Webhook，由 Webhook 负责实际发送
+//
+// 请求体为 Message，Webhook 返回 2xx 状态码表示发送成功
+type HTTPDriver struct {
+	conf   config.SMSHTTP
+	client *http.Client
+}
+
+// NewHTTPDriver 创建 HTTP Webhook 短信驱动
+func NewHTTPDriver(conf config.SMSHTTP, client *http.Client) *HTTPDriver {
+	return &HTTPDriver{conf: conf, client: client}
+}
+
+func (drv *HTTPDriver) Name() string {
+	return config.SMSDriverHTTP
+}
+
+func (drv *HTTPDriver) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, drv.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if drv.conf.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+drv.conf.Token)
+	}
+
+	resp, err := drv.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send sms webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sms webhook responded with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	return nil
+}
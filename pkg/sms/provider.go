@@ -0,0 +1,22 @@
+package sms
+
+import (
+	"github.com/mylxsw/aidea-chat-server/config"
+	"github.com/mylxsw/asteria/log"
+	"github.com/mylxsw/glacier/infra"
+)
+
+type Provider struct{}
+
+func (Provider) Register(binder infra.Binder) {
+	binder.MustSingleton(func(conf *config.Config) (Driver, error) {
+		if conf.SMS.Driver == config.SMSDriverFake {
+			log.Warning("sms driver is fake, sms messages are only logged and not actually sent")
+		}
+
+		return NewDriver(conf.SMS)
+	})
+	binder.MustSingleton(func(conf *config.Config, driver Driver) *Sender {
+		return NewSender(conf.SMS, driver)
+	})
+}
@@ -0,0 +1,98 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"net/http"
+	"time"
+)
+
+// ErrTemplateNotConfigured 短信模板未配置
+var ErrTemplateNotConfigured = errors.New("sms template not configured")
+
+// Param 短信模板参数
+type Param struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Message 一条短信
+type Message struct {
+	// Receiver the phone number of the receiver, numbers without country code are treated as mainland China numbers
+	Receiver string `json:"receiver"`
+	// Template the template name, e.g. verify_code
+	Template string `json:"template"`
+	// TemplateID the template id of the sms provider
+	TemplateID string `json:"template_id"`
+	// Params template parameters, ordered for providers using positional parameters (tencent)
+	Params []Param `json:"params"`
+}
+
+// Driver 短信服务驱动
+type Driver interface {
+	// Name the name of the driver
+	Name() string
+	// Send 发送短信
+	Send(ctx context.Context, msg Message) error
+}
+
+// Sender 使用配置的驱动发送短信
+type Sender struct {
+	conf   config.SMS
+	driver Driver
+}
+
+// NewSender 创建短信发送器
+func NewSender(conf config.SMS, driver Driver) *Sender {
+	return &Sender{conf: conf, driver: driver}
+}
+
+// NewDriver 根据配置创建短信驱动
+func NewDriver(conf config.SMS) (Driver, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch conf.Driver {
+	case config.SMSDriverAliyun:
+		return NewAliyunDriver(conf.SignName, conf.Aliyun, client), nil
+	case config.SMSDriverTencent:
+		return NewTencentDriver(conf.SignName, conf.Tencent, client), nil
+	case config.SMSDriverHTTP:
+		if conf.HTTP.URL == "" {
+			return nil, errors.New("sms.http.url is required")
+		}
+
+		return NewHTTPDriver(conf.HTTP, client), nil
+	case config.SMSDriverFake:
+		return NewFakeDriver(), nil
+	default:
+		return nil, fmt.Errorf("unsupported sms driver: %s", conf.Driver)
+	}
+}
+
+// Driver 当前使用的驱动
+func (s *Sender) Driver() Driver {
+	return s.driver
+}
+
+// Send 使用指定的模板发送短信
+func (s *Sender) Send(ctx context.Context, receiver string, template string, params ...Param) error {
+	templateID := s.conf.Templates[template]
+	// fake 驱动不需要配置模板
+	if templateID == "" && s.driver.Name() != config.SMSDriverFake {
+		return fmt.Errorf("%w: %s", ErrTemplateNotConfigured, template)
+	}
+
+	return s.driver.Send(ctx, Message{
+		Receiver:   receiver,
+		Template:   template,
+		TemplateID: templateID,
+		Params:     params,
+	})
+}
+
+// SendVerifyCode 发送短信验证码
+func (s *Sender) SendVerifyCode(ctx context.Context, receiver string, code string) error {
+	return s.Send(ctx, receiver, config.SMSTemplateVerifyCode, Param{Name: "code", Value: code})
+}
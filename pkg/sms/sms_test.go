@@ -0,0 +1,151 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAliyunSign(t *testing.T) {
+	// 阿里云文档中的签名示例
+	values := url.Values{}
+	values.Set("Timestamp", "2016-02-23T12:46:24Z")
+	values.Set("Format", "XML")
+	values.Set("AccessKeyId", "testid")
+	values.Set("Action", "DescribeRegions")
+	values.Set("SignatureMethod", "HMAC-SHA1")
+	values.Set("SignatureNonce", "3ee8c1b8-83d3-44af-a94f-4e0ad82fd6cf")
+	values.Set("Version", "2014-05-26")
+	values.Set("SignatureVersion", "1.0")
+
+	if got := aliyunSign(http.MethodGet, values, "testsecret"); got != "OLeaidS1JvxuMvnyHOwuJ+uX5qY=" {
+		t.Errorf("unexpected signature: %s", got)
+	}
+}
+
+func TestAliyunPercentEncode(t *testing.T) {
+	testCases := map[string]string{
+		"a b":     "a%20b",
+		"a*b":     "a%2Ab",
+		"a~b":     "a~b",
+		"/":       "%2F",
+		`{"k":1}`: "%7B%22k%22%3A1%7D",
+	}
+
+	for input, want := range testCases {
+		if got := aliyunPercentEncode(input); got != want {
+			t.Errorf("%q: expect %s, got %s", input, want, got)
+		}
+	}
+}
+
+func TestTencentAuthorization(t *testing.T) {
+	body := []byte(`{"PhoneNumberSet":["+8613800000000"],"SmsSdkAppId":"1400000000","SignName":"AIdea","TemplateId":"123456","TemplateParamSet":["1234"]}`)
+	got := tencentAuthorization(
+		"AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE",
+		"Gu5t9xGARNpq86cd98joQYCN3EXAMPLE",
+		"sms.tencentcloudapi.com",
+		"application/json; charset=utf-8",
+		body,
+		time.Unix(1551113065, 0),
+	)
+
+	want := "TC3-HMAC-SHA256 Credential=AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE/2019-02-25/sms/tc3_request, " +
+		"SignedHeaders=content-type;host, Signature=1fbfe37f653824343d2a1c110245aded48b314d89f0f6faeada023fe2d41ec0d"
+	if got != want {
+		t.Errorf("unexpected authorization:\nexpect %s\ngot    %s", want, got)
+	}
+}
+
+func TestPhoneNumbers(t *testing.T) {
+	testCases := []struct {
+		phone   string
+		aliyun  string
+		tencent string
+	}{
+		{phone: "13800000000", aliyun: "13800000000", tencent: "+8613800000000"},
+		{phone: "+8613800000000", aliyun: "13800000000", tencent: "+8613800000000"},
+		{phone: "+14155550100", aliyun: "14155550100", tencent: "+14155550100"},
+	}
+
+	for _, tc := range testCases {
+		if got := aliyunPhoneNumber(tc.phone); got != tc.aliyun {
+			t.Errorf("aliyun %s: expect %s, got %s", tc.phone, tc.aliyun, got)
+		}
+
+		if got := tencentPhoneNumber(tc.phone); got != tc.tencent {
+			t.Errorf("tencent %s: expect %s, got %s", tc.phone, tc.tencent, got)
+		}
+	}
+}
+
+func TestSender_SendVerifyCode(t *testing.T) {
+	driver := NewFakeDriver()
+	sender := NewSender(config.SMS{Driver: config.SMSDriverFake}, driver)
+
+	if err := sender.SendVerifyCode(context.Background(), "13800000000", "1234"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	msg, ok := driver.Last("13800000000")
+	if !ok {
+		t.Fatal("message is not recorded")
+	}
+
+	if msg.Template != config.SMSTemplateVerifyCode || len(msg.Params) != 1 || msg.Params[0] != (Param{Name: "code", Value: "1234"}) {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	driver.Reset()
+	if len(driver.Messages()) != 0 {
+		t.Error("messages should be cleared")
+	}
+}
+
+func TestSender_TemplateNotConfigured(t *testing.T) {
+	sender := NewSender(config.SMS{Driver: config.SMSDriverHTTP}, NewHTTPDriver(config.SMSHTTP{URL: "http://127.0.0.1"}, http.DefaultClient))
+
+	err := sender.SendVerifyCode(context.Background(), "13800000000", "1234")
+	if !errors.Is(err, ErrTemplateNotConfigured) {
+		t.Errorf("expect ErrTemplateNotConfigured, got %v", err)
+	}
+}
+
+func TestHTTPDriver_Send(t *testing.T) {
+	var received Message
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if received.Receiver == "fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("failed"))
+		}
+	}))
+	defer server.Close()
+
+	driver := NewHTTPDriver(config.SMSHTTP{URL: server.URL, Token: "secret"}, server.Client())
+
+	msg := Message{Receiver: "13800000000", Template: "verify_code", TemplateID: "tpl", Params: []Param{{Name: "code", Value: "1234"}}}
+	if err := driver.Send(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if auth != "Bearer secret" || received.Receiver != msg.Receiver || received.TemplateID != msg.TemplateID {
+		t.Errorf("unexpected request: %s %+v", auth, received)
+	}
+
+	if err := driver.Send(context.Background(), Message{Receiver: "fail"}); err == nil {
+		t.Error("expect error when webhook responds with non 2xx status")
+	}
+}
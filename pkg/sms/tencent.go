@@ -0,0 +1,171 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/config"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TencentDriver 腾讯云短信服务，使用 SendSms 接口（API 3.0，TC3-HMAC-SHA256 签名）
+//
+// https://cloud.tencent.com/document/product/382/55981
+type TencentDriver struct {
+	signName string
+	conf     config.SMSTencent
+	client   *http.Client
+}
+
+// NewTencentDriver 创建腾讯云短信驱动
+func NewTencentDriver(signName string, conf config.SMSTencent, client *http.Client) *TencentDriver {
+	return &TencentDriver{signName: signName, conf: conf, client: client}
+}
+
+func (drv *TencentDriver) Name() string {
+	return config.SMSDriverTencent
+}
+
+type tencentRequest struct {
+	PhoneNumberSet   []string `json:"PhoneNumberSet"`
+	SmsSdkAppID      string   `json:"SmsSdkAppId"`
+	SignName         string   `json:"SignName"`
+	TemplateID       string   `json:"TemplateId"`
+	TemplateParamSet []string `json:"TemplateParamSet"`
+}
+
+type tencentResponse struct {
+	Response struct {
+		RequestID string `json:"RequestId"`
+		Error     *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error,omitempty"`
+		SendStatusSet []struct {
+			PhoneNumber string `json:"PhoneNumber"`
+			Code        string `json:"Code"`
+			Message     string `json:"Message"`
+		} `json:"SendStatusSet"`
+	} `json:"Response"`
+}
+
+func (drv *TencentDriver) Send(ctx context.Context, msg Message) error {
+	// 腾讯云的模板参数是按照位置替换的
+	params := make([]string, 0, len(msg.Params))
+	for _, p := range msg.Params {
+		params = append(params, p.Value)
+	}
+
+	body, err := json.Marshal(tencentRequest{
+		PhoneNumberSet:   []string{tencentPhoneNumber(msg.Receiver)},
+		SmsSdkAppID:      drv.conf.SDKAppID,
+		SignName:         drv.signName,
+		TemplateID:       msg.TemplateID,
+		TemplateParamSet: params,
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(drv.conf.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid tencent sms endpoint: %w", err)
+	}
+
+	now := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, drv.conf.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	contentType := "application/json; charset=utf-8"
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-TC-Action", "SendSms")
+	req.Header.Set("X-TC-Version", "2021-01-11")
+	req.Header.Set("X-TC-Region", drv.conf.Region)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("Authorization", tencentAuthorization(drv.conf.SecretID, drv.conf.SecretKey, endpoint.Host, contentType, body, now))
+
+	resp, err := drv.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send tencent sms request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var ret tencentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return fmt.Errorf("decode tencent sms response failed (status %d): %w", resp.StatusCode, err)
+	}
+
+	if ret.Response.Error != nil {
+		return fmt.Errorf("tencent sms failed: [%s] %s (request id: %s)", ret.Response.Error.Code, ret.Response.Error.Message, ret.Response.RequestID)
+	}
+
+	for _, status := range ret.Response.SendStatusSet {
+		if status.Code != "Ok" {
+			return fmt.Errorf("tencent sms failed: [%s] %s (request id: %s)", status.Code, status.Message, ret.Response.RequestID)
+		}
+	}
+
+	return nil
+}
+
+// tencentAuthorization 计算 TC3-HMAC-SHA256 签名
+func tencentAuthorization(secretID, secretKey, host, contentType string, body []byte, now time.Time) string {
+	const service = "sms"
+	const algorithm = "TC3-HMAC-SHA256"
+
+	date := now.UTC().Format("2006-01-02")
+	signedHeaders := "content-type;host"
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost,
+		"/",
+		"",
+		"content-type:" + contentType + "\nhost:" + host + "\n",
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := date + "/" + service + "/tc3_request"
+	stringToSign := strings.Join([]string{
+		algorithm,
+		strconv.FormatInt(now.Unix(), 10),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	secretDate := hmacSHA256([]byte("TC3"+secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s", algorithm, secretID, scope, signedHeaders, signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// tencentPhoneNumber 腾讯云要求 E.164 格式的号码，没有国家码时视为国内号码
+func tencentPhoneNumber(phone string) string {
+	if strings.HasPrefix(phone, "+") {
+		return phone
+	}
+
+	return "+86" + phone
+}
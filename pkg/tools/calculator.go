@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Calculator 计算数学表达式，支持 + - * / % ^ 以及括号
+type Calculator struct{}
+
+// NewCalculator create a new calculator tool
+func NewCalculator() *Calculator {
+	return &Calculator{}
+}
+
+func (Calculator) Name() string {
+	return "calculator"
+}
+
+func (Calculator) Description() string {
+	return "Evaluate a math expression and return the exact result. Supports + - * / % ^ and parentheses, e.g. (1.5 + 2) * 3 ^ 2"
+}
+
+func (Calculator) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"expression": map[string]any{
+				"type":        "string",
+				"description": "the math expression to evaluate",
+			},
+		},
+		"required": []string{"expression"},
+	}
+}
+
+func (Calculator) Call(_ context.Context, arguments string) (string, error) {
+	var args struct {
+		Expression string `json:"expression"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	p := &exprParser{input: []rune(args.Expression)}
+	result, err := p.parse()
+	if err != nil {
+		return "", err
+	}
+
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return "", errors.New("the result is not a finite number")
+	}
+
+	return strconv.FormatFloat(result, 'f', -1, 64), nil
+}
+
+// exprParser 递归下降解析数学表达式
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/" | "%") factor }
+//	factor  = ("+" | "-") factor | primary [ "^" factor ]
+//	primary = number | "(" expr ")"
+type exprParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *exprParser) parse() (float64, error) {
+	result, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected character %q at position %d", p.input[p.pos], p.pos)
+	}
+
+	return result, nil
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// next 跳过空白字符后，如果下一个字符为 ch 中的一个，则消费并返回该字符
+func (p *exprParser) next(ch string) (rune, bool) {
+	p.skipSpaces()
+	if p.pos < len(p.input) && strings.ContainsRune(ch, p.input[p.pos]) {
+		p.pos++
+		return p.input[p.pos-1], true
+	}
+
+	return 0, false
+}
+
+func (p *exprParser) expr() (float64, error) {
+	left, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		op, ok := p.next("+-")
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+
+		if op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+}
+
+func (p *exprParser) term() (float64, error) {
+	left, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		op, ok := p.next("*/%")
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+
+		switch op {
+		case '*':
+			left *= right
+		case '/':
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			left /= right
+		case '%':
+			if right == 0 {
+				return 0, errors.New("division by zero")
+			}
+			left = math.Mod(left, right)
+		}
+	}
+}
+
+func (p *exprParser) factor() (float64, error) {
+	if op, ok := p.next("+-"); ok {
+		val, err := p.factor()
+		if op == '-' {
+			val = -val
+		}
+
+		return val, err
+	}
+
+	base, err := p.primary()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := p.next("^"); ok {
+		exp, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+
+		return math.Pow(base, exp), nil
+	}
+
+	return base, nil
+}
+
+func (p *exprParser) primary() (float64, error) {
+	if _, ok := p.next("("); ok {
+		val, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+
+		if _, ok := p.next(")"); !ok {
+			return 0, errors.New("missing closing parenthesis")
+		}
+
+		return val, nil
+	}
+
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && (unicode.IsDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+		p.pos++
+	}
+
+	if start == p.pos {
+		if p.pos >= len(p.input) {
+			return 0, errors.New("unexpected end of expression")
+		}
+
+		return 0, fmt.Errorf("unexpected character %q at position %d", p.input[p.pos], p.pos)
+	}
+
+	return strconv.ParseFloat(string(p.input[start:p.pos]), 64)
+}
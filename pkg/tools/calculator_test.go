@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCalculator_Call(t *testing.T) {
+	testCases := []struct {
+		expression string
+		want       string
+		wantErr    bool
+	}{
+		{expression: "1 + 2", want: "3"},
+		{expression: "(1.5 + 2) * 3 ^ 2", want: "31.5"},
+		{expression: "2 ^ 3 ^ 2", want: "512"},
+		{expression: "-2 ^ 2", want: "-4"},
+		{expression: "10 - 4 - 3", want: "3"},
+		{expression: "7 % 4", want: "3"},
+		{expression: "--3", want: "3"},
+		{expression: "1 / 0", wantErr: true},
+		{expression: "5 % 0", wantErr: true},
+		{expression: "(1 + 2", wantErr: true},
+		{expression: "1 +", wantErr: true},
+		{expression: "2 * abc", wantErr: true},
+		{expression: "1 2", wantErr: true},
+		{expression: "10 ^ 400", wantErr: true},
+	}
+
+	calculator := NewCalculator()
+	for _, tc := range testCases {
+		got, err := calculator.Call(context.Background(), `{"expression": "`+tc.expression+`"}`)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: expect error, got %s", tc.expression, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.expression, err)
+			continue
+		}
+
+		if got != tc.want {
+			t.Errorf("%s: expect %s, got %s", tc.expression, tc.want, got)
+		}
+	}
+}
+
+func TestCalculator_InvalidArguments(t *testing.T) {
+	if _, err := NewCalculator().Call(context.Background(), "not json"); err == nil {
+		t.Error("expect error for invalid arguments")
+	}
+}
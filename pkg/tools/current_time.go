@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// CurrentTime 获取当前时间，模型本身无法知道当前的日期和时间
+type CurrentTime struct{}
+
+// NewCurrentTime create a new current time tool
+func NewCurrentTime() *CurrentTime {
+	return &CurrentTime{}
+}
+
+func (CurrentTime) Name() string {
+	return "current_time"
+}
+
+func (CurrentTime) Description() string {
+	return "Get the current date, time and weekday in the specified timezone"
+}
+
+func (CurrentTime) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"timezone": map[string]any{
+				"type":        "string",
+				"description": "IANA timezone name, e.g. Asia/Shanghai, defaults to UTC",
+			},
+		},
+	}
+}
+
+func (CurrentTime) Call(_ context.Context, arguments string) (string, error) {
+	var args struct {
+		Timezone string `json:"timezone"`
+	}
+	if arguments != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	loc := time.UTC
+	if args.Timezone != "" {
+		l, err := time.LoadLocation(args.Timezone)
+		if err != nil {
+			return "", fmt.Errorf("invalid timezone: %s", args.Timezone)
+		}
+
+		loc = l
+	}
+
+	now := time.Now().In(loc)
+	return fmt.Sprintf("%s (%s)", now.Format(time.RFC3339), now.Weekday()), nil
+}
@@ -0,0 +1,17 @@
+package tools
+
+import (
+	"github.com/mylxsw/glacier/infra"
+)
+
+type Provider struct{}
+
+func (Provider) Register(binder infra.Binder) {
+	binder.MustSingleton(func() *Registry {
+		return NewRegistry(
+			NewCalculator(),
+			NewCurrentTime(),
+			NewWebFetch(),
+		)
+	})
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+var ErrToolNotFound = errors.New("tool not found")
+
+// Tool 服务端内置工具，模型发起工具调用后由服务端直接执行，并将结果返回给模型
+type Tool interface {
+	// Name the name of the tool, must be unique, a-z, A-Z, 0-9, underscores and dashes
+	Name() string
+	// Description a description of what the tool does, used by the model to choose when and how to call the tool
+	Description() string
+	// Parameters the parameters the tool accepts, described as a JSON Schema object
+	Parameters() any
+	// Call execute the tool with arguments generated by the model (JSON format), returns the result for the model
+	Call(ctx context.Context, arguments string) (string, error)
+}
+
+// Registry 内置工具注册表
+type Registry struct {
+	tools map[string]Tool
+}
+
+// NewRegistry create a new tool registry
+func NewRegistry(tools ...Tool) *Registry {
+	registry := &Registry{tools: make(map[string]Tool)}
+	for _, tool := range tools {
+		registry.Register(tool)
+	}
+
+	return registry
+}
+
+// Register 注册工具，同名工具会被覆盖
+func (registry *Registry) Register(tool Tool) {
+	registry.tools[tool.Name()] = tool
+}
+
+// Get 根据名称获取工具
+func (registry *Registry) Get(name string) (Tool, bool) {
+	tool, ok := registry.tools[name]
+	return tool, ok
+}
+
+// Names 返回所有已注册的工具名称
+func (registry *Registry) Names() []string {
+	names := make([]string, 0, len(registry.tools))
+	for name := range registry.tools {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Tools 返回指定名称的工具，未注册的工具会被忽略
+func (registry *Registry) Tools(names []string) []Tool {
+	tools := make([]Tool, 0, len(names))
+	for _, name := range names {
+		if tool, ok := registry.tools[name]; ok {
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
+}
+
+// Call 执行指定名称的工具
+func (registry *Registry) Call(ctx context.Context, name string, arguments string) (string, error) {
+	tool, ok := registry.tools[name]
+	if !ok {
+		return "", ErrToolNotFound
+	}
+
+	return tool.Call(ctx, arguments)
+}
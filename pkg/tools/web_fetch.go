@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mylxsw/aidea-chat-server/pkg/misc"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// webFetchMaxBodySize 最多读取的响应内容大小
+	webFetchMaxBodySize = 2 * 1024 * 1024
+	// webFetchMaxResultLength 返回给模型的最大字符数，避免占用过多的上下文
+	webFetchMaxResultLength = 8000
+)
+
+var (
+	htmlIgnoredBlockRegexp = regexp.MustCompile(`(?is)<(script|style|noscript|svg|head)[^>]*>.*?</(script|style|noscript|svg|head)>`)
+	htmlTagRegexp          = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesRegexp       = regexp.MustCompile(`\s*\n\s*`)
+	blankSpacesRegexp      = regexp.MustCompile(`[ \t]+`)
+)
+
+// WebFetch 获取网页内容，并转换为纯文本
+type WebFetch struct {
+	client *http.Client
+}
+
+// NewWebFetch create a new web fetch tool, requests to private network addresses are rejected
+func NewWebFetch() *WebFetch {
+	return &WebFetch{client: misc.NewPublicHTTPClient(20 * time.Second)}
+}
+
+func (WebFetch) Name() string {
+	return "web_fetch"
+}
+
+func (WebFetch) Description() string {
+	return "Fetch the content of a web page by url, returns the page content as plain text"
+}
+
+func (WebFetch) Parameters() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "the url of the web page, http or https only",
+			},
+		},
+		"required": []string{"url"},
+	}
+}
+
+func (tool WebFetch) Call(ctx context.Context, arguments string) (string, error) {
+	var args struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	u, err := url.Parse(args.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.New("invalid url")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; AIdeaBot/1.0)")
+	req.Header.Set("Accept", "text/html,text/plain,application/json;q=0.9,*/*;q=0.5")
+
+	resp, err := tool.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("fetch failed, status code: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.HasPrefix(contentType, "text/") && !strings.Contains(contentType, "json") && !strings.Contains(contentType, "xml") {
+		return "", fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webFetchMaxBodySize))
+	if err != nil {
+		return "", fmt.Errorf("read response failed: %w", err)
+	}
+
+	content := string(body)
+	if strings.Contains(contentType, "html") {
+		content = htmlToText(content)
+	}
+
+	if runes := []rune(content); len(runes) > webFetchMaxResultLength {
+		content = string(runes[:webFetchMaxResultLength]) + "\n...(truncated)"
+	}
+
+	return content, nil
+}
+
+// htmlToText 去掉 HTML 标签，只保留文本内容
+func htmlToText(content string) string {
+	content = htmlIgnoredBlockRegexp.ReplaceAllString(content, "")
+	content = htmlTagRegexp.ReplaceAllString(content, "\n")
+	content = html.UnescapeString(content)
+	content = blankSpacesRegexp.ReplaceAllString(content, " ")
+	content = blankLinesRegexp.ReplaceAllString(content, "\n")
+
+	return strings.TrimSpace(content)
+}